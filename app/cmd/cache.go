@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"gohub/pkg/cache"
+	"gohub/pkg/config"
 	"gohub/pkg/console"
 )
 
@@ -34,9 +35,12 @@ func init() {
 	// Set options for the cache forget command
 	CacheForget.Flags().StringVarP(&cacheKey, "key", "k", "", "KEY of the cache")
 	_ = CacheForget.MarkFlagRequired("key")
+
+	registerDestructiveFlags(CacheClear)
 }
 
 func runCacheClear(_ *cobra.Command, _ []string) {
+	confirmDestructive(fmt.Sprintf("clear all cached data in redis database %d", config.GetInt("redis.database_cache")))
 	cache.Flush()
 	console.Success("Cache cleared.")
 }
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gohub/pkg/app"
+	"gohub/pkg/console"
+)
+
+// Options of the commands that destroy data
+var (
+	destructiveForce bool
+	destructiveYes   bool
+)
+
+// registerDestructiveFlags Add --force and --yes to commands that destroy data, see confirmDestructive
+func registerDestructiveFlags(commands ...*cobra.Command) {
+	for _, command := range commands {
+		command.Flags().BoolVar(&destructiveForce, "force", false, "allow running in production")
+		command.Flags().BoolVarP(&destructiveYes, "yes", "y", false, "skip the confirmation outside production")
+	}
+}
+
+// confirmDestructive Exit unless the user means to run a command that destroys data.
+// Production refuses without --force, elsewhere the user confirms unless --yes is passed.
+func confirmDestructive(action string) {
+	if app.IsProduction() {
+		if !destructiveForce {
+			console.Exit("Refusing to " + action + " in production, pass --force to run it anyway.")
+		}
+		return
+	}
+
+	if destructiveYes {
+		return
+	}
+	if !console.Confirm("This will " + action + ". Continue?") {
+		console.Exit("Aborted.")
+	}
+}
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
 	"github.com/spf13/cobra"
 	"gohub/database/migrations"
+	"gohub/pkg/console"
+	"gohub/pkg/database"
 	"gohub/pkg/migrate"
 )
 
@@ -13,14 +19,28 @@ var Migrate = &cobra.Command{
 
 func init() {
 	Migrate.AddCommand(
+		MigrateStatus,
 		MigrateUp,
 		MigrateRollback,
 		MigrateReset,
 		MigrateRefresh,
 		MigrateFresh,
+		MigrateUnlock,
+		MigrateDump,
 	)
+
+	MigrateUp.Flags().IntVar(&migrateStep, "step", 0, "run at most N migrations, each in a batch of its own")
+	MigrateUp.Flags().BoolVar(&migratePretend, "pretend", false, "print the SQL the migrations would run without running it")
+	MigrateRollback.Flags().IntVar(&migrateStep, "step", 0, "roll back the last N migrations instead of the last batch")
+	registerDestructiveFlags(MigrateReset, MigrateRefresh, MigrateFresh)
 }
 
+// Options for the migrate commands
+var (
+	migrateStep    int
+	migratePretend bool
+)
+
 func migrator() *migrate.Migrator {
 	// Register all migration files under database/migrations
 	migrations.Initialize()
@@ -35,7 +55,36 @@ var MigrateUp = &cobra.Command{
 }
 
 func runUp(_ *cobra.Command, _ []string) {
-	migrator().Up()
+	if migratePretend {
+		console.ExitIf(migrator().Pretend(migrateStep))
+		return
+	}
+	console.ExitIf(migrator().UpStep(migrateStep))
+}
+
+var MigrateStatus = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether each migration has run",
+	Run:   runStatus,
+}
+
+func runStatus(_ *cobra.Command, _ []string) {
+	statuses := migrator().Status()
+	if len(statuses) == 0 {
+		console.Warning("No migrations found.")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "Migration\tStatus\tBatch")
+	for _, status := range statuses {
+		if status.Ran {
+			fmt.Fprintf(writer, "%s\tRan\t%d\n", status.Name, status.Batch)
+		} else {
+			fmt.Fprintf(writer, "%s\tPending\t\n", status.Name)
+		}
+	}
+	_ = writer.Flush()
 }
 
 var MigrateRollback = &cobra.Command{
@@ -46,7 +95,7 @@ var MigrateRollback = &cobra.Command{
 }
 
 func runDown(_ *cobra.Command, _ []string) {
-	migrator().Rollback()
+	console.ExitIf(migrator().RollbackStep(migrateStep))
 }
 
 var MigrateReset = &cobra.Command{
@@ -56,7 +105,8 @@ var MigrateReset = &cobra.Command{
 }
 
 func runReset(_ *cobra.Command, _ []string) {
-	migrator().Reset()
+	confirmDestructive("roll back all migrations of database " + database.CurrentDatabase())
+	console.ExitIf(migrator().Reset())
 }
 
 var MigrateRefresh = &cobra.Command{
@@ -66,7 +116,8 @@ var MigrateRefresh = &cobra.Command{
 }
 
 func runRefresh(_ *cobra.Command, _ []string) {
-	migrator().Refresh()
+	confirmDestructive("roll back and re-run all migrations of database " + database.CurrentDatabase())
+	console.ExitIf(migrator().Refresh())
 }
 
 var MigrateFresh = &cobra.Command{
@@ -76,5 +127,27 @@ var MigrateFresh = &cobra.Command{
 }
 
 func runFresh(_ *cobra.Command, _ []string) {
-	migrator().Fresh()
+	confirmDestructive("drop all tables of database " + database.CurrentDatabase())
+	console.ExitIf(migrator().Fresh())
+}
+
+var MigrateUnlock = &cobra.Command{
+	Use:   "unlock",
+	Short: "Clear the migration lock left by a migrator that did not finish, report the holder of a live one",
+	Run:   runUnlock,
+}
+
+func runUnlock(_ *cobra.Command, _ []string) {
+	console.ExitIf(migrator().Unlock())
+	console.Success("migration lock cleared.")
+}
+
+var MigrateDump = &cobra.Command{
+	Use:   "dump",
+	Short: "Write the schema and the applied migrations to database/schema, loaded by up and fresh on an empty database",
+	Run:   runDump,
+}
+
+func runDump(_ *cobra.Command, _ []string) {
+	console.ExitIf(migrator().Dump())
 }
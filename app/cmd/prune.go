@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gohub/app/models"
+	"gohub/app/models/category"
+	"gohub/app/models/session"
+	"gohub/app/models/topic"
+	"gohub/app/models/user"
+	"gohub/pkg/config"
+	"gohub/pkg/console"
+	"gorm.io/gorm"
+)
+
+var Prune = &cobra.Command{
+	Use:   "prune",
+	Short: "Permanently delete trashed topics, users and categories, and ended sessions, older than the retention",
+	Run:   runPrune,
+	Args:  cobra.NoArgs,
+}
+
+var pruneDays int
+
+func init() {
+	Prune.Flags().IntVarP(&pruneDays, "days", "d", 0, "retention in days, defaults to prune.retention_days")
+}
+
+func runPrune(_ *cobra.Command, _ []string) {
+	days := pruneDays
+	if days <= 0 {
+		days = config.GetInt("prune.retention_days")
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	ctx := context.Background()
+
+	report := func(name string, pruned int64, err error) {
+		console.ExitIf(err)
+		console.Success(fmt.Sprintf("Pruned %d %s deleted more than %d days ago.", pruned, name, days))
+	}
+
+	// Topics first, they also take their replies with them.
+	// Categories and users that still have topics or replies are kept.
+	pruned, err := models.PruneTrashed[topic.Topic](ctx, before)
+	report("topics", pruned, err)
+
+	pruned, err = models.PruneTrashed[category.Category](ctx, before, unreferenced(
+		"SELECT 1 FROM topics WHERE topics.category_id = categories.id",
+	))
+	report("categories", pruned, err)
+
+	pruned, err = models.PruneTrashed[user.User](ctx, before, unreferenced(
+		"SELECT 1 FROM topics WHERE topics.user_id = users.id",
+		"SELECT 1 FROM replies WHERE replies.user_id = users.id",
+	))
+	report("users", pruned, err)
+
+	pruned, err = session.PruneEnded(ctx, before)
+	report("sessions", pruned, err)
+}
+
+// unreferenced Only rows none of the subqueries find
+func unreferenced(subqueries ...string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, subquery := range subqueries {
+			db = db.Where("NOT EXISTS (" + subquery + ")")
+		}
+		return db
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"gohub/pkg/config"
+	"gohub/pkg/console"
+	"gohub/pkg/queue"
+)
+
+var QueueWork = &cobra.Command{
+	Use:   "queue:work",
+	Short: "Run the queued jobs, retrying failed ones with exponential backoff",
+	Run:   runQueueWork,
+	Args:  cobra.NoArgs,
+}
+
+var QueueFailed = &cobra.Command{
+	Use:   "queue:failed",
+	Short: "List the jobs that ran out of attempts",
+	Run:   runQueueFailed,
+	Args:  cobra.NoArgs,
+}
+
+var QueueRetry = &cobra.Command{
+	Use:   "queue:retry [id...]",
+	Short: "Push failed jobs back onto their queue, example: queue:retry 1 2, or queue:retry --all",
+	Run:   runQueueRetry,
+}
+
+// Options for the queue commands
+var (
+	queueName  string
+	queueTries int
+	queueOnce  bool
+	retryAll   bool
+)
+
+func init() {
+	QueueWork.Flags().StringVarP(&queueName, "queue", "q", "", "queue to work, defaults to queue.default")
+	QueueWork.Flags().IntVarP(&queueTries, "tries", "t", 0, "attempts per job, defaults to queue.tries")
+	QueueWork.Flags().BoolVar(&queueOnce, "once", false, "run a single job and exit")
+
+	QueueRetry.Flags().BoolVar(&retryAll, "all", false, "retry every failed job")
+}
+
+func runQueueWork(_ *cobra.Command, _ []string) {
+	worker := &queue.Worker{
+		Queue:      queueName,
+		Tries:      queueTries,
+		Backoff:    time.Duration(config.GetInt("queue.backoff")) * time.Second,
+		MaxBackoff: time.Duration(config.GetInt("queue.max_backoff")) * time.Second,
+		Timeout:    5 * time.Second,
+	}
+	if worker.Queue == "" {
+		worker.Queue = config.GetString("queue.default")
+	}
+	if worker.Tries <= 0 {
+		worker.Tries = config.GetInt("queue.tries")
+	}
+
+	// Stop taking jobs on Ctrl+C or SIGTERM, the running one is finished first
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if queueOnce {
+		ran, err := worker.RunNext(ctx)
+		console.ExitIf(err)
+		if !ran {
+			console.Warning("No job available on queue [" + worker.Queue + "].")
+		}
+		return
+	}
+
+	console.Success(fmt.Sprintf("Working queue [%s], press Ctrl+C to stop.", worker.Queue))
+	worker.Run(ctx)
+}
+
+func runQueueFailed(_ *cobra.Command, _ []string) {
+	failedJobs, err := queue.Failed(context.Background())
+	console.ExitIf(err)
+	if len(failedJobs) == 0 {
+		console.Success("No failed jobs.")
+		return
+	}
+
+	for _, job := range failedJobs {
+		// Only the first line of the error, the rest is in the table
+		reason, _, _ := strings.Cut(job.Error, "\n")
+		fmt.Printf("%d\t%s\t%s\t%d attempts\t%s\t%s\n",
+			job.ID, job.Queue, job.Type, job.Attempts, job.FailedAt.Format(time.DateTime), reason)
+	}
+}
+
+func runQueueRetry(_ *cobra.Command, args []string) {
+	if len(args) == 0 && !retryAll {
+		console.Exit("Give the IDs of the failed jobs to retry, or --all, see queue:failed")
+	}
+
+	var ids []uint64
+	for _, arg := range args {
+		id, err := cast.ToUint64E(arg)
+		if err != nil || id == 0 {
+			console.Exit("Invalid failed job ID: " + arg)
+		}
+		ids = append(ids, id)
+	}
+
+	retried, err := queue.Retry(context.Background(), ids...)
+	console.ExitIf(err)
+	console.Success(fmt.Sprintf("%d failed jobs pushed back onto their queues.", retried))
+}
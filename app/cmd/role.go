@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gohub/app/models/role"
+	"gohub/app/models/user"
+	"gohub/pkg/console"
+)
+
+var Role = &cobra.Command{
+	Use:   "role",
+	Short: "Role management",
+}
+
+var RoleAssign = &cobra.Command{
+	Use:   "assign",
+	Short: "Give a role to a user, example: role assign 1 admin",
+	Run:   runRoleAssign,
+	Args:  cobra.ExactArgs(2),
+}
+
+var RoleRevoke = &cobra.Command{
+	Use:   "revoke",
+	Short: "Take a role away from a user, example: role revoke 1 admin",
+	Run:   runRoleRevoke,
+	Args:  cobra.ExactArgs(2),
+}
+
+func init() {
+	Role.AddCommand(RoleAssign, RoleRevoke)
+}
+
+func runRoleAssign(_ *cobra.Command, args []string) {
+	userModel, roleModel := findUserAndRole(args[0], args[1])
+
+	console.ExitIf(roleModel.AssignTo(context.Background(), userModel.ID))
+	console.Success(fmt.Sprintf("Role [%s] assigned to user [%s].", roleModel.Name, userModel.Name))
+}
+
+func runRoleRevoke(_ *cobra.Command, args []string) {
+	userModel, roleModel := findUserAndRole(args[0], args[1])
+
+	if roleModel.RevokeFrom(context.Background(), userModel.ID) == 0 {
+		console.Warning(fmt.Sprintf("User [%s] does not have role [%s].", userModel.Name, roleModel.Name))
+		return
+	}
+	console.Success(fmt.Sprintf("Role [%s] revoked from user [%s].", roleModel.Name, userModel.Name))
+}
+
+func findUserAndRole(userID, roleName string) (user.User, role.Role) {
+	userModel := user.Get(context.Background(), userID)
+	if userModel.ID == 0 {
+		console.Exit("User not found: " + userID)
+	}
+
+	roleModel := role.GetBy(context.Background(), "name", roleName)
+	if roleModel.ID == 0 {
+		console.Exit("Role not found: " + roleName + ", run `seed SeedRolesTable` to create the built-in roles")
+	}
+
+	return userModel, roleModel
+}
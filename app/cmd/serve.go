@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"context"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
 	"gohub/bootstrap"
@@ -26,6 +29,11 @@ func runWeb(_ *cobra.Command, _ []string) {
 
 	bootstrap.SetupRoute(router)
 
+	// Keep topics.vote_count and the hot scores in step with the cached counters
+	if interval := config.GetInt("vote.reconcile_interval"); interval > 0 {
+		go reconcileVotes(context.Background(), time.Duration(interval)*time.Minute)
+	}
+
 	err := router.Run(":" + config.Get("app.port"))
 	if err != nil {
 		logger.ErrorString("CMD", "serve", err.Error())
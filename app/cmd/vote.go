@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gohub/app/models/vote"
+	"gohub/pkg/console"
+	"gohub/pkg/logger"
+)
+
+var Vote = &cobra.Command{
+	Use:   "vote",
+	Short: "Topic vote management",
+}
+
+var VoteReconcile = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Write the cached vote counters into topics and refresh the hot scores",
+	Run:   runVoteReconcile,
+	Args:  cobra.NoArgs,
+}
+
+func init() {
+	Vote.AddCommand(VoteReconcile)
+}
+
+func runVoteReconcile(_ *cobra.Command, _ []string) {
+	updated, err := vote.Reconcile(context.Background())
+	console.ExitIf(err)
+	console.Success(fmt.Sprintf("Reconciled votes, %d topics updated.", updated))
+}
+
+// reconcileVotes Run vote.Reconcile every interval until ctx is done
+func reconcileVotes(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := vote.Reconcile(ctx); err != nil {
+				logger.ErrorString("CMD", "vote reconcile", err.Error())
+			}
+		}
+	}
+}
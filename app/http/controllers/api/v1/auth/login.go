@@ -1,8 +1,14 @@
 package auth
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	v1 "gohub/app/http/controllers/api/v1"
+	"gohub/app/limiter"
+	"gohub/app/models/session"
+	"gohub/app/models/user"
 	"gohub/app/requests"
 	"gohub/pkg/auth"
 	"gohub/pkg/jwt"
@@ -25,11 +31,7 @@ func (lc *LoginController) LoginByPhone(c *gin.Context) {
 	if err != nil {
 		response.Error(c, err, "Account does not exist")
 	} else {
-		token := jwt.NewJWT().IssueToken(user.GetStringID(), user.Name)
-
-		response.Data(c, gin.H{
-			"token": token,
-		})
+		respondLogin(c, user)
 	}
 }
 
@@ -40,26 +42,129 @@ func (lc *LoginController) LoginByPassword(c *gin.Context) {
 		return
 	}
 
+	// Accounts are locked for an IP after repeated wrong passwords, whether they exist or not
+	lockout := limiter.NewLoginLockout(c.Request.Context(), request.LoginID, c.ClientIP())
+	if retryAfter := lockout.RetryAfter(); retryAfter > 0 {
+		response.TooManyRequests(c, retryAfter, lockedMessage(retryAfter))
+		return
+	}
+
 	user, err := auth.Attempt(c.Request.Context(), request.LoginID, request.Password)
 	if err != nil {
-		response.Unauthorized(c, "The account does not exist or the password is wrong")
-	} else {
-		token := jwt.NewJWT().IssueToken(user.GetStringID(), user.Name)
-		response.Data(c, gin.H{
-			"token": token,
-		})
+		retryAfter, attemptsLeft := lockout.Fail(c.Request.Context())
+		if retryAfter > 0 {
+			response.TooManyRequests(c, retryAfter, lockedMessage(retryAfter))
+		} else {
+			response.Unauthorized(c, fmt.Sprintf(
+				"The account does not exist or the password is wrong, %d attempts left", attemptsLeft))
+		}
+		return
+	}
+
+	lockout.Clear(c.Request.Context())
+	respondLogin(c, user)
+}
+
+// LoginByTwoFactor Exchange the challenge token of a login and a two-factor code for a token pair
+func (lc *LoginController) LoginByTwoFactor(c *gin.Context) {
+	request := requests.LoginByTwoFactorRequest{}
+	if ok := requests.Validate(c, &request, requests.LoginByTwoFactor); !ok {
+		return
 	}
+
+	claims, err := jwt.NewJWT().ParseChallenge(request.ChallengeToken)
+	if err != nil {
+		response.Unauthorized(c, "The login has expired, please log in again")
+		return
+	}
+
+	ctx := c.Request.Context()
+	userModel := user.Get(ctx, claims.UserID)
+	if userModel.ID == 0 || !userModel.TwoFactorEnabled() {
+		response.Unauthorized(c, "The login has expired, please log in again")
+		return
+	}
+
+	if !v1.CheckTwoFactor(c, userModel, func() error {
+		return auth.AttemptTwoFactor(ctx, userModel, request.Code, request.RecoveryCode)
+	}) {
+		return
+	}
+
+	respondTokenPair(c, userModel)
 }
 
-// RefreshToken Refresh Access Token
+// respondLogin The token pair of the user logged in. With two-factor authentication enabled
+// every way of logging in answers a challenge instead, LoginByTwoFactor exchanges it with the code.
+func respondLogin(c *gin.Context, userModel user.User) {
+	if userModel.TwoFactorEnabled() {
+		respondChallenge(c, userModel)
+		return
+	}
+	respondTokenPair(c, userModel)
+}
+
+// respondChallenge The challenge token waiting for the two-factor code of the user
+func respondChallenge(c *gin.Context, userModel user.User) {
+	challenge, expireAt, err := jwt.NewJWT().IssueChallenge(userModel.GetStringID(), userModel.Name)
+	if err != nil {
+		response.Abort500(c, "Failed to log in, please try again later~")
+		return
+	}
+	response.Data(c, gin.H{
+		"two_factor":      true,
+		"challenge_token": challenge,
+		"expire_time":     expireAt,
+	})
+}
+
+// respondTokenPair The token pair of the user, once every factor of the login is checked
+func respondTokenPair(c *gin.Context, userModel user.User) {
+	pair, err := auth.IssueToken(c, userModel)
+	if err != nil {
+		response.Abort500(c, "Failed to log in, please try again later~")
+		return
+	}
+	response.Data(c, pair)
+}
+
+// lockedMessage The message of a locked account
+func lockedMessage(retryAfter time.Duration) string {
+	return fmt.Sprintf("Too many wrong passwords, please try again in %s", retryAfter.Round(time.Second))
+}
+
+// RefreshToken Exchange the refresh token for a new token pair
 func (lc *LoginController) RefreshToken(c *gin.Context) {
-	token, err := jwt.NewJWT().RefreshToken(c)
+	request := requests.RefreshTokenRequest{}
+	if ok := requests.Validate(c, &request, requests.RefreshToken); !ok {
+		return
+	}
 
+	pair, err := jwt.NewJWT().RefreshToken(request.RefreshToken)
+	if err == nil && !auth.SessionActive(c.Request.Context(), pair.FamilyID) {
+		// The session was revoked, the tokens just issued must not be used either
+		jwt.NewJWT().RevokeFamily(pair.FamilyID)
+		err = jwt.ErrTokenRevoked
+	}
 	if err != nil {
-		response.Error(c, err, "Refresh Access Token failed")
+		response.Unauthorized(c, "Refresh Access Token failed: "+err.Error())
 	} else {
-		response.Data(c, gin.H{
-			"token": token,
-		})
+		response.Data(c, pair)
 	}
 }
+
+// Logout Revoke the session the current token belongs to
+func (lc *LoginController) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+	familyID := auth.CurrentSessionFamily(c)
+
+	auth.RevokeSession(ctx, session.GetActiveByFamily(ctx, familyID))
+	jwt.NewJWT().RevokeFamily(familyID)
+	response.Success(c)
+}
+
+// LogoutAll Revoke all sessions of the current user
+func (lc *LoginController) LogoutAll(c *gin.Context) {
+	auth.RevokeAllSessions(c.Request.Context(), auth.CurrentUID(c))
+	response.Success(c)
+}
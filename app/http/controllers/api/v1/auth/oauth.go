@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	v1 "gohub/app/http/controllers/api/v1"
+	"gohub/app/models/socialaccount"
+	"gohub/app/models/user"
+	"gohub/app/requests"
+	"gohub/pkg/auth"
+	"gohub/pkg/database"
+	"gohub/pkg/helpers"
+	"gohub/pkg/logger"
+	"gohub/pkg/oauth"
+	"gohub/pkg/response"
+	"gorm.io/gorm"
+)
+
+// OAuthController Sign in with OAuth providers
+type OAuthController struct {
+	v1.BaseAPIController
+}
+
+// Redirect Send the user to the provider to sign in
+func (oc *OAuthController) Redirect(c *gin.Context) {
+	provider, err := oauth.NewProvider(c.Param("provider"))
+	if err != nil {
+		response.Abort404(c, "Unknown OAuth provider")
+		return
+	}
+
+	state := oauth.NewState(c, oauth.State{Provider: provider.Name})
+	c.Redirect(http.StatusFound, provider.AuthURL(state, oauth.CallbackURL(provider.Name)))
+}
+
+// Callback The provider sends the user back with a code, sign in or link the account
+func (oc *OAuthController) Callback(c *gin.Context) {
+	provider, err := oauth.NewProvider(c.Param("provider"))
+	if err != nil {
+		response.Abort404(c, "Unknown OAuth provider")
+		return
+	}
+
+	request := requests.OAuthCallbackRequest{}
+	if ok := requests.Validate(c, &request, requests.OAuthCallback); !ok {
+		return
+	}
+
+	state, ok := oauth.PullState(c, request.State)
+	if !ok || state.Provider != provider.Name {
+		response.Error(c, errors.New("invalid state"), "The sign in expired or was not started here, please try again")
+		return
+	}
+
+	ctx := c.Request.Context()
+	token, err := provider.Exchange(ctx, request.Code, oauth.CallbackURL(provider.Name))
+	if err != nil {
+		response.Error(c, err, "Failed to sign in with "+provider.Name)
+		return
+	}
+	providerUser, err := provider.User(ctx, token)
+	if err != nil {
+		response.Error(c, err, "Failed to get the account from "+provider.Name)
+		return
+	}
+
+	if state.UserID != "" {
+		oc.link(c, state, providerUser)
+		return
+	}
+
+	// Signed in before
+	account := socialaccount.GetByProvider(ctx, provider.Name, providerUser.ID)
+	if account.ID > 0 {
+		userModel := user.Get(ctx, account.UserID)
+		if userModel.ID == 0 {
+			response.Abort404(c, "The linked user does not exist")
+			return
+		}
+		respondToken(c, http.StatusOK, userModel)
+		return
+	}
+
+	userModel, created, err := signupWithProvider(ctx, provider.Name, providerUser)
+	if err != nil {
+		logger.LogIf(err)
+		response.Abort500(c, "Failed to create user, please try later~")
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	respondToken(c, status, userModel)
+}
+
+// link Link the account to the user who started the redirect from their profile
+func (oc *OAuthController) link(c *gin.Context, state oauth.State, providerUser oauth.User) {
+	ctx := c.Request.Context()
+
+	account := socialaccount.GetByProvider(ctx, state.Provider, providerUser.ID)
+	if account.ID > 0 {
+		if account.UserID != state.UserID {
+			response.Error(c, errors.New("linked to another user"), "The account is linked to another user")
+			return
+		}
+		response.Data(c, account)
+		return
+	}
+	if socialaccount.GetOf(ctx, state.UserID, state.Provider).ID > 0 {
+		response.Error(c, errors.New("provider linked already"), "Unlink the other "+state.Provider+" account first")
+		return
+	}
+
+	account = newSocialAccount(state.UserID, state.Provider, providerUser)
+	if err := account.Create(ctx); err != nil {
+		logger.LogIf(err)
+		response.Abort500(c, "Failed to link the account, please try later~")
+		return
+	}
+	response.Created(c, account)
+}
+
+// signupWithProvider The user with the verified email of the account, or a new one, with the account linked.
+// An email that was saved without being verified may belong to someone else, whoever
+// signed up with it does not get the account; the new user is created without the email.
+func signupWithProvider(ctx context.Context, provider string, providerUser oauth.User) (userModel user.User, created bool, err error) {
+	err = database.DBWithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		emailTaken := false
+		if providerUser.EmailVerified && providerUser.Email != "" {
+			var count int64
+			if err := tx.Model(&user.User{}).Where("email = ?", providerUser.Email).Count(&count).Error; err != nil {
+				return err
+			}
+			emailTaken = count > 0
+			if err := tx.Where("email = ? AND email_verified_at IS NOT NULL", providerUser.Email).Limit(1).Find(&userModel).Error; err != nil {
+				return err
+			}
+		}
+		if userModel.ID == 0 {
+			userModel = user.User{
+				Name:   availableName(tx, providerUser.Name),
+				Avatar: providerUser.Avatar,
+				// Signing in with a password takes a reset first
+				Password: helpers.SecureRandomString(32),
+			}
+			if providerUser.EmailVerified && providerUser.Email != "" && !emailTaken {
+				now := time.Now()
+				userModel.Email = providerUser.Email
+				userModel.EmailVerifiedAt = &now
+			}
+			if err := tx.Create(&userModel).Error; err != nil {
+				return err
+			}
+			created = true
+		}
+
+		account := newSocialAccount(userModel.GetStringID(), provider, providerUser)
+		return tx.Create(&account).Error
+	})
+	return
+}
+
+func newSocialAccount(userID, provider string, providerUser oauth.User) socialaccount.SocialAccount {
+	return socialaccount.SocialAccount{
+		UserID:         userID,
+		Provider:       provider,
+		ProviderUserID: providerUser.ID,
+		Name:           providerUser.Name,
+		Email:          providerUser.Email,
+		Avatar:         providerUser.Avatar,
+	}
+}
+
+// nameDisallowed User names are alpha_num
+var nameDisallowed = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// availableName A user name like the name at the provider that is not taken yet
+func availableName(tx *gorm.DB, name string) string {
+	name = nameDisallowed.ReplaceAllString(name, "")
+	if len(name) > 15 {
+		name = name[:15]
+	}
+	if len(name) < 3 {
+		name = "user"
+	}
+
+	candidate := name
+	for {
+		var count int64
+		tx.Model(&user.User{}).Where("name = ?", candidate).Count(&count)
+		if count == 0 {
+			return candidate
+		}
+		candidate = name + helpers.RandomNumber(5)
+	}
+}
+
+// respondToken The token pair of the user signed in, or the challenge of LoginByTwoFactor
+// when the user has two-factor authentication enabled
+func respondToken(c *gin.Context, status int, userModel user.User) {
+	if userModel.TwoFactorEnabled() {
+		respondChallenge(c, userModel)
+		return
+	}
+
+	pair, err := auth.IssueToken(c, userModel)
+	if err != nil {
+		response.Abort500(c, "Failed to sign in, please try again later~")
+		return
+	}
+	data := gin.H{
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expire_time":   pair.ExpireAtTime,
+		"user":          userModel,
+	}
+	if status == http.StatusCreated {
+		response.Created(c, data)
+	} else {
+		response.Data(c, data)
+	}
+}
@@ -5,6 +5,10 @@ import (
 	v1 "gohub/app/http/controllers/api/v1"
 	"gohub/app/models/user"
 	"gohub/app/requests"
+	"gohub/pkg/app"
+	"gohub/pkg/config"
+	"gohub/pkg/logger"
+	"gohub/pkg/mail"
 	"gohub/pkg/response"
 )
 
@@ -26,6 +30,7 @@ func (pc *PasswordController) ResetByPhone(c *gin.Context) {
 	} else {
 		userModel.Password = request.Password
 		userModel.Save(c.Request.Context())
+		sendPasswordResetMail(c, userModel)
 
 		response.Success(c)
 	}
@@ -44,7 +49,35 @@ func (pc *PasswordController) ResetByEmail(c *gin.Context) {
 	} else {
 		userModel.Password = request.Password
 		userModel.Save(c.Request.Context())
+		sendPasswordResetMail(c, userModel)
 
 		response.Success(c)
 	}
 }
+
+// sendPasswordResetMail Tell the user their password was reset, in case it was not them
+func sendPasswordResetMail(c *gin.Context, userModel user.User) {
+	if userModel.Email == "" {
+		return
+	}
+
+	email := mail.Email{
+		From: mail.From{
+			Address: config.GetString("mail.from.address"),
+			Name:    config.GetString("mail.from.name"),
+		},
+		To: []string{userModel.Email},
+	}
+	err := mail.Template{
+		Name:   "password_reset",
+		Locale: c.GetHeader("Accept-Language"),
+		Data: map[string]any{
+			"Name":    userModel.Name,
+			"ResetAt": app.TimenowInTimezone().Format("2006-01-02 15:04:05 MST"),
+		},
+	}.Render(&email)
+	if err == nil {
+		err = mail.NewMailer().Queue(c.Request.Context(), email)
+	}
+	logger.LogIf(err)
+}
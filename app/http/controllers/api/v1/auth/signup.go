@@ -2,11 +2,15 @@
 package auth
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	v1 "gohub/app/http/controllers/api/v1"
 	"gohub/app/models/user"
 	"gohub/app/requests"
-	"gohub/pkg/jwt"
+	"gohub/app/verifyemail"
+	"gohub/pkg/auth"
+	"gohub/pkg/logger"
 	"gohub/pkg/response"
 )
 
@@ -56,10 +60,16 @@ func (sc *SignupController) SignupUsingPhone(c *gin.Context) {
 	userModel.Create(c.Request.Context())
 
 	if userModel.ID > 0 {
-		token := jwt.NewJWT().IssueToken(userModel.GetStringID(), userModel.Name)
+		pair, err := auth.IssueToken(c, userModel)
+		if err != nil {
+			response.Abort500(c, "The account was created but logging in failed, please log in later~")
+			return
+		}
 		response.Created(c, gin.H{
-			"token": token,
-			"user":  userModel,
+			"token":         pair.AccessToken,
+			"refresh_token": pair.RefreshToken,
+			"expire_time":   pair.ExpireAtTime,
+			"user":          userModel,
 		})
 	} else {
 		response.Abort500(c, "Failed to create user, please try later~")
@@ -78,13 +88,29 @@ func (sc *SignupController) SignupUsingEmail(c *gin.Context) {
 		Email:    request.Email,
 		Password: request.Password,
 	}
+	// The code proves the ownership, otherwise the link of the verification email activates the account
+	if request.VerifyCode != "" {
+		now := time.Now()
+		userModel.EmailVerifiedAt = &now
+	}
 	userModel.Create(c.Request.Context())
 
 	if userModel.ID > 0 {
-		token := jwt.NewJWT().IssueToken(userModel.GetStringID(), userModel.Name)
+		if !userModel.HasVerifiedEmail() {
+			logger.LogIf(verifyemail.SendVerify(c.Request.Context(), userModel, c.GetHeader("Accept-Language")))
+		}
+
+		pair, err := auth.IssueToken(c, userModel)
+		if err != nil {
+			response.Abort500(c, "The account was created but logging in failed, please log in later~")
+			return
+		}
 		response.Created(c, gin.H{
-			"token": token,
-			"user":  userModel,
+			"token":          pair.AccessToken,
+			"refresh_token":  pair.RefreshToken,
+			"expire_time":    pair.ExpireAtTime,
+			"user":           userModel,
+			"email_verified": userModel.HasVerifiedEmail(),
 		})
 	} else {
 		response.Abort500(c, "Failed to create user, please try later~")
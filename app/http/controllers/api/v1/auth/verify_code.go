@@ -35,7 +35,7 @@ func (vc *VerifyController) SendUsingPhone(c *gin.Context) {
 	}
 
 	// Send SMS
-	if ok := verifycode.NewVerifyCode().SendSMS(request.Phone); !ok {
+	if err := verifycode.NewVerifyCode().SendSMS(request.Phone); err != nil {
 		response.Abort500(c, "Failed to send SMS~")
 	} else {
 		response.Success(c)
@@ -49,7 +49,7 @@ func (vc *VerifyController) SendUsingEmail(c *gin.Context) {
 		return
 	}
 
-	err := verifycode.NewVerifyCode().SendEmail(request.Email)
+	err := verifycode.NewVerifyCode().SendEmail(request.Email, c.GetHeader("Accept-Language"))
 	if err != nil {
 		response.Abort500(c, "Failed to send email verification code~")
 	} else {
@@ -56,7 +56,7 @@ func (ctrl *CategoriesController) Update(c *gin.Context) {
 
 func (ctrl *CategoriesController) Index(c *gin.Context) {
 	request := requests.PaginationRequest{}
-	if ok := requests.Validate(c, &request, requests.Pagination); !ok {
+	if ok := requests.Validate(c, &request, requests.PaginationOf(&category.Category{})); !ok {
 		return
 	}
 
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/user"
+	"gohub/app/requests"
+	"gohub/app/verifyemail"
+	"gohub/pkg/auth"
+	"gohub/pkg/logger"
+	"gohub/pkg/response"
+	"gohub/pkg/signedurl"
+)
+
+// EmailVerificationController Prove the ownership of email addresses, with a code or a signed link
+type EmailVerificationController struct {
+	BaseAPIController
+}
+
+// Send Email the link that verifies the current email again
+func (ctrl *EmailVerificationController) Send(c *gin.Context) {
+	currentUser := auth.CurrentUser(c)
+	if currentUser.Email == "" {
+		response.Abort403(c, "Add an email address first")
+		return
+	}
+	if currentUser.HasVerifiedEmail() {
+		response.Abort403(c, "The email address is verified already")
+		return
+	}
+
+	if err := verifyemail.SendVerify(c.Request.Context(), currentUser, c.GetHeader("Accept-Language")); err != nil {
+		logger.LogIf(err)
+		response.Abort500(c, "Failed to send the verification email, please try again later~")
+		return
+	}
+	response.Success(c)
+}
+
+// VerifyByCode Verify the current email with a code of POST /auth/verify-codes/email
+func (ctrl *EmailVerificationController) VerifyByCode(c *gin.Context) {
+	currentUser := auth.CurrentUser(c)
+	if currentUser.Email == "" {
+		response.Abort403(c, "Add an email address first")
+		return
+	}
+
+	request := requests.EmailVerifyCodeRequest{}
+	if ok := requests.Validate(c, &request, requests.EmailVerifyCode); !ok {
+		return
+	}
+
+	markVerified(c, currentUser)
+}
+
+// Verify Open a link of verifyemail, which verifies the current email or applies the change to a new one
+func (ctrl *EmailVerificationController) Verify(c *gin.Context) {
+	if err := signedurl.Verify(c.Request.URL); err != nil {
+		if errors.Is(err, signedurl.ErrExpired) {
+			response.Abort403(c, "The link has expired, please request a new one")
+		} else {
+			response.Abort403(c, "The link is invalid")
+		}
+		return
+	}
+
+	request := requests.EmailVerifyLinkRequest{}
+	if ok := requests.Validate(c, &request, requests.EmailVerifyLink); !ok {
+		return
+	}
+
+	userModel := user.Get(c.Request.Context(), request.User)
+	if userModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	if request.From == "" {
+		if !strings.EqualFold(userModel.Email, request.Email) {
+			response.Abort403(c, "The email address has been changed since the link was sent")
+			return
+		}
+	} else {
+		if !verifyemail.IsChangeOf(userModel, request.From) {
+			response.Abort403(c, "The email address has been changed since the link was sent")
+			return
+		}
+		if !strings.EqualFold(userModel.Email, request.Email) && user.IsEmailExist(c.Request.Context(), request.Email) {
+			response.ValidationError(c, map[string][]string{
+				"email": {"Email is occupied"},
+			})
+			return
+		}
+		userModel.Email = request.Email
+	}
+
+	markVerified(c, userModel)
+}
+
+// markVerified Save the email of the user as verified now
+func markVerified(c *gin.Context, userModel user.User) {
+	now := time.Now()
+	userModel.EmailVerifiedAt = &now
+	if rowsAffected := userModel.Save(c.Request.Context()); rowsAffected > 0 {
+		response.Success(c)
+	} else {
+		response.Abort500(c, "Failed to verify the email address, please try again later~")
+	}
+}
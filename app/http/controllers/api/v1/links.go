@@ -2,6 +2,7 @@ package v1
 
 import (
 	"gohub/app/models/link"
+	"gohub/app/requests"
 	"gohub/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -15,3 +16,61 @@ func (ctrl *LinksController) Index(c *gin.Context) {
 	links := link.AllCached(c.Request.Context())
 	response.Data(c, links)
 }
+
+func (ctrl *LinksController) Store(c *gin.Context) {
+	request := requests.LinkRequest{}
+	if ok := requests.Validate(c, &request, requests.LinkSave); !ok {
+		return
+	}
+
+	linkModel := link.Link{
+		Name: request.Name,
+		URL:  request.URL,
+	}
+
+	linkModel.Create(c.Request.Context())
+	if linkModel.ID > 0 {
+		response.Created(c, linkModel)
+	} else {
+		response.Abort500(c, "Failed to create, please try later~")
+	}
+}
+
+func (ctrl *LinksController) Update(c *gin.Context) {
+	linkModel := link.Get(c.Request.Context(), c.Param("id"))
+	if linkModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	request := requests.LinkRequest{}
+	if ok := requests.Validate(c, &request, requests.LinkSave); !ok {
+		return
+	}
+
+	linkModel.Name = request.Name
+	linkModel.URL = request.URL
+	rowsAffected := linkModel.Save(c.Request.Context())
+
+	if rowsAffected > 0 {
+		response.Data(c, linkModel)
+	} else {
+		response.Abort500(c)
+	}
+}
+
+func (ctrl *LinksController) Delete(c *gin.Context) {
+	linkModel := link.Get(c.Request.Context(), c.Param("id"))
+	if linkModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	rowsAffected := linkModel.Delete(c.Request.Context())
+	if rowsAffected > 0 {
+		response.Success(c)
+		return
+	}
+
+	response.Abort500(c, "Deletion failed, please try later~")
+}
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/notification"
+	"gohub/app/requests"
+	"gohub/pkg/auth"
+	"gohub/pkg/response"
+)
+
+type NotificationsController struct {
+	BaseAPIController
+}
+
+// Index Notifications of the current user, unread=1 leaves out the read ones
+func (ctrl *NotificationsController) Index(c *gin.Context) {
+	request := requests.NotificationRequest{}
+	if ok := requests.Validate(c, &request, requests.Notification); !ok {
+		return
+	}
+
+	data, pager := notification.Paginate(c.Request.Context(), c, auth.CurrentUID(c), request.OnlyUnread(), 20)
+	response.Paginated(c, data, pager)
+}
+
+func (ctrl *NotificationsController) Read(c *gin.Context) {
+	notificationModel := notification.GetOf(c.Request.Context(), auth.CurrentUID(c), c.Param("id"))
+	if notificationModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	notificationModel.MarkRead(c.Request.Context())
+	response.Data(c, notificationModel)
+}
+
+func (ctrl *NotificationsController) ReadAll(c *gin.Context) {
+	rowsAffected := notification.ReadAll(c.Request.Context(), auth.CurrentUID(c))
+	response.Data(c, gin.H{"read": rowsAffected})
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"gohub/app/models/reply"
+	"gohub/app/models/topic"
+	"gohub/app/policies"
+	"gohub/app/requests"
+	"gohub/pkg/auth"
+	"gohub/pkg/response"
+)
+
+type RepliesController struct {
+	BaseAPIController
+}
+
+// Index Replies of a topic, parent_id can be used to build the thread tree
+func (ctrl *RepliesController) Index(c *gin.Context) {
+	topicModel := topic.Get(c.Request.Context(), c.Param("id"))
+	if topicModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	request := requests.PaginationRequest{}
+	if ok := requests.Validate(c, &request, requests.PaginationOf(&reply.Reply{})); !ok {
+		return
+	}
+
+	data, pager := reply.PaginateByTopic(c.Request.Context(), c, topicModel.GetStringID(), 20)
+	response.Paginated(c, data, pager)
+}
+
+func (ctrl *RepliesController) Store(c *gin.Context) {
+	topicModel := topic.Get(c.Request.Context(), c.Param("id"))
+	if topicModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	request := requests.ReplyRequest{}
+	if ok := requests.Validate(c, &request, requests.ReplySave); !ok {
+		return
+	}
+
+	replyModel := reply.Reply{
+		Body:     request.Body,
+		TopicID:  topicModel.GetStringID(),
+		UserID:   auth.CurrentUID(c),
+		ParentID: cast.ToUint64(request.ParentID),
+	}
+
+	replyModel.Create(c.Request.Context())
+	if replyModel.ID > 0 {
+		response.Created(c, replyModel)
+	} else {
+		response.Abort500(c, "Failed to create, please try later~")
+	}
+}
+
+func (ctrl *RepliesController) Delete(c *gin.Context) {
+	topicModel := topic.Get(c.Request.Context(), c.Param("id"))
+	if topicModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	replyModel := reply.Get(c.Request.Context(), c.Param("reply_id"))
+	if replyModel.ID == 0 || replyModel.TopicID != topicModel.GetStringID() {
+		response.Abort404(c)
+		return
+	}
+
+	if ok := policies.CanModifyReply(c, replyModel, topicModel); !ok {
+		response.Abort403(c)
+		return
+	}
+
+	rowsAffected := replyModel.Delete(c.Request.Context())
+	if rowsAffected > 0 {
+		response.Success(c)
+		return
+	}
+
+	response.Abort500(c, "Failed to delete, please try later~")
+}
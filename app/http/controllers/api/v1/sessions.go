@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/session"
+	"gohub/pkg/auth"
+	"gohub/pkg/response"
+)
+
+// SessionsController The devices the current user is logged in on
+type SessionsController struct {
+	BaseAPIController
+}
+
+// Index The active sessions, the one of the current token is marked current
+func (ctrl *SessionsController) Index(c *gin.Context) {
+	type item struct {
+		session.Session
+		Current bool `json:"current"`
+	}
+
+	sessions := session.ActiveOf(c.Request.Context(), auth.CurrentUID(c))
+	items := make([]item, 0, len(sessions))
+	for _, sessionModel := range sessions {
+		items = append(items, item{
+			Session: sessionModel,
+			Current: sessionModel.FamilyID == auth.CurrentSessionFamily(c),
+		})
+	}
+	response.Data(c, items)
+}
+
+// Delete Revoke a session, its tokens are refused from the next request on
+func (ctrl *SessionsController) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionModel := session.GetActiveOf(ctx, auth.CurrentUID(c), c.Param("id"))
+	if sessionModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	if auth.RevokeSession(ctx, sessionModel) {
+		response.Success(c)
+	} else {
+		response.Abort500(c, "Failed to revoke the session, please try again later~")
+	}
+}
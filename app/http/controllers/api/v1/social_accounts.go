@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/socialaccount"
+	"gohub/pkg/auth"
+	"gohub/pkg/oauth"
+	"gohub/pkg/response"
+)
+
+// SocialAccountsController The OAuth accounts linked to the current user
+type SocialAccountsController struct {
+	BaseAPIController
+}
+
+// Index The linked accounts
+func (ctrl *SocialAccountsController) Index(c *gin.Context) {
+	response.Data(c, socialaccount.AllOf(c.Request.Context(), auth.CurrentUID(c)))
+}
+
+// Link The URL of the provider to sign in at, the callback links the account to the current user.
+// The URL only works in the client that requested it, which gets the nonce cookie of the state.
+func (ctrl *SocialAccountsController) Link(c *gin.Context) {
+	provider, err := oauth.NewProvider(c.Param("provider"))
+	if err != nil {
+		response.Abort404(c, "Unknown OAuth provider")
+		return
+	}
+
+	state := oauth.NewState(c, oauth.State{Provider: provider.Name, UserID: auth.CurrentUID(c)})
+	response.Data(c, gin.H{
+		"url": provider.AuthURL(state, oauth.CallbackURL(provider.Name)),
+	})
+}
+
+// Unlink Remove the account of the provider, unless the user could not sign in any more
+func (ctrl *SocialAccountsController) Unlink(c *gin.Context) {
+	ctx := c.Request.Context()
+	currentUser := auth.CurrentUser(c)
+
+	account := socialaccount.GetOf(ctx, currentUser.GetStringID(), c.Param("provider"))
+	if account.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	// Without email or phone the password can not be reset, the account is the only way in
+	if currentUser.Email == "" && currentUser.Phone == "" &&
+		socialaccount.CountOf(ctx, currentUser.GetStringID()) <= 1 {
+		response.Abort403(c, "Add an email or phone before unlinking the only account you sign in with")
+		return
+	}
+
+	if rowsAffected := account.Delete(ctx); rowsAffected > 0 {
+		response.Success(c)
+	} else {
+		response.Abort500(c, "Failed to unlink the account, please try again later~")
+	}
+}
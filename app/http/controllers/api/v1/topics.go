@@ -2,7 +2,11 @@ package v1
 
 import (
 	"github.com/gin-gonic/gin"
+	"gohub/app/models/category"
+	"gohub/app/models/permission"
 	"gohub/app/models/topic"
+	"gohub/app/models/user"
+	"gohub/app/models/vote"
 	"gohub/app/policies"
 	"gohub/app/requests"
 	"gohub/pkg/auth"
@@ -41,7 +45,7 @@ func (ctrl *TopicsController) Update(c *gin.Context) {
 		return
 	}
 
-	if ok := policies.CanModifyTopic(c, topicModel); !ok {
+	if ok := policies.CanManageTopic(c, topicModel); !ok {
 		response.Abort403(c)
 		return
 	}
@@ -70,7 +74,7 @@ func (ctrl *TopicsController) Delete(c *gin.Context) {
 		return
 	}
 
-	if ok := policies.CanModifyTopic(c, topicModel); !ok {
+	if ok := policies.CanManageTopic(c, topicModel); !ok {
 		response.Abort403(c)
 		return
 	}
@@ -85,12 +89,58 @@ func (ctrl *TopicsController) Delete(c *gin.Context) {
 }
 
 func (ctrl *TopicsController) Index(c *gin.Context) {
-	request := requests.PaginationRequest{}
-	if ok := requests.Validate(c, &request, requests.Pagination); !ok {
+	ctrl.paginate(c, topic.Filter{})
+}
+
+// IndexByCategory Topics of the category in the URL
+func (ctrl *TopicsController) IndexByCategory(c *gin.Context) {
+	categoryModel := category.Get(c.Request.Context(), c.Param("id"))
+	if categoryModel.ID == 0 {
+		response.Abort404(c)
 		return
 	}
 
-	data, pager := topic.Paginate(c.Request.Context(), c, 10)
+	ctrl.paginate(c, topic.Filter{CategoryID: categoryModel.GetStringID()})
+}
+
+// IndexByUser Topics written by the user in the URL
+func (ctrl *TopicsController) IndexByUser(c *gin.Context) {
+	userModel := user.Get(c.Request.Context(), c.Param("id"))
+	if userModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	ctrl.paginate(c, topic.Filter{UserID: userModel.GetStringID()})
+}
+
+// paginate List topics matching the query filters, on top of the fixed ones
+func (ctrl *TopicsController) paginate(c *gin.Context, filter topic.Filter) {
+	request := requests.TopicFilterRequest{}
+	if ok := requests.Validate(c, &request, requests.TopicFilter); !ok {
+		return
+	}
+
+	if filter.CategoryID == "" {
+		filter.CategoryID = request.CategoryID
+	}
+	if filter.UserID == "" {
+		filter.UserID = request.UserID
+	}
+	filter.CreatedAfter = request.CreatedAfterTime()
+	filter.CreatedBefore = request.CreatedBeforeTime()
+
+	data, pager := topic.Paginate(c.Request.Context(), c, filter, 10)
+	response.Paginated(c, data, pager)
+}
+
+func (ctrl *TopicsController) Search(c *gin.Context) {
+	request := requests.TopicSearchRequest{}
+	if ok := requests.Validate(c, &request, requests.TopicSearch); !ok {
+		return
+	}
+
+	data, pager := topic.Search(c.Request.Context(), c, request.Q, 10)
 	response.Paginated(c, data, pager)
 }
 
@@ -101,5 +151,44 @@ func (ctrl *TopicsController) Show(c *gin.Context) {
 		return
 	}
 
+	// topics.vote_count lags behind until the next reconciliation
+	topicModel.VoteCount = vote.Count(c.Request.Context(), topicModel.GetStringID())
 	response.Data(c, topicModel)
 }
+
+// Trashed The deleted topics of the current user, moderators see everyone's
+func (ctrl *TopicsController) Trashed(c *gin.Context) {
+	request := requests.PaginationRequest{}
+	if ok := requests.Validate(c, &request, requests.PaginationOf(&topic.Topic{})); !ok {
+		return
+	}
+
+	userID := auth.CurrentUID(c)
+	if auth.Can(c, permission.TopicsModerate) {
+		userID = ""
+	}
+
+	data, pager := topic.PaginateTrashed(c.Request.Context(), c, userID, 10)
+	response.Paginated(c, data, pager)
+}
+
+func (ctrl *TopicsController) Restore(c *gin.Context) {
+	topicModel := topic.GetTrashed(c.Request.Context(), c.Param("id"))
+	if topicModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	if ok := policies.CanManageTopic(c, topicModel); !ok {
+		response.Abort403(c)
+		return
+	}
+
+	rowsAffected := topicModel.Restore(c.Request.Context())
+	if rowsAffected > 0 {
+		response.Data(c, topicModel)
+		return
+	}
+
+	response.Abort500(c, "Failed to restore, please try later~")
+}
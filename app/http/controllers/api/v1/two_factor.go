@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gohub/app/limiter"
+	"gohub/app/models/user"
+	"gohub/app/requests"
+	"gohub/pkg/auth"
+	"gohub/pkg/config"
+	"gohub/pkg/response"
+	"gohub/pkg/totp"
+)
+
+// TwoFactorController Two-factor authentication of the current user with an authenticator app
+type TwoFactorController struct {
+	BaseAPIController
+}
+
+// Setup Generate a new secret, two-factor authentication is enabled once it is confirmed with a code
+func (ctrl *TwoFactorController) Setup(c *gin.Context) {
+	currentUser := auth.CurrentUser(c)
+	if currentUser.TwoFactorEnabled() {
+		response.Abort403(c, "Two-factor authentication is enabled already, disable it first")
+		return
+	}
+
+	currentUser.TwoFactorSecret = totp.GenerateSecret()
+	currentUser.TwoFactorRecoveryCodes = ""
+	if rowsAffected := currentUser.Save(c.Request.Context()); rowsAffected == 0 {
+		response.Abort500(c, "Failed to set up two-factor authentication, please try again later~")
+		return
+	}
+
+	issuer := config.GetString("two_factor.issuer")
+	if issuer == "" {
+		issuer = config.GetString("app.name")
+	}
+	account := currentUser.Email
+	if account == "" {
+		account = currentUser.Name
+	}
+	uri := totp.URI(issuer, account, currentUser.TwoFactorSecret)
+
+	png, err := totp.QRCode(uri, 256)
+	if err != nil {
+		response.Abort500(c, "Failed to generate the QR code, please try again later~")
+		return
+	}
+
+	response.Data(c, gin.H{
+		"secret":      currentUser.TwoFactorSecret,
+		"otpauth_uri": uri,
+		"qr_code":     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// Confirm Enable two-factor authentication with the first code of the new secret,
+// the recovery codes are only shown here
+func (ctrl *TwoFactorController) Confirm(c *gin.Context) {
+	request := requests.TwoFactorConfirmRequest{}
+	if ok := requests.Validate(c, &request, requests.TwoFactorConfirm); !ok {
+		return
+	}
+
+	currentUser := auth.CurrentUser(c)
+	if currentUser.TwoFactorEnabled() {
+		response.Abort403(c, "Two-factor authentication is enabled already")
+		return
+	}
+	if currentUser.TwoFactorSecret == "" {
+		response.ValidationError(c, map[string][]string{
+			"code": {"Set up two-factor authentication first"},
+		})
+		return
+	}
+
+	if !CheckTwoFactor(c, currentUser, func() error { return auth.CheckTwoFactorSetup(currentUser, request.Code) }) {
+		return
+	}
+
+	codes, hashes := auth.NewRecoveryCodes()
+	now := time.Now()
+	currentUser.TwoFactorRecoveryCodes = hashes
+	currentUser.TwoFactorConfirmedAt = &now
+	if rowsAffected := currentUser.Save(c.Request.Context()); rowsAffected == 0 {
+		response.Abort500(c, "Failed to enable two-factor authentication, please try again later~")
+		return
+	}
+
+	response.Data(c, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// Disable Turn two-factor authentication off with a code or a recovery code
+func (ctrl *TwoFactorController) Disable(c *gin.Context) {
+	request := requests.TwoFactorCodeRequest{}
+	if ok := requests.Validate(c, &request, requests.TwoFactorCode); !ok {
+		return
+	}
+
+	currentUser := auth.CurrentUser(c)
+	if !currentUser.TwoFactorEnabled() {
+		response.Abort403(c, "Two-factor authentication is not enabled")
+		return
+	}
+
+	if !CheckTwoFactor(c, currentUser, func() error {
+		return auth.AttemptTwoFactor(c.Request.Context(), currentUser, request.Code, request.RecoveryCode)
+	}) {
+		return
+	}
+
+	currentUser.TwoFactorSecret = ""
+	currentUser.TwoFactorRecoveryCodes = ""
+	currentUser.TwoFactorConfirmedAt = nil
+	if rowsAffected := currentUser.Save(c.Request.Context()); rowsAffected == 0 {
+		response.Abort500(c, "Failed to disable two-factor authentication, please try again later~")
+		return
+	}
+
+	response.Success(c)
+}
+
+// CheckTwoFactor Run the check of a two-factor code, wrong codes count towards the lockout of the user.
+// Responds and returns false when the code is wrong or the user is locked.
+func CheckTwoFactor(c *gin.Context, userModel user.User, check func() error) bool {
+	lockout := limiter.NewTwoFactorLockout(userModel.GetStringID())
+	if retryAfter := lockout.RetryAfter(); retryAfter > 0 {
+		response.TooManyRequests(c, retryAfter, twoFactorLockedMessage(retryAfter))
+		return false
+	}
+
+	err := check()
+	if errors.Is(err, auth.ErrTwoFactorCode) {
+		retryAfter, attemptsLeft := lockout.Fail(c.Request.Context())
+		if retryAfter > 0 {
+			response.TooManyRequests(c, retryAfter, twoFactorLockedMessage(retryAfter))
+		} else {
+			response.ValidationError(c, map[string][]string{
+				"code": {fmt.Sprintf("The two-factor code is wrong or used already, %d attempts left", attemptsLeft)},
+			})
+		}
+		return false
+	}
+	if err != nil {
+		response.Abort500(c, "Failed to check the two-factor code, please try again later~")
+		return false
+	}
+
+	lockout.Clear(c.Request.Context())
+	return true
+}
+
+// twoFactorLockedMessage The message of a user locked after wrong two-factor codes
+func twoFactorLockedMessage(retryAfter time.Duration) string {
+	return fmt.Sprintf("Too many wrong two-factor codes, please try again in %s", retryAfter.Round(time.Second))
+}
@@ -1,12 +1,17 @@
 package v1
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"gohub/app/models/notification"
 	"gohub/app/models/user"
 	"gohub/app/requests"
+	"gohub/app/verifyemail"
 	"gohub/pkg/auth"
 	"gohub/pkg/config"
 	"gohub/pkg/file"
+	"gohub/pkg/logger"
 	"gohub/pkg/response"
 )
 
@@ -17,13 +22,23 @@ type UsersController struct {
 // CurrentUser Information about the currently logged-in user
 func (ctrl *UsersController) CurrentUser(c *gin.Context) {
 	userModel := auth.CurrentUser(c)
-	response.Data(c, userModel)
+	response.Data(c, struct {
+		user.User
+		UnreadNotifications int64 `json:"unread_notifications"`
+		EmailVerified       bool  `json:"email_verified"`
+		TwoFactorEnabled    bool  `json:"two_factor_enabled"`
+	}{
+		User:                userModel,
+		UnreadNotifications: notification.UnreadCount(c.Request.Context(), userModel.GetStringID()),
+		EmailVerified:       userModel.HasVerifiedEmail(),
+		TwoFactorEnabled:    userModel.TwoFactorEnabled(),
+	})
 }
 
 // Index All user
 func (ctrl *UsersController) Index(c *gin.Context) {
 	request := requests.PaginationRequest{}
-	if ok := requests.Validate(c, &request, requests.Pagination); !ok {
+	if ok := requests.Validate(c, &request, requests.PaginationOf(&user.User{})); !ok {
 		return
 	}
 
@@ -57,7 +72,23 @@ func (ctrl *UsersController) UpdateEmail(c *gin.Context) {
 	}
 
 	currentUser := auth.CurrentUser(c)
+
+	// Without a code the email is only changed once the link sent to the new address is opened
+	if request.VerifyCode == "" {
+		if err := verifyemail.SendChange(c.Request.Context(), currentUser, request.Email, c.GetHeader("Accept-Language")); err != nil {
+			logger.LogIf(err)
+			response.Abort500(c, "Failed to send the confirmation email, please try later~")
+			return
+		}
+		response.Data(c, gin.H{
+			"pending_email": request.Email,
+		})
+		return
+	}
+
+	now := time.Now()
 	currentUser.Email = request.Email
+	currentUser.EmailVerifiedAt = &now
 
 	rowsAffected := currentUser.Save(c.Request.Context())
 	if rowsAffected > 0 {
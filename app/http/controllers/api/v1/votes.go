@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/topic"
+	"gohub/app/models/vote"
+	"gohub/pkg/auth"
+	"gohub/pkg/response"
+)
+
+type VotesController struct {
+	BaseAPIController
+}
+
+// Store Upvote the topic, voting again keeps the single vote
+func (ctrl *VotesController) Store(c *gin.Context) {
+	ctx := c.Request.Context()
+	topicModel := topic.Get(ctx, c.Param("id"))
+	if topicModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	userID, topicID := auth.CurrentUID(c), topicModel.GetStringID()
+	if vote.Get(ctx, userID, topicID).ID == 0 {
+		voteModel := vote.TopicVote{UserID: userID, TopicID: topicID}
+		voteModel.Create(ctx)
+		// A concurrent request of the same user may have won the unique index
+		if voteModel.ID == 0 && vote.Get(ctx, userID, topicID).ID == 0 {
+			response.Abort500(c, "Failed to vote, please try later~")
+			return
+		}
+	}
+
+	ctrl.voted(c, topicID, true)
+}
+
+// Delete Take the vote back, not having voted is fine as well
+func (ctrl *VotesController) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	topicModel := topic.Get(ctx, c.Param("id"))
+	if topicModel.ID == 0 {
+		response.Abort404(c)
+		return
+	}
+
+	topicID := topicModel.GetStringID()
+	if voteModel := vote.Get(ctx, auth.CurrentUID(c), topicID); voteModel.ID > 0 {
+		voteModel.Delete(ctx)
+	}
+
+	ctrl.voted(c, topicID, false)
+}
+
+func (ctrl *VotesController) voted(c *gin.Context, topicID string, voted bool) {
+	response.Data(c, gin.H{
+		"topic_id":   topicID,
+		"voted":      voted,
+		"vote_count": vote.Count(c.Request.Context(), topicID),
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"gohub/app/models/user"
+	"gohub/pkg/auth"
 	"gohub/pkg/config"
 	"gohub/pkg/jwt"
 	"gohub/pkg/response"
@@ -23,6 +24,12 @@ func AuthJWT() gin.HandlerFunc {
 			return
 		}
 
+		// The session of the token may have been revoked from another device
+		if !auth.SessionActive(c.Request.Context(), claims.FamilyID) {
+			response.Unauthorized(c, "The login has been revoked, please log in again")
+			return
+		}
+
 		// JWT parsed successfully, set user information
 		userModel := user.Get(c.Request.Context(), claims.UserID)
 		if userModel.ID == 0 {
@@ -35,6 +42,8 @@ func AuthJWT() gin.HandlerFunc {
 		c.Set("current_user_id", userModel.GetStringID())
 		c.Set("current_user_name", userModel.Name)
 		c.Set("current_user", userModel)
+		c.Set("current_session_family", claims.FamilyID)
+		auth.TouchSession(c)
 
 		c.Next()
 	}
@@ -0,0 +1,19 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/pkg/auth"
+	"gohub/pkg/response"
+)
+
+// Can Only allow users whose roles grant the permission, must be used after AuthJWT
+func Can(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.Can(c, permission) {
+			response.Abort403(c)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/pkg/auth"
+	"gohub/pkg/response"
+)
+
+// EnsureVerified Only allow users who verified their email, must be used after AuthJWT.
+// Users without an email signed up with a phone number, which was verified by an SMS code.
+func EnsureVerified() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser := auth.CurrentUser(c)
+		if currentUser.Email == "" && currentUser.Phone != "" {
+			c.Next()
+			return
+		}
+		if !currentUser.HasVerifiedEmail() {
+			response.Abort403(c, "Please verify your email address first")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,59 +1,59 @@
 package middlewares
 
 import (
-	"net/http"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/spf13/cast"
+	limiterLib "github.com/ulule/limiter/v3"
 	"gohub/app/limiter"
-	"gohub/pkg/app"
 	"gohub/pkg/logger"
 	"gohub/pkg/response"
 )
 
-// LimitIP Global current limiting middleware, limiting current for IP
-// limit is a format string, such as "5-S", example:
+// LimitIP Global current limiting middleware, limiting current for IP.
+// policy is the name of a policy in config/limit.go, example:
 //
-// * 5 reqs/second: "5-S"
-// * 10 reqs/minute: "10-M"
-// * 1000 reqs/hour: "1000-H"
-// * 2000 reqs/day: "2000-D"
-func LimitIP(limit string) gin.HandlerFunc {
-	if app.IsTesting() {
-		limit = "1000000-H"
-	}
-
+//	v1.Use(middlewares.LimitIP("global"))
+func LimitIP(policy string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Current limit for IP
-		key := limiter.GetKeyIP(c)
-		if ok := limitHandler(c, key, limit); !ok {
+		if ok := limitHandler(c, policy, limiter.Policy(policy), limiter.GetKeyIP(c)); !ok {
 			return
 		}
 		c.Next()
 	}
 }
 
-// LimitPerRoute Throttle middleware, used in a separate route
-func LimitPerRoute(limit string) gin.HandlerFunc {
-	if app.IsTesting() {
-		limit = "1000000-H"
-	}
+// LimitUser Limit the current user wherever the requests come from, guests are limited by IP.
+// Used after AuthJWT, example:
+//
+//	tpcGroup.POST("", middlewares.AuthJWT(), middlewares.LimitUser("user"), tpc.Store)
+func LimitUser(policy string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// For a single route, increase the number of visits
-		c.Set("limiter-once", false)
+		if ok := limitHandler(c, policy, limiter.Policy(policy), limiter.GetKeyUser(c)); !ok {
+			return
+		}
+		c.Next()
+	}
+}
 
+// LimitPerRoute Throttle middleware, used in a separate route, limit.routes can override its policy
+func LimitPerRoute(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, formatted := limiter.RoutePolicy(c, policy)
 		// Limit traffic for IP + routing
-		key := limiter.GetKeyRouteWithIP(c)
-		if ok := limitHandler(c, key, limit); !ok {
+		if ok := limitHandler(c, policy, formatted, limiter.GetKeyRouteWithIP(c)); !ok {
 			return
 		}
 		c.Next()
 	}
 }
 
-func limitHandler(c *gin.Context, key string, limit string) bool {
-	// get excess
-	rate, err := limiter.CheckRate(c, key, limit)
+func limitHandler(c *gin.Context, policy, formatted, key string) bool {
+	// Counters are per policy, so the same key can be limited by several policies
+	rate, err := limiter.CheckRate(c, policy+":"+key, formatted)
 	if err != nil {
 		logger.LogIf(err)
 		response.Abort500(c)
@@ -61,19 +61,22 @@ func limitHandler(c *gin.Context, key string, limit string) bool {
 	}
 
 	// ---- Set header information ----
-	// X-RateLimit-Limit :10000 Maximum number of visits
-	// X-RateLimit-Remaining :9993 Visits remaining
-	// X-RateLimit-Reset :1513784506 At this point in time, the number of visits will be reset to X-RateLimit-Limit
-	c.Header("X-RateLimit-Limit", cast.ToString(rate.Limit))
-	c.Header("X-RateLimit-Remaining", cast.ToString(rate.Remaining))
-	c.Header("X-RateLimit-Reset", cast.ToString(rate.Reset))
+	// RateLimit-Limit :200 Maximum number of visits in the window
+	// RateLimit-Remaining :193 Visits remaining
+	// RateLimit-Reset :1200 Seconds until the number of visits is reset to RateLimit-Limit
+	// RateLimit-Policy :200;w=3600 The limit and the window in seconds
+	reset := time.Until(time.Unix(rate.Reset, 0))
+	c.Header("RateLimit-Limit", strconv.FormatInt(rate.Limit, 10))
+	c.Header("RateLimit-Remaining", strconv.FormatInt(rate.Remaining, 10))
+	c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(max(reset, 0).Seconds()))))
+	if parsed, err := limiterLib.NewRateFromFormatted(formatted); err == nil {
+		c.Header("RateLimit-Policy", strconv.FormatInt(parsed.Limit, 10)+";w="+strconv.Itoa(int(parsed.Period.Seconds())))
+	}
 
 	// excess
 	if rate.Reached {
 		// Notify the user that the quota is exceeded
-		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-			"message": "Interface requests are too frequent",
-		})
+		response.TooManyRequests(c, reset, "Interface requests are too frequent")
 		return false
 	}
 	return true
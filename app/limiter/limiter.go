@@ -6,12 +6,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	limiterLib "github.com/ulule/limiter/v3"
-	memoryStore "github.com/ulule/limiter/v3/drivers/store/memory"
-	sredis "github.com/ulule/limiter/v3/drivers/store/redis"
-	"gohub/pkg/app"
+	"gohub/pkg/auth"
 	"gohub/pkg/config"
 	"gohub/pkg/logger"
-	"gohub/pkg/redis"
 )
 
 // GetKeyIP Get Limiter's Key, IP
@@ -19,51 +16,39 @@ func GetKeyIP(c *gin.Context) string {
 	return c.ClientIP()
 }
 
+// GetKeyUser Limiter's Key, the current user ID, guests are keyed by IP
+func GetKeyUser(c *gin.Context) string {
+	if uid := auth.CurrentUID(c); uid != "" {
+		return "user:" + uid
+	}
+	return GetKeyIP(c)
+}
+
 // GetKeyRouteWithIP Limiter's Key, route + IP, limits current for a single route
 func GetKeyRouteWithIP(c *gin.Context) string {
 	return routeToKeyString(c.FullPath() + c.ClientIP())
 }
 
-// CheckRate Detect whether the request is overclocked
+// CheckRate Detect whether the request is overclocked, counted with the limit.algorithm
 func CheckRate(c *gin.Context, key, formatted string) (limiterLib.Context, error) {
 	// Instantiate the limiter.Rate object of the dependent limiter package
-	var context limiterLib.Context
 	rate, err := limiterLib.NewRateFromFormatted(formatted)
 	if err != nil {
 		logger.LogIf(err)
-		return context, err
+		return limiterLib.Context{}, err
 	}
 
-	// Initialize the storage, using the shared redis.Redis object in the program
-	var store limiterLib.Store
-	if app.IsTesting() {
-		store = memoryStore.NewStore()
-	} else {
-		var err error
-		store, err = sredis.NewStoreWithOptions(redis.Redis.Client, limiterLib.StoreOptions{
-			// Set a prefix for limiter to keep redis data tidy
-			Prefix: config.GetString("app.name") + ":limiter",
-		})
-		if err != nil {
-			logger.LogIf(err)
-			return context, err
-		}
+	store, err := GetStore(RateStore)
+	if err != nil {
+		logger.LogIf(err)
+		return limiterLib.Context{}, err
 	}
 
-	// Use the limiter.Rate object and storage object initialized above
-	limiterObj := limiterLib.New(store, rate)
-
-	// Get the result of the current limit
-	if c.GetBool("limiter-once") {
-		// Peek() takes the result without increasing the number of visits
-		return limiterObj.Peek(c, key)
-	} else {
-		// Make sure that when LimitIP is called in multiple routing groups to limit traffic,
-		// only one visit will be added
-		c.Set("limiter-core", true)
-		// Get() takes the result and increases the number of visits
-		return limiterObj.Get(c, key)
-	}
+	// Make sure that when the same key is limited by several middlewares of a route,
+	// only one visit will be added, the others peek at the result
+	peek := c.GetBool("limiter:" + key)
+	c.Set("limiter:"+key, true)
+	return take(c, store, config.GetString("limit.algorithm"), rate, key, peek)
 }
 
 // routeToKeyString helper method, format '/' in the URL as '-'
@@ -1,9 +1,20 @@
 package limiter
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	limiterLib "github.com/ulule/limiter/v3"
+	memoryStore "github.com/ulule/limiter/v3/drivers/store/memory"
+	appconfig "gohub/config"
+	pkgconfig "gohub/pkg/config"
 )
 
 func TestRouteToKeyString(t *testing.T) {
@@ -19,3 +30,142 @@ func TestRouteToKeyString(t *testing.T) {
 		require.Equal(t, expected, routeToKeyString(input))
 	}
 }
+
+func initTestConfig(t *testing.T, env string) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	envPath := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("APP_ENV=local\n"+env), 0o644))
+	t.Setenv("APP_ENV_PATH", envPath)
+	appconfig.Initialize()
+	pkgconfig.InitConfig("")
+}
+
+func newContext(method, path string) *gin.Context {
+	c, engine := gin.CreateTestContext(httptest.NewRecorder())
+	engine.Handle(method, path, func(*gin.Context) {})
+	c.Request = httptest.NewRequest(method, path, nil)
+	engine.HandleContext(c)
+	return c
+}
+
+func TestPolicy(t *testing.T) {
+	initTestConfig(t, "LIMIT_LOGIN=5-M\n")
+
+	policy, formatted := RoutePolicy(newContext(http.MethodPost, "/api/v1/auth/login/using-password"), "login")
+	require.Equal(t, "login", policy)
+	require.Equal(t, "5-M", formatted)
+	require.Equal(t, "5-M", Policy("login"))
+	require.Equal(t, "3-H", Policy("3-H"))
+
+	pkgconfig.Add("limit", func() map[string]any {
+		return map[string]any{
+			"policies": map[string]any{"login": "5-M", "strict": "1-S"},
+			"routes": map[string]any{
+				"POST /api/v1/topics":  "strict",
+				"DELETE /api/v1/links": "3-H",
+			},
+		}
+	})
+	pkgconfig.InitConfig("")
+
+	policy, formatted = RoutePolicy(newContext(http.MethodPost, "/api/v1/topics"), "user")
+	require.Equal(t, "strict", policy)
+	require.Equal(t, "1-S", formatted)
+
+	policy, formatted = RoutePolicy(newContext(http.MethodDelete, "/api/v1/links"), "user")
+	require.Equal(t, "3-H", policy)
+	require.Equal(t, "3-H", formatted)
+
+	// Other methods of the path keep the policy of the middleware
+	policy, formatted = RoutePolicy(newContext(http.MethodGet, "/api/v1/topics"), "login")
+	require.Equal(t, "login", policy)
+	require.Equal(t, "5-M", formatted)
+}
+
+func TestCheckRateFixedWindow(t *testing.T) {
+	initTestConfig(t, "LIMIT_ALGORITHM=fixed\n")
+	SetStore(RateStore, memoryStore.NewStore())
+
+	for i := int64(1); i <= 3; i++ {
+		result, err := CheckRate(newContext(http.MethodGet, "/"), "fixed", "3-M")
+		require.NoError(t, err)
+		require.False(t, result.Reached)
+		require.Equal(t, 3-i, result.Remaining)
+	}
+
+	c := newContext(http.MethodGet, "/")
+	result, err := CheckRate(c, "fixed", "3-M")
+	require.NoError(t, err)
+	require.True(t, result.Reached)
+	require.Greater(t, result.Reset, time.Now().Unix())
+
+	// The same key limited again in a request is not counted twice
+	result, err = CheckRate(c, "fixed", "5-M")
+	require.NoError(t, err)
+	require.False(t, result.Reached)
+	require.Equal(t, int64(1), result.Remaining)
+
+	// Keys are counted separately
+	result, err = CheckRate(newContext(http.MethodGet, "/"), "other", "3-M")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.Remaining)
+}
+
+func TestCheckRateSlidingWindow(t *testing.T) {
+	initTestConfig(t, "LIMIT_ALGORITHM=sliding\n")
+	SetStore(RateStore, memoryStore.NewStore())
+
+	for i := 0; i < 3; i++ {
+		result, err := CheckRate(newContext(http.MethodGet, "/"), "sliding", "3-H")
+		require.NoError(t, err)
+		require.False(t, result.Reached)
+	}
+	result, err := CheckRate(newContext(http.MethodGet, "/"), "sliding", "3-H")
+	require.NoError(t, err)
+	require.True(t, result.Reached)
+	require.Equal(t, int64(0), result.Remaining)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore.NewStore()
+	rate := limiterLib.Rate{Period: time.Minute, Limit: 10}
+	start := time.Now().Truncate(time.Minute)
+
+	// 10 requests late in the previous window
+	for i := 0; i < 10; i++ {
+		result, err := slidingWindow(ctx, store, rate, "key", start.Add(-10*time.Second), false)
+		require.NoError(t, err)
+		require.False(t, result.Reached)
+	}
+	result, err := slidingWindow(ctx, store, rate, "key", start.Add(-5*time.Second), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), result.Remaining)
+
+	// A quarter into the current window, 7.5 of them still count
+	now := start.Add(15 * time.Second)
+	result, err = slidingWindow(ctx, store, rate, "key", now, false)
+	require.NoError(t, err)
+	require.False(t, result.Reached)
+	require.Equal(t, int64(1), result.Remaining)
+
+	result, err = slidingWindow(ctx, store, rate, "key", now, false)
+	require.NoError(t, err)
+	require.False(t, result.Reached)
+	require.Equal(t, int64(0), result.Remaining)
+
+	result, err = slidingWindow(ctx, store, rate, "key", now, false)
+	require.NoError(t, err)
+	require.True(t, result.Reached)
+	// 10 * (1 - t) + 3 <= 9 once t reaches 0.4 of the window
+	require.Equal(t, start.Add(24*time.Second).Unix(), result.Reset)
+
+	// Three quarters in, 2.5 of the previous window and the 4 of this one count
+	now = start.Add(45 * time.Second)
+	result, err = slidingWindow(ctx, store, rate, "key", now, false)
+	require.NoError(t, err)
+	require.False(t, result.Reached)
+	require.Equal(t, int64(3), result.Remaining)
+}
@@ -0,0 +1,118 @@
+package limiter
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	limiterLib "github.com/ulule/limiter/v3"
+	"gohub/app/models/user"
+	"gohub/pkg/cache"
+	"gohub/pkg/config"
+	"gohub/pkg/logger"
+)
+
+// Lockout Progressive lockout of a key after repeated failures, such as the wrong passwords of an account.
+// Failures are counted atomically, MaxAttempts of them lock the key for Duration,
+// each further failure doubles it up to MaxDuration.
+type Lockout struct {
+	Key string
+	// Client Only locks the key for this client, such as an IP, when set. The failures of the key
+	// are still counted together, so once they reach MaxAttempts every failing client is locked.
+	Client      string
+	MaxAttempts int
+	Duration    time.Duration
+	MaxDuration time.Duration
+	// Decay The count starts over this long after the first failure
+	Decay time.Duration
+}
+
+// NewLoginLockout The lockout of an account for the IP of a login, login is a phone number, email or name.
+// The phone number, email and name of an account share its failures, logins of no account are counted
+// by themselves. Failing logins from one IP can not lock the owner of the account out.
+func NewLoginLockout(ctx context.Context, login, ip string) *Lockout {
+	login = strings.TrimSpace(login)
+	key := "login:unknown:" + strings.ToLower(login)
+	if userModel := user.GetByUtil(ctx, login); userModel.ID > 0 {
+		key = "login:user:" + userModel.GetStringID()
+	}
+	return newLoginLockout(key, ip)
+}
+
+// NewTwoFactorLockout The lockout of the two-factor codes of a user, after the password was right
+func NewTwoFactorLockout(userID string) *Lockout {
+	return newLoginLockout("two_factor:"+userID, "")
+}
+
+// newLoginLockout The lockout of key with the login.* settings
+func newLoginLockout(key, client string) *Lockout {
+	return &Lockout{
+		Key:         key,
+		Client:      client,
+		MaxAttempts: config.GetInt("login.max_attempts"),
+		Duration:    time.Second * time.Duration(config.GetInt("login.lockout")),
+		MaxDuration: time.Second * time.Duration(config.GetInt("login.max_lockout")),
+		Decay:       time.Minute * time.Duration(config.GetInt("login.decay")),
+	}
+}
+
+// RetryAfter How long the key is still locked, 0 when it is not
+func (l *Lockout) RetryAfter() time.Duration {
+	if !cache.Has(l.untilKey()) {
+		return 0
+	}
+	return max(time.Until(time.Unix(cast.ToInt64(cache.Get(l.untilKey())), 0)), 0)
+}
+
+// Fail Count a failure, returns how long the key is locked now and the failures left before it is
+func (l *Lockout) Fail(ctx context.Context) (retryAfter time.Duration, attemptsLeft int) {
+	counter, err := l.counter()
+	if err != nil {
+		return 0, l.MaxAttempts
+	}
+	result, err := counter.Get(ctx, l.Key)
+	if err != nil {
+		logger.LogIf(err)
+		return 0, l.MaxAttempts
+	}
+
+	failures := int(result.Limit - result.Remaining)
+	if failures < l.MaxAttempts {
+		return 0, l.MaxAttempts - failures
+	}
+
+	retryAfter = l.MaxDuration
+	if exponent := failures - l.MaxAttempts; exponent < 32 {
+		retryAfter = min(l.Duration*time.Duration(math.Pow(2, float64(exponent))), l.MaxDuration)
+	}
+	cache.Set(l.untilKey(), time.Now().Add(retryAfter).Unix(), retryAfter)
+	return retryAfter, 0
+}
+
+// Clear Forget the failures and the lockout of the client, after a success
+func (l *Lockout) Clear(ctx context.Context) {
+	cache.Forget(l.untilKey())
+	if counter, err := l.counter(); err == nil {
+		_, err = counter.Reset(ctx, l.Key)
+		logger.LogIf(err)
+	}
+}
+
+func (l *Lockout) untilKey() string {
+	if l.Client != "" {
+		return "lockout:" + l.Key + ":" + l.Client
+	}
+	return "lockout:" + l.Key
+}
+
+// counter Counts the failures of Decay, the limit is never reached
+func (l *Lockout) counter() (*limiterLib.Limiter, error) {
+	store, err := GetStore(LockoutStore)
+	if err != nil {
+		logger.LogIf(err)
+		return nil, err
+	}
+	return limiterLib.New(store, limiterLib.Rate{Period: l.Decay, Limit: math.MaxInt32}), nil
+}
@@ -0,0 +1,29 @@
+package limiter
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gohub/pkg/config"
+)
+
+// Policy The rate of the named policy in config/limit.go, such as "200-H",
+// a name that is not a policy is taken as a rate itself
+func Policy(name string) (formatted string) {
+	// Config map keys are case-insensitive, stored in lower case
+	if formatted = config.GetStringMapString("limit.policies")[strings.ToLower(name)]; formatted == "" {
+		formatted = name
+	}
+	return formatted
+}
+
+// RoutePolicy The name and rate of the policy of a route limit.
+// The route override in limit.routes replaces the policy, it is used as the name when it is a rate.
+// Only the per-route limits use it, the IP and user limits shared by many routes keep their own counters.
+func RoutePolicy(c *gin.Context, name string) (policy, formatted string) {
+	route := strings.ToLower(c.Request.Method + " " + c.FullPath())
+	if override, ok := config.GetStringMapString("limit.routes")[route]; ok {
+		name = override
+	}
+	return name, Policy(name)
+}
@@ -0,0 +1,86 @@
+package limiter
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	limiterLib "github.com/ulule/limiter/v3"
+)
+
+// Algorithms counting the requests of a rate, set with limit.algorithm
+const (
+	// FixedWindow The count starts over Period after the first request, allows bursts of twice the
+	// limit around the end of a window
+	FixedWindow = "fixed"
+	// SlidingWindow The count of the current window plus the previous one weighted by how much of it
+	// still overlaps the last Period
+	SlidingWindow = "sliding"
+)
+
+// counterLimit Window counters are never limited themselves, their count is Limit - Remaining
+const counterLimit = math.MaxInt32
+
+// take Count a request of key, or only peek at the count
+func take(ctx context.Context, store limiterLib.Store, algorithm string, rate limiterLib.Rate, key string, peek bool) (limiterLib.Context, error) {
+	if algorithm == SlidingWindow {
+		return slidingWindow(ctx, store, rate, key, time.Now(), peek)
+	}
+
+	limiterObj := limiterLib.New(store, rate)
+	if peek {
+		return limiterObj.Peek(ctx, key)
+	}
+	return limiterObj.Get(ctx, key)
+}
+
+// slidingWindow Approximate a sliding window with the counters of two fixed windows aligned to Period
+func slidingWindow(ctx context.Context, store limiterLib.Store, rate limiterLib.Rate, key string, now time.Time, peek bool) (limiterLib.Context, error) {
+	period := int64(rate.Period)
+	window := now.UnixNano() / period
+	start := time.Unix(0, window*period)
+	// Counters live for two periods, the previous window is read during the whole current one
+	counter := limiterLib.Rate{Period: 2 * rate.Period, Limit: counterLimit}
+
+	previous, err := store.Peek(ctx, key+":"+strconv.FormatInt(window-1, 10), counter)
+	if err != nil {
+		return limiterLib.Context{}, err
+	}
+	var current limiterLib.Context
+	if peek {
+		current, err = store.Peek(ctx, key+":"+strconv.FormatInt(window, 10), counter)
+	} else {
+		current, err = store.Get(ctx, key+":"+strconv.FormatInt(window, 10), counter)
+	}
+	if err != nil {
+		return limiterLib.Context{}, err
+	}
+
+	previousCount := float64(counterLimit - previous.Remaining)
+	currentCount := float64(counterLimit - current.Remaining)
+	elapsed := float64(now.Sub(start)) / float64(period)
+	count := int64(math.Ceil(previousCount*(1-elapsed) + currentCount))
+
+	result := limiterLib.Context{
+		Limit:     rate.Limit,
+		Remaining: max(rate.Limit-count, 0),
+		Reached:   count > rate.Limit,
+	}
+
+	// Reset is when there is room for one more request
+	room := float64(rate.Limit - 1)
+	switch {
+	case !result.Reached && result.Remaining > 0:
+		result.Reset = start.Add(rate.Period).Unix()
+	case currentCount <= room:
+		// The previous window slides out far enough during the current one
+		until := 1 - (room-currentCount)/previousCount
+		result.Reset = start.Add(time.Duration(until * float64(period))).Unix()
+	default:
+		// The current window has to slide out during the next one
+		until := 1 - room/currentCount
+		result.Reset = start.Add(rate.Period + time.Duration(until*float64(period))).Unix()
+	}
+	return result, nil
+}
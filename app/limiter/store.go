@@ -0,0 +1,39 @@
+package limiter
+
+import (
+	"fmt"
+	"sync"
+
+	limiterLib "github.com/ulule/limiter/v3"
+)
+
+// Names of the stores used by the limiter, set up by bootstrap.SetupLimiter
+const (
+	// RateStore Counts requests for the limit middlewares
+	RateStore = "rate"
+	// LockoutStore Counts the failures of a Lockout
+	LockoutStore = "lockout"
+)
+
+var (
+	storesMu sync.RWMutex
+	stores   = map[string]limiterLib.Store{}
+)
+
+// SetStore Register the store of name, replacing the previous one
+func SetStore(name string, store limiterLib.Store) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	stores[name] = store
+}
+
+// GetStore The store registered as name
+func GetStore(name string) (limiterLib.Store, error) {
+	storesMu.RLock()
+	defer storesMu.RUnlock()
+	store, ok := stores[name]
+	if !ok {
+		return nil, fmt.Errorf("limiter: store %s is not set up", name)
+	}
+	return store, nil
+}
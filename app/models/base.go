@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/spf13/cast"
+	"gorm.io/gorm"
 )
 
 // BaseModel Model base class
@@ -18,6 +19,11 @@ type CommonTimestampsField struct {
 	UpdatedAt time.Time `gorm:"updated_at;index;" json:"updated_at"`
 }
 
+// SoftDeletes Deleting only sets deleted_at, queries skip those rows unless Unscoped
+type SoftDeletes struct {
+	DeletedAt gorm.DeletedAt `gorm:"index;" json:"deleted_at,omitempty"`
+}
+
 // GetStringID Get ID in string format
 func (a BaseModel) GetStringID() string {
 	return cast.ToString(a.ID)
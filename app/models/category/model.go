@@ -15,6 +15,7 @@ type Category struct {
 	Description string `json:"description,omitempty"`
 
 	models.CommonTimestampsField
+	models.SoftDeletes
 }
 
 func (category *Category) Create(ctx context.Context) {
@@ -30,3 +31,8 @@ func (category *Category) Delete(ctx context.Context) (rowsAffected int64) {
 	result := database.DBWithContext(ctx).Delete(&category)
 	return result.RowsAffected
 }
+
+// SortableFields Columns the list API can sort by
+func (category *Category) SortableFields() []string {
+	return []string{"id", "name", "created_at", "updated_at"}
+}
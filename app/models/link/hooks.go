@@ -1,8 +1,17 @@
 package link
 
+import (
+	"gohub/pkg/cache"
+	"gorm.io/gorm"
+)
+
 // func (link *Link) BeforeSave(tx *gorm.DB) (err error) {}
 
-// func (link *Link) AfterSave(tx *gorm.DB) (err error) {}
+// AfterSave Cached links are outdated after creating or updating
+func (link *Link) AfterSave(_ *gorm.DB) (err error) {
+	cache.Forget(allCacheKey)
+	return
+}
 
 // func (link *Link) BeforeCreate(tx *gorm.DB) (err error) {}
 
@@ -14,6 +23,10 @@ package link
 
 // func (link *Link) BeforeDelete(tx *gorm.DB) (err error) {}
 
-// func (link *Link) AfterDelete(tx *gorm.DB) (err error) {}
+// AfterDelete Cached links are outdated after deleting
+func (link *Link) AfterDelete(_ *gorm.DB) (err error) {
+	cache.Forget(allCacheKey)
+	return
+}
 
 // func (link *Link) AfterFind(tx *gorm.DB) (err error) {}
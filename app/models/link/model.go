@@ -30,3 +30,8 @@ func (link *Link) Delete(ctx context.Context) (rowsAffected int64) {
 	result := database.DBWithContext(ctx).Delete(&link)
 	return result.RowsAffected
 }
+
+// SortableFields Columns the list API can sort by
+func (link *Link) SortableFields() []string {
+	return []string{"id", "name", "created_at", "updated_at"}
+}
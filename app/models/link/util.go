@@ -31,9 +31,12 @@ func Paginate(ctx context.Context, c *gin.Context, limit int) (links []Link, pag
 	return models.Paginate[Link](ctx, c, limit)
 }
 
+// allCacheKey Cache key of AllCached, forgotten by the model hooks when links change
+const allCacheKey = "links:all"
+
 func AllCached(ctx context.Context) (links []Link) {
 	// Set cache key
-	cacheKey := "links:all"
+	cacheKey := allCacheKey
 	// Set expire time
 	expireTime := 120 * time.Minute
 	// Get data
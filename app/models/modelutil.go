@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gohub/pkg/database"
@@ -34,8 +35,37 @@ func Exists[T any](ctx context.Context, field string, value any) bool {
 	return count > 0
 }
 
-func Paginate[T any](ctx context.Context, c *gin.Context, limit int) (models []T, paging paginator.Paging) {
-	query := database.DBWithContext(ctx).Model(new(T))
+// Paginate Page through T, scopes narrow down the query, e.g. with WHERE conditions
+func Paginate[T any](ctx context.Context, c *gin.Context, limit int, scopes ...func(*gorm.DB) *gorm.DB) (models []T, paging paginator.Paging) {
+	query := database.DBWithContext(ctx).Model(new(T)).Scopes(scopes...)
 	paging = paginator.Paginate(ctx, c, query, &models, limit)
 	return
 }
+
+// CursorPaginate Like Paginate, with keyset pagination
+func CursorPaginate[T any](ctx context.Context, c *gin.Context, limit int, scopes ...func(*gorm.DB) *gorm.DB) (models []T, paging paginator.Paging) {
+	query := database.DBWithContext(ctx).Model(new(T)).Scopes(scopes...)
+	paging = paginator.CursorPaginate(ctx, c, query, &models, limit)
+	return
+}
+
+// PruneTrashed Permanently delete the rows of T soft-deleted before the given time
+// Rows are deleted one by one so that their delete hooks can clean up,
+// scopes can exclude rows that are still referenced
+func PruneTrashed[T any](ctx context.Context, before time.Time, scopes ...func(*gorm.DB) *gorm.DB) (pruned int64, err error) {
+	var batch []T
+	err = database.DBWithContext(ctx).Unscoped().
+		Scopes(scopes...).
+		Where("deleted_at < ?", before).
+		FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				result := database.DBWithContext(ctx).Unscoped().Delete(&batch[i])
+				if result.Error != nil {
+					return result.Error
+				}
+				pruned += result.RowsAffected
+			}
+			return nil
+		}).Error
+	return
+}
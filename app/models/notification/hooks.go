@@ -0,0 +1,19 @@
+package notification
+
+// func (notification *Notification) BeforeSave(tx *gorm.DB) (err error) {}
+
+// func (notification *Notification) AfterSave(tx *gorm.DB) (err error) {}
+
+// func (notification *Notification) BeforeCreate(tx *gorm.DB) (err error) {}
+
+// func (notification *Notification) AfterCreate(tx *gorm.DB) (err error) {}
+
+// func (notification *Notification) BeforeUpdate(tx *gorm.DB) (err error) {}
+
+// func (notification *Notification) AfterUpdate(tx *gorm.DB) (err error) {}
+
+// func (notification *Notification) BeforeDelete(tx *gorm.DB) (err error) {}
+
+// func (notification *Notification) AfterDelete(tx *gorm.DB) (err error) {}
+
+// func (notification *Notification) AfterFind(tx *gorm.DB) (err error) {}
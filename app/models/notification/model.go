@@ -0,0 +1,75 @@
+// Package notification model
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gohub/app/models"
+	"gohub/app/models/user"
+	"gohub/pkg/database"
+)
+
+// Kinds of notification
+const (
+	TypeReply   = "reply"   // someone replied to your topic
+	TypeAnswer  = "answer"  // someone answered your reply
+	TypeMention = "mention" // someone mentioned you with @name
+	TypeVote    = "vote"    // someone upvoted your topic
+)
+
+// Notification Tells UserID that ActorID did something on one of their topics or replies
+type Notification struct {
+	models.BaseModel
+
+	UserID  string `json:"user_id,omitempty"`
+	ActorID string `json:"actor_id,omitempty"`
+	Type    string `json:"type"`
+
+	// The topic it happened on, its title is kept as it was then
+	TopicID string `json:"topic_id,omitempty"`
+	Title   string `json:"title"`
+	// The reply, 0 for notifications about the topic itself
+	ReplyID uint64 `json:"reply_id"`
+
+	ReadAt *time.Time `json:"read_at"`
+
+	// Associate the user who caused it by actor_id
+	Actor user.User `json:"actor" gorm:"foreignKey:ActorID"`
+
+	models.CommonTimestampsField
+}
+
+// MarkRead Set read_at, reading twice keeps the first time
+func (notification *Notification) MarkRead(ctx context.Context) (rowsAffected int64) {
+	if notification.ReadAt != nil {
+		return 0
+	}
+	now := time.Now()
+	result := database.DBWithContext(ctx).Model(&notification).Update("read_at", now)
+	if result.RowsAffected > 0 {
+		notification.ReadAt = &now
+	}
+	return result.RowsAffected
+}
+
+// Message What happened, in a sentence
+func (notification *Notification) Message(actorName string) string {
+	switch notification.Type {
+	case TypeReply:
+		return fmt.Sprintf("%s replied to your topic \"%s\"", actorName, notification.Title)
+	case TypeAnswer:
+		return fmt.Sprintf("%s answered your reply on \"%s\"", actorName, notification.Title)
+	case TypeMention:
+		return fmt.Sprintf("%s mentioned you in \"%s\"", actorName, notification.Title)
+	case TypeVote:
+		return fmt.Sprintf("%s upvoted your topic \"%s\"", actorName, notification.Title)
+	}
+	return fmt.Sprintf("%s: %s", actorName, notification.Title)
+}
+
+// SortableFields Columns the list API can sort by
+func (notification *Notification) SortableFields() []string {
+	return []string{"id", "created_at", "read_at"}
+}
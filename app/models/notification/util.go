@@ -0,0 +1,129 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gohub/app/models"
+	"gohub/app/models/user"
+	"gohub/pkg/config"
+	"gohub/pkg/database"
+	"gohub/pkg/logger"
+	"gohub/pkg/mail"
+	"gohub/pkg/paginator"
+	"gorm.io/gorm"
+)
+
+// GetOf The notification of the user, its ID is 0 when not found
+func GetOf(ctx context.Context, userID, idStr string) (notification Notification) {
+	database.DBWithContext(ctx).
+		Preload("Actor").
+		Where("id = ? AND user_id = ?", idStr, userID).
+		First(&notification)
+	return
+}
+
+// Paginate Notifications of the user, only the unread ones with unread
+func Paginate(ctx context.Context, c *gin.Context, userID string, unread bool, limit int) (notifications []Notification, paging paginator.Paging) {
+	return models.Paginate[Notification](ctx, c, limit, func(db *gorm.DB) *gorm.DB {
+		db = db.Preload("Actor").Where("user_id = ?", userID)
+		if unread {
+			db = db.Where("read_at IS NULL")
+		}
+		return db
+	})
+}
+
+// UnreadCount Number of notifications the user has not read yet
+func UnreadCount(ctx context.Context, userID string) (count int64) {
+	database.DBWithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count)
+	return
+}
+
+// ReadAll Mark every notification of the user as read
+func ReadAll(ctx context.Context, userID string) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected
+}
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9]{3,20})\b`)
+
+// Mentioned IDs of the users mentioned in body with @name
+func Mentioned(tx *gorm.DB, body string) (userIDs []string, err error) {
+	var names []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		names = append(names, match[1])
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	err = tx.Session(&gorm.Session{NewDB: true}).
+		Model(&user.User{}).
+		Where("name IN ?", names).
+		Pluck("id", &userIDs).Error
+	return userIDs, err
+}
+
+// Send Store the notifications, called from the model hooks with their transaction.
+// Users are notified once, by the first of their notifications, and never of their own actions.
+// The stored notifications are returned for Deliver, once the transaction is committed.
+func Send(tx *gorm.DB, notifications ...Notification) (sent []Notification, err error) {
+	db := tx.Session(&gorm.Session{NewDB: true})
+	notified := map[string]bool{}
+
+	for _, notification := range notifications {
+		if notification.UserID == "" || notification.UserID == notification.ActorID || notified[notification.UserID] {
+			continue
+		}
+		notified[notification.UserID] = true
+
+		if err = db.Create(&notification).Error; err != nil {
+			return nil, err
+		}
+		sent = append(sent, notification)
+	}
+	return sent, nil
+}
+
+// Deliver Queue emails of the notifications returned by Send to their users, when notification.mail is on.
+// Called after the transaction of Send committed, so no email tells of a rolled back notification.
+func Deliver(ctx context.Context, notifications ...Notification) {
+	if !config.GetBool("notification.mail") {
+		return
+	}
+	for _, notification := range notifications {
+		logger.LogIf(deliver(ctx, notification))
+	}
+}
+
+// deliver Queue an email of the notification to its user, when they have an email address
+func deliver(ctx context.Context, notification Notification) error {
+	var recipient, actor user.User
+	if err := database.DBWithContext(ctx).Select("id", "email").First(&recipient, notification.UserID).Error; err != nil {
+		return err
+	}
+	if recipient.Email == "" {
+		return nil
+	}
+	if err := database.DBWithContext(ctx).Select("id", "name").First(&actor, notification.ActorID).Error; err != nil {
+		return err
+	}
+
+	subject := notification.Message(actor.Name)
+	return mail.NewMailer().Queue(ctx, mail.Email{
+		From: mail.From{
+			Address: config.GetString("mail.from.address"),
+			Name:    config.GetString("mail.from.name"),
+		},
+		To:      []string{recipient.Email},
+		Subject: subject,
+		Text:    []byte(fmt.Sprintf("%s\n\n%s/topics/%s", subject, config.GetString("app.url"), notification.TopicID)),
+	})
+}
@@ -0,0 +1,19 @@
+package permission
+
+// func (permission *Permission) BeforeSave(tx *gorm.DB) (err error) {}
+
+// func (permission *Permission) AfterSave(tx *gorm.DB) (err error) {}
+
+// func (permission *Permission) BeforeCreate(tx *gorm.DB) (err error) {}
+
+// func (permission *Permission) AfterCreate(tx *gorm.DB) (err error) {}
+
+// func (permission *Permission) BeforeUpdate(tx *gorm.DB) (err error) {}
+
+// func (permission *Permission) AfterUpdate(tx *gorm.DB) (err error) {}
+
+// func (permission *Permission) BeforeDelete(tx *gorm.DB) (err error) {}
+
+// func (permission *Permission) AfterDelete(tx *gorm.DB) (err error) {}
+
+// func (permission *Permission) AfterFind(tx *gorm.DB) (err error) {}
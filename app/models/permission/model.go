@@ -0,0 +1,39 @@
+// Package permission model
+package permission
+
+import (
+	"context"
+
+	"gohub/app/models"
+	"gohub/pkg/database"
+)
+
+// Permission names, checked by middlewares.Can and the policies
+const (
+	CategoriesManage = "categories.manage"
+	LinksManage      = "links.manage"
+	TopicsModerate   = "topics.moderate"
+)
+
+type Permission struct {
+	models.BaseModel
+
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	models.CommonTimestampsField
+}
+
+func (permission *Permission) Create(ctx context.Context) {
+	database.DBWithContext(ctx).Create(&permission)
+}
+
+func (permission *Permission) Save(ctx context.Context) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Save(&permission)
+	return result.RowsAffected
+}
+
+func (permission *Permission) Delete(ctx context.Context) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Delete(&permission)
+	return result.RowsAffected
+}
@@ -0,0 +1,36 @@
+package permission
+
+import (
+	"context"
+
+	"gohub/app/models"
+	"gohub/pkg/database"
+)
+
+func Get(ctx context.Context, idStr string) (permission Permission) {
+	return models.Get[Permission](ctx, idStr)
+}
+
+func GetBy(ctx context.Context, field, value string) (permission Permission) {
+	return models.GetBy[Permission](ctx, field, value)
+}
+
+func All(ctx context.Context) (permissions []Permission) {
+	return models.All[Permission](ctx)
+}
+
+func IsExist(ctx context.Context, field, value string) bool {
+	return models.Exists[Permission](ctx, field, value)
+}
+
+// NamesOfUser Names of all permissions the user gets through the roles
+func NamesOfUser(ctx context.Context, userID string) (names []string) {
+	database.DBWithContext(ctx).
+		Model(&Permission{}).
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Distinct("permissions.name").
+		Pluck("permissions.name", &names)
+	return
+}
@@ -0,0 +1,97 @@
+package reply
+
+import (
+	"gohub/app/models/notification"
+	"gohub/app/models/topic"
+	"gorm.io/gorm"
+)
+
+// func (reply *Reply) BeforeSave(tx *gorm.DB) (err error) {}
+
+// func (reply *Reply) AfterSave(tx *gorm.DB) (err error) {}
+
+// func (reply *Reply) BeforeCreate(tx *gorm.DB) (err error) {}
+
+// AfterCreate Keep the reply counter and the last reply time of the topic up-to-date,
+// then notify the authors of the topic and of the answered reply, and the mentioned users
+func (reply *Reply) AfterCreate(tx *gorm.DB) (err error) {
+	err = tx.Model(&topic.Topic{}).
+		Where("id = ?", reply.TopicID).
+		UpdateColumns(map[string]any{
+			"reply_count":   gorm.Expr("reply_count + ?", 1),
+			"last_reply_at": reply.CreatedAt,
+		}).Error
+	if err != nil {
+		return err
+	}
+	return reply.notify(tx.Session(&gorm.Session{NewDB: true}))
+}
+
+// func (reply *Reply) BeforeUpdate(tx *gorm.DB) (err error) {}
+
+// func (reply *Reply) AfterUpdate(tx *gorm.DB) (err error) {}
+
+// func (reply *Reply) BeforeDelete(tx *gorm.DB) (err error) {}
+
+// AfterDelete Remove the answers to this reply, then recalculate the topic counters
+func (reply *Reply) AfterDelete(tx *gorm.DB) (err error) {
+	// Deleting the children one by one triggers this hook recursively
+	var children []Reply
+	if err = tx.Where("parent_id = ?", reply.ID).Find(&children).Error; err != nil {
+		return err
+	}
+	for i := range children {
+		if err = tx.Delete(&children[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&topic.Topic{}).
+		Where("id = ?", reply.TopicID).
+		UpdateColumns(map[string]any{
+			"reply_count": gorm.Expr("reply_count - ?", 1),
+			"last_reply_at": tx.Model(&Reply{}).
+				Select("MAX(created_at)").
+				Where("topic_id = ?", reply.TopicID),
+		}).Error
+}
+
+func (reply *Reply) notify(tx *gorm.DB) error {
+	var topicModel topic.Topic
+	if err := tx.Select("id", "user_id", "title").First(&topicModel, reply.TopicID).Error; err != nil {
+		return err
+	}
+	newNotification := func(userID, notificationType string) notification.Notification {
+		return notification.Notification{
+			UserID:  userID,
+			ActorID: reply.UserID,
+			Type:    notificationType,
+			TopicID: reply.TopicID,
+			Title:   topicModel.Title,
+			ReplyID: reply.ID,
+		}
+	}
+
+	var notifications []notification.Notification
+	if reply.ParentID > 0 {
+		var parent Reply
+		if err := tx.Select("id", "user_id").First(&parent, reply.ParentID).Error; err != nil {
+			return err
+		}
+		notifications = append(notifications, newNotification(parent.UserID, notification.TypeAnswer))
+	}
+	notifications = append(notifications, newNotification(topicModel.UserID, notification.TypeReply))
+
+	mentioned, err := notification.Mentioned(tx, reply.Body)
+	if err != nil {
+		return err
+	}
+	for _, userID := range mentioned {
+		notifications = append(notifications, newNotification(userID, notification.TypeMention))
+	}
+
+	reply.notified, err = notification.Send(tx, notifications...)
+	return err
+}
+
+// func (reply *Reply) AfterFind(tx *gorm.DB) (err error) {}
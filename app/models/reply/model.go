@@ -0,0 +1,51 @@
+// Package reply model
+package reply
+
+import (
+	"context"
+
+	"gohub/app/models"
+	"gohub/app/models/notification"
+	"gohub/app/models/user"
+	"gohub/pkg/database"
+)
+
+type Reply struct {
+	models.BaseModel
+
+	Body    string `json:"body,omitempty"`
+	TopicID string `json:"topic_id,omitempty"`
+	UserID  string `json:"user_id,omitempty"`
+
+	// ParentID Points to the reply being answered, 0 means a top-level reply
+	ParentID uint64 `json:"parent_id"`
+
+	// Associate users by user_id
+	User user.User `json:"user"`
+
+	models.CommonTimestampsField
+
+	// Sent by the AfterCreate hook, delivered once they are committed
+	notified []notification.Notification
+}
+
+func (reply *Reply) Create(ctx context.Context) {
+	if database.DBWithContext(ctx).Create(&reply).Error == nil {
+		notification.Deliver(ctx, reply.notified...)
+	}
+}
+
+func (reply *Reply) Save(ctx context.Context) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Save(&reply)
+	return result.RowsAffected
+}
+
+func (reply *Reply) Delete(ctx context.Context) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Delete(&reply)
+	return result.RowsAffected
+}
+
+// SortableFields Columns the list API can sort by
+func (reply *Reply) SortableFields() []string {
+	return []string{"id", "created_at", "updated_at"}
+}
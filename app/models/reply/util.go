@@ -0,0 +1,33 @@
+package reply
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"gohub/app/models"
+	"gohub/pkg/database"
+	"gohub/pkg/paginator"
+)
+
+func Get(ctx context.Context, idStr string) (reply Reply) {
+	return models.Get[Reply](ctx, idStr)
+}
+
+func GetBy(ctx context.Context, field, value string) (reply Reply) {
+	return models.GetBy[Reply](ctx, field, value)
+}
+
+func All(ctx context.Context) (replies []Reply) {
+	return models.All[Reply](ctx)
+}
+
+func IsExist(ctx context.Context, field, value string) bool {
+	return models.Exists[Reply](ctx, field, value)
+}
+
+// PaginateByTopic Pagination content of the replies under a topic
+func PaginateByTopic(ctx context.Context, c *gin.Context, topicID string, limit int) (replies []Reply, paging paginator.Paging) {
+	query := database.DBWithContext(ctx).Model(new(Reply)).Where("topic_id = ?", topicID)
+	paging = paginator.Paginate(ctx, c, query, &replies, limit)
+	return
+}
@@ -0,0 +1,19 @@
+package role
+
+// func (role *Role) BeforeSave(tx *gorm.DB) (err error) {}
+
+// func (role *Role) AfterSave(tx *gorm.DB) (err error) {}
+
+// func (role *Role) BeforeCreate(tx *gorm.DB) (err error) {}
+
+// func (role *Role) AfterCreate(tx *gorm.DB) (err error) {}
+
+// func (role *Role) BeforeUpdate(tx *gorm.DB) (err error) {}
+
+// func (role *Role) AfterUpdate(tx *gorm.DB) (err error) {}
+
+// func (role *Role) BeforeDelete(tx *gorm.DB) (err error) {}
+
+// func (role *Role) AfterDelete(tx *gorm.DB) (err error) {}
+
+// func (role *Role) AfterFind(tx *gorm.DB) (err error) {}
@@ -0,0 +1,62 @@
+// Package role model
+package role
+
+import (
+	"context"
+
+	"gohub/app/models"
+	"gohub/app/models/permission"
+	"gohub/pkg/database"
+)
+
+// Built-in roles, created by the SeedRolesTable seeder
+const (
+	Admin     = "admin"
+	Moderator = "moderator"
+)
+
+type Role struct {
+	models.BaseModel
+
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Permissions []permission.Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
+
+	models.CommonTimestampsField
+}
+
+// UserRole A row of the user_roles pivot table
+type UserRole struct {
+	UserID uint64 `gorm:"primaryKey;autoIncrement:false"`
+	RoleID uint64 `gorm:"primaryKey;autoIncrement:false"`
+}
+
+func (role *Role) Create(ctx context.Context) {
+	database.DBWithContext(ctx).Create(&role)
+}
+
+func (role *Role) Save(ctx context.Context) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Save(&role)
+	return result.RowsAffected
+}
+
+func (role *Role) Delete(ctx context.Context) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Delete(&role)
+	return result.RowsAffected
+}
+
+// AssignTo Give the role to the user, assigning twice has no effect
+func (role *Role) AssignTo(ctx context.Context, userID uint64) error {
+	return database.DBWithContext(ctx).
+		Where(UserRole{UserID: userID, RoleID: role.ID}).
+		FirstOrCreate(&UserRole{}).Error
+}
+
+// RevokeFrom Take the role away from the user
+func (role *Role) RevokeFrom(ctx context.Context, userID uint64) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).
+		Where("user_id = ? AND role_id = ?", userID, role.ID).
+		Delete(&UserRole{})
+	return result.RowsAffected
+}
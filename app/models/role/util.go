@@ -0,0 +1,34 @@
+package role
+
+import (
+	"context"
+
+	"gohub/app/models"
+	"gohub/pkg/database"
+)
+
+func Get(ctx context.Context, idStr string) (role Role) {
+	return models.Get[Role](ctx, idStr)
+}
+
+func GetBy(ctx context.Context, field, value string) (role Role) {
+	return models.GetBy[Role](ctx, field, value)
+}
+
+func All(ctx context.Context) (roles []Role) {
+	return models.All[Role](ctx)
+}
+
+func IsExist(ctx context.Context, field, value string) bool {
+	return models.Exists[Role](ctx, field, value)
+}
+
+// NamesOfUser Names of all roles the user has
+func NamesOfUser(ctx context.Context, userID string) (names []string) {
+	database.DBWithContext(ctx).
+		Model(&Role{}).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("roles.name", &names)
+	return
+}
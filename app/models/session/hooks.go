@@ -0,0 +1,19 @@
+package session
+
+// func (session *Session) BeforeSave(tx *gorm.DB) (err error) {}
+
+// func (session *Session) BeforeCreate(tx *gorm.DB) (err error) {}
+
+// func (session *Session) AfterCreate(tx *gorm.DB) (err error) {}
+
+// func (session *Session) BeforeUpdate(tx *gorm.DB) (err error) {}
+
+// func (session *Session) AfterUpdate(tx *gorm.DB) (err error) {}
+
+// func (session *Session) AfterSave(tx *gorm.DB) (err error) {}
+
+// func (session *Session) BeforeDelete(tx *gorm.DB) (err error) {}
+
+// func (session *Session) AfterDelete(tx *gorm.DB) (err error) {}
+
+// func (session *Session) AfterFind(tx *gorm.DB) (err error) {}
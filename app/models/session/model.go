@@ -0,0 +1,46 @@
+// Package session model
+package session
+
+import (
+	"context"
+	"time"
+
+	"gohub/app/models"
+	"gohub/pkg/database"
+)
+
+// Session A login of a user, one per token family of pkg/jwt
+type Session struct {
+	models.BaseModel
+
+	UserID string `json:"-"`
+	// FamilyID The token family of pkg/jwt, the tokens carry it in their claims
+	FamilyID string `json:"-"`
+
+	// Device A name like "Chrome on Windows", or the X-Device-Name header of the login
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// ExpiresAt The end of the maximum refresh time, the user has to log in again after it
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"-"`
+
+	models.CommonTimestampsField
+}
+
+func (session *Session) Create(ctx context.Context) error {
+	return database.DBWithContext(ctx).Create(&session).Error
+}
+
+// Revoke Log the session out, it stays until it is pruned
+func (session *Session) Revoke(ctx context.Context) (rowsAffected int64) {
+	if session.ID == 0 {
+		return 0
+	}
+	now := time.Now()
+	result := database.DBWithContext(ctx).Model(&session).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", &now)
+	return result.RowsAffected
+}
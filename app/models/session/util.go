@@ -0,0 +1,47 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"gohub/pkg/database"
+	"gorm.io/gorm"
+)
+
+// active Sessions that were neither revoked nor expired
+func active(db *gorm.DB) *gorm.DB {
+	return db.Where("revoked_at IS NULL AND expires_at > ?", time.Now())
+}
+
+// GetActiveByFamily The active session of the token family
+func GetActiveByFamily(ctx context.Context, familyID string) (session Session) {
+	database.DBWithContext(ctx).Scopes(active).Where("family_id = ?", familyID).First(&session)
+	return
+}
+
+// GetActiveOf The active session idStr of userID
+func GetActiveOf(ctx context.Context, userID, idStr string) (session Session) {
+	database.DBWithContext(ctx).Scopes(active).Where("user_id = ? AND id = ?", userID, idStr).First(&session)
+	return
+}
+
+// ActiveOf The active sessions of userID, the last seen first
+func ActiveOf(ctx context.Context, userID string) (sessions []Session) {
+	database.DBWithContext(ctx).Scopes(active).Where("user_id = ?", userID).
+		Order("last_seen_at DESC").Order("id DESC").Find(&sessions)
+	return
+}
+
+// Touch Record the request of the session of the token family
+func Touch(ctx context.Context, familyID, ip string) {
+	database.DBWithContext(ctx).Model(&Session{}).Where("family_id = ?", familyID).
+		Updates(map[string]any{"last_seen_at": time.Now(), "ip": ip})
+}
+
+// PruneEnded Delete the sessions revoked or expired before the time
+func PruneEnded(ctx context.Context, before time.Time) (int64, error) {
+	result := database.DBWithContext(ctx).
+		Where("revoked_at < ? OR expires_at < ?", before, before).
+		Delete(&Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,19 @@
+package socialaccount
+
+// func (socialAccount *SocialAccount) BeforeSave(tx *gorm.DB) (err error) {}
+
+// func (socialAccount *SocialAccount) BeforeCreate(tx *gorm.DB) (err error) {}
+
+// func (socialAccount *SocialAccount) AfterCreate(tx *gorm.DB) (err error) {}
+
+// func (socialAccount *SocialAccount) BeforeUpdate(tx *gorm.DB) (err error) {}
+
+// func (socialAccount *SocialAccount) AfterUpdate(tx *gorm.DB) (err error) {}
+
+// func (socialAccount *SocialAccount) AfterSave(tx *gorm.DB) (err error) {}
+
+// func (socialAccount *SocialAccount) BeforeDelete(tx *gorm.DB) (err error) {}
+
+// func (socialAccount *SocialAccount) AfterDelete(tx *gorm.DB) (err error) {}
+
+// func (socialAccount *SocialAccount) AfterFind(tx *gorm.DB) (err error) {}
@@ -0,0 +1,35 @@
+// Package socialaccount model
+package socialaccount
+
+import (
+	"context"
+
+	"gohub/app/models"
+	"gohub/pkg/database"
+)
+
+// SocialAccount An account at an OAuth provider linked to a user, a user links at most one per provider
+type SocialAccount struct {
+	models.BaseModel
+
+	UserID   string `json:"user_id,omitempty"`
+	Provider string `json:"provider"`
+	// ProviderUserID The ID of the account at the provider
+	ProviderUserID string `json:"provider_user_id"`
+
+	// As the provider told when the account was linked
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Avatar string `json:"avatar"`
+
+	models.CommonTimestampsField
+}
+
+func (socialAccount *SocialAccount) Create(ctx context.Context) error {
+	return database.DBWithContext(ctx).Create(&socialAccount).Error
+}
+
+func (socialAccount *SocialAccount) Delete(ctx context.Context) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Delete(&socialAccount)
+	return result.RowsAffected
+}
@@ -0,0 +1,33 @@
+package socialaccount
+
+import (
+	"context"
+
+	"gohub/pkg/database"
+)
+
+// GetByProvider The account with the ID providerUserID at provider
+func GetByProvider(ctx context.Context, provider, providerUserID string) (socialAccount SocialAccount) {
+	database.DBWithContext(ctx).
+		Where("provider = ? AND provider_user_id = ?", provider, providerUserID).
+		First(&socialAccount)
+	return
+}
+
+// GetOf The account userID linked at provider
+func GetOf(ctx context.Context, userID, provider string) (socialAccount SocialAccount) {
+	database.DBWithContext(ctx).Where("user_id = ? AND provider = ?", userID, provider).First(&socialAccount)
+	return
+}
+
+// AllOf The accounts linked to userID
+func AllOf(ctx context.Context, userID string) (socialAccounts []SocialAccount) {
+	database.DBWithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&socialAccounts)
+	return
+}
+
+// CountOf The number of accounts linked to userID
+func CountOf(ctx context.Context, userID string) (count int64) {
+	database.DBWithContext(ctx).Model(&SocialAccount{}).Where("user_id = ?", userID).Count(&count)
+	return
+}
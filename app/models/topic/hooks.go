@@ -1,19 +1,66 @@
 package topic
 
+import (
+	"gohub/app/models/notification"
+	"gorm.io/gorm"
+)
+
 // func (topic *Topic) BeforeSave(tx *gorm.DB) (err error) {}
 
-// func (topic *Topic) AfterSave(tx *gorm.DB) (err error) {}
+// AfterSave Keep the full-text index in step with the title and body
+func (topic *Topic) AfterSave(tx *gorm.DB) (err error) {
+	return indexForSearch(tx, topic)
+}
 
 // func (topic *Topic) BeforeCreate(tx *gorm.DB) (err error) {}
 
-// func (topic *Topic) AfterCreate(tx *gorm.DB) (err error) {}
+// AfterCreate Notify the users mentioned in the body
+func (topic *Topic) AfterCreate(tx *gorm.DB) (err error) {
+	tx = tx.Session(&gorm.Session{NewDB: true})
+	mentioned, err := notification.Mentioned(tx, topic.Body)
+	if err != nil {
+		return err
+	}
+
+	notifications := make([]notification.Notification, 0, len(mentioned))
+	for _, userID := range mentioned {
+		notifications = append(notifications, notification.Notification{
+			UserID:  userID,
+			ActorID: topic.UserID,
+			Type:    notification.TypeMention,
+			TopicID: topic.GetStringID(),
+			Title:   topic.Title,
+		})
+	}
+	topic.notified, err = notification.Send(tx, notifications...)
+	return err
+}
 
 // func (topic *Topic) BeforeUpdate(tx *gorm.DB) (err error) {}
 
 // func (topic *Topic) AfterUpdate(tx *gorm.DB) (err error) {}
 
-// func (topic *Topic) BeforeDelete(tx *gorm.DB) (err error) {}
+// BeforeDelete A trashed topic keeps its replies, votes and notifications so it can be restored,
+// they are only removed when the topic is deleted permanently
+func (topic *Topic) BeforeDelete(tx *gorm.DB) (err error) {
+	if !tx.Statement.Unscoped {
+		return nil
+	}
+	if err = tx.Exec("DELETE FROM replies WHERE topic_id = ?", topic.ID).Error; err != nil {
+		return err
+	}
+	if err = tx.Exec("DELETE FROM topic_votes WHERE topic_id = ?", topic.ID).Error; err != nil {
+		return err
+	}
+	return tx.Exec("DELETE FROM notifications WHERE topic_id = ?", topic.ID).Error
+}
 
-// func (topic *Topic) AfterDelete(tx *gorm.DB) (err error) {}
+// AfterDelete Likewise the full-text index entry stays while the topic is in the trash
+func (topic *Topic) AfterDelete(tx *gorm.DB) (err error) {
+	if !tx.Statement.Unscoped {
+		return nil
+	}
+	return removeFromSearch(tx, topic)
+}
 
 // func (topic *Topic) AfterFind(tx *gorm.DB) (err error) {}
@@ -3,9 +3,11 @@ package topic
 
 import (
 	"context"
+	"time"
 
 	"gohub/app/models"
 	"gohub/app/models/category"
+	"gohub/app/models/notification"
 	"gohub/app/models/user"
 	"gohub/pkg/database"
 )
@@ -18,6 +20,14 @@ type Topic struct {
 	UserID     string `json:"user_id,omitempty"`
 	CategoryID string `json:"category_id,omitempty"`
 
+	// Maintained by the reply model hooks
+	ReplyCount  int64      `json:"reply_count"`
+	LastReplyAt *time.Time `json:"last_reply_at,omitempty"`
+
+	// Written by vote.Reconcile, the live count is vote.Count
+	VoteCount int64   `json:"vote_count"`
+	HotScore  float64 `json:"-"`
+
 	// Associate users by user_id
 	User user.User `json:"user"`
 
@@ -25,10 +35,16 @@ type Topic struct {
 	Category category.Category `json:"category"`
 
 	models.CommonTimestampsField
+	models.SoftDeletes
+
+	// Sent by the AfterCreate hook, delivered once they are committed
+	notified []notification.Notification
 }
 
 func (topic *Topic) Create(ctx context.Context) {
-	database.DBWithContext(ctx).Create(&topic)
+	if database.DBWithContext(ctx).Create(&topic).Error == nil {
+		notification.Deliver(ctx, topic.notified...)
+	}
 }
 
 func (topic *Topic) Save(ctx context.Context) (rowsAffected int64) {
@@ -36,7 +52,24 @@ func (topic *Topic) Save(ctx context.Context) (rowsAffected int64) {
 	return result.RowsAffected
 }
 
+// Delete Move the topic to the trash, see Restore
 func (topic *Topic) Delete(ctx context.Context) (rowsAffected int64) {
 	result := database.DBWithContext(ctx).Delete(&topic)
 	return result.RowsAffected
 }
+
+// Restore Take the topic out of the trash
+func (topic *Topic) Restore(ctx context.Context) (rowsAffected int64) {
+	result := database.DBWithContext(ctx).Unscoped().Model(&topic).Update("deleted_at", nil)
+	return result.RowsAffected
+}
+
+// SortableFields Columns the list API can sort by
+func (topic *Topic) SortableFields() []string {
+	return []string{"id", "created_at", "updated_at", "reply_count", "last_reply_at", "vote_count", "hot_score"}
+}
+
+// SortAliases sort=hot ranks recent topics by votes, see vote.Reconcile
+func (topic *Topic) SortAliases() map[string]string {
+	return map[string]string{"hot": "-hot_score"}
+}
@@ -0,0 +1,163 @@
+package topic
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gohub/pkg/config"
+	"gohub/pkg/database"
+	"gohub/pkg/paginator"
+	"gorm.io/gorm"
+)
+
+// SearchResult Topic matched by a full-text search
+type SearchResult struct {
+	Topic
+
+	// Relevance of the match, higher is better
+	Rank float64 `json:"rank" gorm:"column:search_rank;->"`
+	// HTML-escaped fragment of the body with the matched terms wrapped in <mark>
+	Snippet string `json:"snippet" gorm:"->"`
+}
+
+const (
+	highlightStart = "<mark>"
+	highlightStop  = "</mark>"
+	snippetRunes   = 120
+
+	// The databases wrap matches in these private use runes, the body is escaped before they become tags
+	matchStart = "\uE000"
+	matchStop  = "\uE001"
+)
+
+// markMatches Escape a fragment with matches wrapped in matchStart/matchStop and turn them into <mark> tags
+var markMatches = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&#34;", "'", "&#39;",
+	matchStart, highlightStart, matchStop, highlightStop,
+)
+
+// pgSearchVector Title matches weigh more than body matches
+const pgSearchVector = "setweight(to_tsvector('simple', coalesce(title, '')), 'A') || " +
+	"setweight(to_tsvector('simple', coalesce(body, '')), 'B')"
+
+// Search Find topics whose title or body match q, the most relevant first
+func Search(ctx context.Context, c *gin.Context, q string, limit int) (results []SearchResult, paging paginator.Paging) {
+	query := database.DBWithContext(ctx).Model(&Topic{})
+
+	switch config.Get("database.connection") {
+	case "postgresql":
+		query = query.
+			Select("topics.*, ts_rank(topics.search_vector, websearch_to_tsquery('simple', ?)) AS search_rank, "+
+				"ts_headline('simple', topics.body, websearch_to_tsquery('simple', ?), ?) AS snippet",
+				q, q, "StartSel="+matchStart+", StopSel="+matchStop+", MaxWords=35, MinWords=15").
+			Where("topics.search_vector @@ websearch_to_tsquery('simple', ?)", q).
+			Order("search_rank DESC")
+	case "mysql":
+		query = query.
+			Select("topics.*, MATCH (topics.title, topics.body) AGAINST (? IN NATURAL LANGUAGE MODE) AS search_rank", q).
+			Where("MATCH (topics.title, topics.body) AGAINST (? IN NATURAL LANGUAGE MODE)", q).
+			Order("search_rank DESC")
+	case "sqlite":
+		match := sqliteMatchQuery(q)
+		if match == "" {
+			return []SearchResult{}, paginator.Paging{}
+		}
+		query = query.
+			Joins("JOIN topics_fts ON topics_fts.rowid = topics.id").
+			Where("topics_fts MATCH ?", match)
+		if sqliteHasFTS5(ctx) {
+			query = query.
+				Select("topics.*, -bm25(topics_fts, 10.0, 1.0) AS search_rank, "+
+					"snippet(topics_fts, -1, ?, ?, '...', 16) AS snippet", matchStart, matchStop).
+				Order("search_rank DESC")
+		} else {
+			// FTS4 has no built-in ranking function, fall back to the newest matches
+			query = query.
+				Select("topics.*, 0 AS search_rank, snippet(topics_fts, ?, ?, '...', -1, 16) AS snippet", matchStart, matchStop).
+				Order("topics.id DESC")
+		}
+	}
+
+	paging = paginator.Paginate(ctx, c, query, &results, limit)
+
+	// MySQL can not build snippets itself
+	for i := range results {
+		if results[i].Snippet == "" {
+			results[i].Snippet = highlight(results[i].Body, q)
+		}
+		results[i].Snippet = markMatches.Replace(results[i].Snippet)
+	}
+
+	return
+}
+
+func indexForSearch(tx *gorm.DB, topic *Topic) error {
+	switch config.Get("database.connection") {
+	case "postgresql":
+		return tx.Exec("UPDATE topics SET search_vector = "+pgSearchVector+" WHERE id = ?", topic.ID).Error
+	case "sqlite":
+		if err := tx.Exec("DELETE FROM topics_fts WHERE rowid = ?", topic.ID).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO topics_fts (rowid, title, body) VALUES (?, ?, ?)",
+			topic.ID, topic.Title, topic.Body).Error
+	}
+
+	// The MySQL FULLTEXT index is maintained by the database
+	return nil
+}
+
+func removeFromSearch(tx *gorm.DB, topic *Topic) error {
+	if config.Get("database.connection") == "sqlite" {
+		return tx.Exec("DELETE FROM topics_fts WHERE rowid = ?", topic.ID).Error
+	}
+	return nil
+}
+
+func sqliteHasFTS5(ctx context.Context) (enabled bool) {
+	database.DBWithContext(ctx).Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled)
+	return
+}
+
+// sqliteMatchQuery Quote every term so user input can't use the FTS query syntax
+func sqliteMatchQuery(q string) string {
+	terms := strings.Fields(q)
+	for i, term := range terms {
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(terms, " ")
+}
+
+// highlight Cut a fragment of text around the first matched term and wrap every term in it in matchStart/matchStop
+func highlight(text, q string) string {
+	terms := strings.Fields(q)
+	if len(terms) == 0 {
+		return ""
+	}
+	for i, term := range terms {
+		terms[i] = regexp.QuoteMeta(term)
+	}
+	re := regexp.MustCompile("(?i)" + strings.Join(terms, "|"))
+
+	start := 0
+	if loc := re.FindStringIndex(text); loc != nil {
+		start = loc[0]
+	}
+
+	// Begin a few words before the match, on a rune boundary
+	runes := []rune(text)
+	from := max(utf8.RuneCountInString(text[:start])-snippetRunes/4, 0)
+	to := min(from+snippetRunes, len(runes))
+	fragment := string(runes[from:to])
+	if from > 0 {
+		fragment = "..." + fragment
+	}
+	if to < len(runes) {
+		fragment += "..."
+	}
+
+	return re.ReplaceAllString(fragment, matchStart+"$0"+matchStop)
+}
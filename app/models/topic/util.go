@@ -2,11 +2,14 @@ package topic
 
 import (
 	"context"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gohub/app/models"
+	"gohub/pkg/config"
 	"gohub/pkg/database"
 	"gohub/pkg/paginator"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -27,6 +30,60 @@ func IsExist(ctx context.Context, field, value string) bool {
 	return models.Exists[Topic](ctx, field, value)
 }
 
-func Paginate(ctx context.Context, c *gin.Context, limit int) (topics []Topic, paging paginator.Paging) {
-	return models.Paginate[Topic](ctx, c, limit)
+// Filter Conditions for listing topics, zero values are ignored
+type Filter struct {
+	CategoryID string
+	UserID     string
+
+	// Inclusive lower bound of created_at
+	CreatedAfter time.Time
+	// Exclusive upper bound of created_at
+	CreatedBefore time.Time
+}
+
+func (filter Filter) scope(db *gorm.DB) *gorm.DB {
+	if filter.CategoryID != "" {
+		db = db.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.UserID != "" {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		db = db.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		db = db.Where("created_at < ?", filter.CreatedBefore)
+	}
+	return db
+}
+
+// Paginate Topics are listed with offset pagination, or with keyset pagination when the cursor
+// parameter is given, an empty one starting at the first page. Deep pages of a large table are
+// cheaper with keyset pagination, and topics written meanwhile do not shift them.
+func Paginate(ctx context.Context, c *gin.Context, filter Filter, limit int) (topics []Topic, paging paginator.Paging) {
+	if _, keyset := c.GetQuery(config.Get("paging.url_query_cursor")); keyset {
+		return models.CursorPaginate[Topic](ctx, c, limit, filter.scope)
+	}
+	return models.Paginate[Topic](ctx, c, limit, filter.scope)
+}
+
+// GetTrashed A topic in the trash, its ID is 0 when not found
+func GetTrashed(ctx context.Context, idStr string) (topic Topic) {
+	database.DBWithContext(ctx).Unscoped().
+		Preload(clause.Associations).
+		Where("id", idStr).
+		Where("deleted_at IS NOT NULL").
+		First(&topic)
+	return
+}
+
+// PaginateTrashed Topics in the trash written by userID, or by anyone when userID is empty
+func PaginateTrashed(ctx context.Context, c *gin.Context, userID string, limit int) (topics []Topic, paging paginator.Paging) {
+	return models.Paginate[Topic](ctx, c, limit, func(db *gorm.DB) *gorm.DB {
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+		if userID != "" {
+			db = db.Where("user_id = ?", userID)
+		}
+		return db
+	})
 }
@@ -13,3 +13,28 @@ func (userModel *User) BeforeSave(_ *gorm.DB) (err error) {
 
 	return
 }
+
+// BeforeDelete Votes, notifications, social accounts and sessions are only removed when the user is deleted permanently
+func (userModel *User) BeforeDelete(tx *gorm.DB) (err error) {
+	if !tx.Statement.Unscoped {
+		return nil
+	}
+	if err = tx.Exec("DELETE FROM topic_votes WHERE user_id = ?", userModel.ID).Error; err != nil {
+		return err
+	}
+	if err = tx.Exec("DELETE FROM social_accounts WHERE user_id = ?", userModel.ID).Error; err != nil {
+		return err
+	}
+	if err = tx.Exec("DELETE FROM sessions WHERE user_id = ?", userModel.ID).Error; err != nil {
+		return err
+	}
+	return tx.Exec("DELETE FROM notifications WHERE user_id = ? OR actor_id = ?", userModel.ID, userModel.ID).Error
+}
+
+// AfterDelete Roles are only taken away when the user is deleted permanently
+func (userModel *User) AfterDelete(tx *gorm.DB) (err error) {
+	if !tx.Statement.Unscoped {
+		return nil
+	}
+	return tx.Exec("DELETE FROM user_roles WHERE user_id = ?", userModel.ID).Error
+}
@@ -3,6 +3,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"gohub/app/models"
 	"gohub/pkg/database"
@@ -23,7 +24,17 @@ type User struct {
 	Phone    string `json:"-"`
 	Password string `json:"-"`
 
+	// EmailVerifiedAt When the user proved to own Email, with a code or a signed link
+	EmailVerifiedAt *time.Time `json:"-"`
+
+	// Two-factor authentication is enabled once the secret is confirmed with a code,
+	// TwoFactorRecoveryCodes is a JSON array of the SHA256 hashes of the unused recovery codes
+	TwoFactorSecret        string     `json:"-"`
+	TwoFactorRecoveryCodes string     `json:"-"`
+	TwoFactorConfirmedAt   *time.Time `json:"-"`
+
 	models.CommonTimestampsField
+	models.SoftDeletes
 }
 
 func (userModel *User) Create(ctx context.Context) {
@@ -39,3 +50,18 @@ func (userModel *User) Save(ctx context.Context) (rowsAffected int64) {
 	result := database.DBWithContext(ctx).Save(&userModel)
 	return result.RowsAffected
 }
+
+// HasVerifiedEmail Whether the current email was verified
+func (userModel *User) HasVerifiedEmail() bool {
+	return userModel.Email != "" && userModel.EmailVerifiedAt != nil
+}
+
+// TwoFactorEnabled Whether logins take a code of the authenticator app after the first factor
+func (userModel *User) TwoFactorEnabled() bool {
+	return userModel.TwoFactorConfirmedAt != nil
+}
+
+// SortableFields Columns the list API can sort by
+func (userModel *User) SortableFields() []string {
+	return []string{"id", "name", "created_at", "updated_at"}
+}
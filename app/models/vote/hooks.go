@@ -0,0 +1,51 @@
+package vote
+
+import (
+	"gohub/app/models/notification"
+	"gohub/app/models/topic"
+	"gorm.io/gorm"
+)
+
+// func (topicVote *TopicVote) BeforeSave(tx *gorm.DB) (err error) {}
+
+// func (topicVote *TopicVote) AfterSave(tx *gorm.DB) (err error) {}
+
+// func (topicVote *TopicVote) BeforeCreate(tx *gorm.DB) (err error) {}
+
+// AfterCreate Tell the author of the topic, the cached counter is changed by Create once committed
+func (topicVote *TopicVote) AfterCreate(tx *gorm.DB) (err error) {
+	tx = tx.Session(&gorm.Session{NewDB: true})
+	var topicModel topic.Topic
+	if err = tx.Select("id", "user_id", "title").First(&topicModel, topicVote.TopicID).Error; err != nil {
+		return err
+	}
+
+	// Voting again after taking the vote back does not notify twice
+	var notified int64
+	tx.Model(&notification.Notification{}).
+		Where("user_id = ? AND actor_id = ? AND topic_id = ? AND type = ?",
+			topicModel.UserID, topicVote.UserID, topicVote.TopicID, notification.TypeVote).
+		Count(&notified)
+	if notified > 0 {
+		return nil
+	}
+
+	topicVote.notified, err = notification.Send(tx, notification.Notification{
+		UserID:  topicModel.UserID,
+		ActorID: topicVote.UserID,
+		Type:    notification.TypeVote,
+		TopicID: topicVote.TopicID,
+		Title:   topicModel.Title,
+	})
+	return err
+}
+
+// func (topicVote *TopicVote) BeforeUpdate(tx *gorm.DB) (err error) {}
+
+// func (topicVote *TopicVote) AfterUpdate(tx *gorm.DB) (err error) {}
+
+// func (topicVote *TopicVote) BeforeDelete(tx *gorm.DB) (err error) {}
+
+// func (topicVote *TopicVote) AfterDelete(tx *gorm.DB) (err error) {}
+
+// func (topicVote *TopicVote) AfterFind(tx *gorm.DB) (err error) {}
@@ -0,0 +1,52 @@
+// Package vote model
+package vote
+
+import (
+	"context"
+
+	"gohub/app/models"
+	"gohub/app/models/notification"
+	"gohub/pkg/cache"
+	"gohub/pkg/database"
+)
+
+// TopicVote An upvote of a topic, a user votes a topic at most once
+type TopicVote struct {
+	models.BaseModel
+
+	UserID  string `json:"user_id,omitempty"`
+	TopicID string `json:"topic_id,omitempty"`
+
+	models.CommonTimestampsField
+
+	// Sent by the AfterCreate hook, delivered once they are committed
+	notified []notification.Notification
+}
+
+// Create Store the vote, once it is committed it is counted in the cached counter,
+// topics.vote_count follows on Reconcile
+func (topicVote *TopicVote) Create(ctx context.Context) {
+	counted := counting(topicVote.TopicID)
+	if database.DBWithContext(ctx).Create(&topicVote).Error == nil {
+		if counted {
+			cache.Increment(countKey(topicVote.TopicID))
+		}
+		notification.Deliver(ctx, topicVote.notified...)
+	}
+}
+
+// Delete Remove the vote, it is taken out of the cached counter only when the row was deleted
+func (topicVote *TopicVote) Delete(ctx context.Context) (rowsAffected int64) {
+	counted := counting(topicVote.TopicID)
+	result := database.DBWithContext(ctx).Delete(&topicVote)
+	if result.Error == nil && result.RowsAffected > 0 && counted {
+		cache.Decrement(countKey(topicVote.TopicID))
+	}
+	return result.RowsAffected
+}
+
+// counting Whether the counter of the topic is in the cache, checked before the vote is written:
+// a counter loaded afterwards may count the vote already, and a missing one must not start from 0
+func counting(topicID string) bool {
+	return cache.Has(countKey(topicID))
+}
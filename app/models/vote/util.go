@@ -0,0 +1,106 @@
+package vote
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/spf13/cast"
+	"gohub/app/models/topic"
+	"gohub/pkg/cache"
+	"gohub/pkg/config"
+	"gohub/pkg/database"
+	"gorm.io/gorm"
+)
+
+// Get The vote of the user on the topic, its ID is 0 when the user has not voted
+func Get(ctx context.Context, userID, topicID string) (topicVote TopicVote) {
+	database.DBWithContext(ctx).
+		Where("user_id = ? AND topic_id = ?", userID, topicID).
+		First(&topicVote)
+	return
+}
+
+// Count The live vote count of the topic, kept in the cache by TopicVote Create and Delete
+func Count(ctx context.Context, topicID string) int64 {
+	key := countKey(topicID)
+	if cache.Has(key) {
+		return cache.GetInt64(key)
+	}
+
+	var count int64
+	database.DBWithContext(ctx).Model(&TopicVote{}).Where("topic_id = ?", topicID).Count(&count)
+	// A concurrent read may have loaded the counter and votes moved it since, it is kept
+	if !cache.Add(key, count, 0) {
+		return cache.GetInt64(key)
+	}
+	return count
+}
+
+// Reconcile Write the vote counts into topics.vote_count and refresh the hot scores,
+// counters in the cache that drifted are reset as well. Returns the number of topics updated.
+func Reconcile(ctx context.Context) (updated int64, err error) {
+	var counts []struct {
+		TopicID uint64
+		Votes   int64
+	}
+	err = database.DBWithContext(ctx).Model(&TopicVote{}).
+		Select("topic_id, COUNT(*) AS votes").
+		Group("topic_id").
+		Scan(&counts).Error
+	if err != nil {
+		return 0, err
+	}
+	votes := make(map[uint64]int64, len(counts))
+	for _, count := range counts {
+		votes[count.TopicID] = count.Votes
+	}
+
+	// Topics older than the window no longer rank as hot, their score drops to 0
+	now := time.Now()
+	since := now.AddDate(0, 0, -config.GetInt("vote.hot_window_days"))
+
+	var topics []topic.Topic
+	err = database.DBWithContext(ctx).Unscoped().
+		Select("id", "vote_count", "hot_score", "created_at").
+		Where("vote_count <> 0 OR hot_score <> 0 OR created_at >= ? OR id IN (?)",
+			since, database.DBWithContext(ctx).Model(&TopicVote{}).Select("topic_id")).
+		FindInBatches(&topics, 500, func(_ *gorm.DB, _ int) error {
+			for _, topicModel := range topics {
+				count := votes[topicModel.ID]
+				var score float64
+				if !topicModel.CreatedAt.Before(since) {
+					score = hotScore(count, now.Sub(topicModel.CreatedAt))
+				}
+
+				if count != topicModel.VoteCount || score != topicModel.HotScore {
+					err := database.DBWithContext(ctx).Model(&topic.Topic{}).
+						Where("id = ?", topicModel.ID).
+						UpdateColumns(map[string]any{"vote_count": count, "hot_score": score}).Error
+					if err != nil {
+						return err
+					}
+					updated++
+				}
+
+				if key := countKey(topicModel.GetStringID()); cache.Has(key) {
+					cache.Forever(key, cast.ToString(count))
+				}
+			}
+			return nil
+		}).Error
+
+	return updated, err
+}
+
+// hotScore Votes decayed by age, the same topic sinks as hours pass
+func hotScore(votes int64, age time.Duration) float64 {
+	if votes <= 0 {
+		return 0
+	}
+	return float64(votes) / math.Pow(age.Hours()+2, config.GetFloat64("vote.hot_gravity"))
+}
+
+func countKey(topicID string) string {
+	return "topic:vote_count:" + topicID
+}
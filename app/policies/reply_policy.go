@@ -0,0 +1,15 @@
+package policies
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/permission"
+	"gohub/app/models/reply"
+	"gohub/app/models/topic"
+	"gohub/pkg/auth"
+)
+
+// CanModifyReply The author of the reply, the owner of the topic, and moderators can modify it
+func CanModifyReply(c *gin.Context, _reply reply.Reply, _topic topic.Topic) bool {
+	uid := auth.CurrentUID(c)
+	return uid == _reply.UserID || uid == _topic.UserID || auth.Can(c, permission.TopicsModerate)
+}
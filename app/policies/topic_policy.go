@@ -3,10 +3,17 @@ package policies
 
 import (
 	"github.com/gin-gonic/gin"
+	"gohub/app/models/permission"
 	"gohub/app/models/topic"
 	"gohub/pkg/auth"
 )
 
+// CanModifyTopic Only the owner of the topic
 func CanModifyTopic(c *gin.Context, _topic topic.Topic) bool {
 	return auth.CurrentUID(c) == _topic.UserID
 }
+
+// CanManageTopic The owner, or administrators and moderators who can moderate any topic
+func CanManageTopic(c *gin.Context, _topic topic.Topic) bool {
+	return CanModifyTopic(c, _topic) || auth.Can(c, permission.TopicsModerate)
+}
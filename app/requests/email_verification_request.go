@@ -0,0 +1,58 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/app/requests/validators"
+	"gohub/pkg/auth"
+)
+
+// EmailVerifyLinkRequest Query parameters of the links of verifyemail, the signature is checked by the controller
+type EmailVerifyLinkRequest struct {
+	User  string `valid:"user" form:"user"`
+	Email string `valid:"email" form:"email"`
+	// From Hash of the email the change was asked for, empty when verifying the current email
+	From string `valid:"from" form:"from"`
+}
+
+func EmailVerifyLink(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"user":  []string{"required", "numeric"},
+		"email": []string{"required", "email"},
+	}
+	messages := MapData{
+		"user": []string{
+			"required:The link is incomplete, the user is missing",
+			"numeric:The link is malformed",
+		},
+		"email": []string{
+			"required:The link is incomplete, the email is missing",
+			"email:The link is malformed",
+		},
+	}
+
+	return validate(c, data, rules, messages)
+}
+
+type EmailVerifyCodeRequest struct {
+	VerifyCode string `json:"verify_code,omitempty" valid:"verify_code"`
+}
+
+// EmailVerifyCode Validate the code sent to the current email of the user
+func EmailVerifyCode(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"verify_code": []string{"required", "digits:6"},
+	}
+	messages := MapData{
+		"verify_code": []string{
+			"required:Verification code answer is required",
+			"digits:The verification code must be a 6-digit number",
+		},
+	}
+
+	errs := validate(c, data, rules, messages)
+
+	_data := data.(*EmailVerifyCodeRequest)
+	errs = validators.ValidateVerifyCode(auth.CurrentUser(c).Email, _data.VerifyCode, errs)
+
+	return errs
+}
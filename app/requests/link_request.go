@@ -0,0 +1,31 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type LinkRequest struct {
+	Name string `valid:"name" json:"name"`
+	URL  string `valid:"url" json:"url"`
+}
+
+func LinkSave(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"name": []string{"required", "min_cn:2", "max_cn:50"},
+		"url":  []string{"required", "url", "max:255"},
+	}
+	messages := MapData{
+		"name": []string{
+			"required:Name is required",
+			"min_cn:Name length should be at least 2 words",
+			"max_cn:Name length cannot exceed 50 words",
+		},
+		"url": []string{
+			"required:URL is required",
+			"url:The URL format is incorrect",
+			"max:URL length cannot exceed 255 characters",
+		},
+	}
+
+	return validate(c, data, rules, messages)
+}
@@ -78,3 +78,22 @@ func LoginByPassword(data any, c *gin.Context) map[string][]string {
 
 	return errs
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty" valid:"refresh_token"`
+}
+
+// RefreshToken Validate the form
+func RefreshToken(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"refresh_token": []string{"required"},
+	}
+
+	messages := MapData{
+		"refresh_token": []string{
+			"required:Refresh token is required, and the parameter name is 'refresh_token'",
+		},
+	}
+
+	return validate(c, data, rules, messages)
+}
@@ -0,0 +1,39 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"gohub/app/models/notification"
+)
+
+// NotificationRequest Query parameters for listing the notifications of the current user
+type NotificationRequest struct {
+	Unread string `valid:"unread" form:"unread"`
+
+	Sort   string `valid:"sort" form:"sort"`
+	Order  string `valid:"order" form:"order"`
+	Offset string `valid:"offset" form:"offset"`
+	Limit  string `valid:"limit" form:"limit"`
+}
+
+// OnlyUnread Whether read notifications are left out
+func (request NotificationRequest) OnlyUnread() bool {
+	return cast.ToBool(request.Unread)
+}
+
+func Notification(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"unread": []string{"in:0,1,true,false"},
+	}
+	messages := MapData{
+		"unread": []string{
+			"in:unread only supports 0, 1, true, false",
+		},
+	}
+
+	errs := validate(c, data, rules, messages)
+	for field, fieldErrs := range PaginationOf(&notification.Notification{})(data, c) {
+		errs[field] = append(errs[field], fieldErrs...)
+	}
+	return errs
+}
@@ -0,0 +1,35 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthCallbackRequest Query parameters the provider redirects back with
+type OAuthCallbackRequest struct {
+	Code  string `valid:"code" form:"code"`
+	State string `valid:"state" form:"state"`
+	// Error The provider tells why there is no code, such as access_denied
+	Error string `valid:"error" form:"error"`
+}
+
+func OAuthCallback(data any, c *gin.Context) map[string][]string {
+	_data := data.(*OAuthCallbackRequest)
+	if _data.Error != "" {
+		return map[string][]string{"error": {"The sign in was not completed: " + _data.Error}}
+	}
+
+	rules := MapData{
+		"code":  []string{"required"},
+		"state": []string{"required"},
+	}
+	messages := MapData{
+		"code": []string{
+			"required:The code of the provider is required",
+		},
+		"state": []string{
+			"required:The state is required",
+		},
+	}
+
+	return validate(c, data, rules, messages)
+}
@@ -2,6 +2,8 @@ package requests
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"gohub/pkg/paginator"
 )
 
 type PaginationRequest struct {
@@ -11,18 +13,25 @@ type PaginationRequest struct {
 	Limit  string `valid:"limit" form:"limit"`
 }
 
-func Pagination(data any, c *gin.Context) map[string][]string {
+// PaginationOf Paging parameters for listing model, sortable by its SortableFields and SortAliases
+//
+//	requests.Validate(c, &request, requests.PaginationOf(&user.User{}))
+func PaginationOf(model any) ValidatorFunc {
+	sortable := paginator.SortableFieldsOf(model)
+	aliases := paginator.SortAliasesOf(model)
+	return func(data any, c *gin.Context) map[string][]string {
+		return validatePagination(c, data, sortable, aliases)
+	}
+}
+
+func validatePagination(c *gin.Context, data any, sortable []string, aliases map[string]string) map[string][]string {
 	rules := MapData{
-		"sort":   []string{"in:id,created_at,updated_at"},
 		"order":  []string{"in:asc,desc"},
 		"offset": []string{"numeric_between:0,1000000"},
 		"limit":  []string{"numeric_between:1,100"},
 	}
 
 	messages := MapData{
-		"sort": []string{
-			"in:Sort fields only support id, created_at, updated_at",
-		},
 		"order": []string{
 			"in:Sort fields only support asc (positive order), desc (reverse order)",
 		},
@@ -34,5 +43,16 @@ func Pagination(data any, c *gin.Context) map[string][]string {
 		},
 	}
 
-	return validate(c, data, rules, messages)
+	errs := validate(c, data, rules, messages)
+
+	// Multiple columns are separated by commas, a "-" prefix sorts in reverse order
+	sort, _ := findFieldValue(data, "sort")
+	if sortValue := paginator.ExpandSort(cast.ToString(sort), aliases); sortValue != "" {
+		order, _ := findFieldValue(data, "order")
+		if _, err := paginator.ParseSort(sortValue, cast.ToString(order), sortable); err != nil {
+			errs["sort"] = append(errs["sort"], err.Error())
+		}
+	}
+
+	return errs
 }
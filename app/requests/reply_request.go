@@ -0,0 +1,42 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/reply"
+)
+
+type ReplyRequest struct {
+	Body     string `json:"body,omitempty" valid:"body"`
+	ParentID string `json:"parent_id,omitempty" valid:"parent_id"`
+}
+
+func ReplySave(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"body":      []string{"required", "min_cn:2", "max_cn:10000"},
+		"parent_id": []string{"numeric", "exists:replies,id"},
+	}
+	messages := MapData{
+		"body": []string{
+			"required:Reply body is required",
+			"min_cn:Body length must be greater than 2",
+			"max_cn:Body length must be less than 10000",
+		},
+		"parent_id": []string{
+			"numeric:Parent reply ID must be a number",
+			"exists:Parent reply not found",
+		},
+	}
+
+	errs := validate(c, data, rules, messages)
+
+	// The parent reply must belong to the same topic
+	_data := data.(*ReplyRequest)
+	if len(_data.ParentID) > 0 && len(errs["parent_id"]) == 0 {
+		parent := reply.Get(requestContext(c), _data.ParentID)
+		if parent.TopicID != c.Param("id") {
+			errs["parent_id"] = append(errs["parent_id"], "Parent reply does not belong to this topic")
+		}
+	}
+
+	return errs
+}
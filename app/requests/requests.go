@@ -126,8 +126,9 @@ func buildTag(name, param string) string {
 	if param == "" {
 		return name
 	}
-	safeParam := strings.ReplaceAll(param, ",", "_")
-	safeParam = strings.ReplaceAll(safeParam, "|", "_")
+	// Escape the separators of validator tags, so that values such as "created_at" survive
+	safeParam := strings.ReplaceAll(param, ",", "0x2C")
+	safeParam = strings.ReplaceAll(safeParam, "|", "0x7C")
 	return name + "=" + safeParam
 }
 
@@ -117,7 +117,8 @@ func SignupUsingEmail(data any, c *gin.Context) map[string][]string {
 		"name":             []string{"required", "alphanum", "between:3,20", "not_exists:users,name"},
 		"password":         []string{"required", "min:6"},
 		"password_confirm": []string{"required"},
-		"verify_code":      []string{"required", "digits:6"},
+		// Optional, without it the account waits for the link of the verification email
+		"verify_code": []string{"digits:6"},
 	}
 
 	messages := MapData{
@@ -141,7 +142,6 @@ func SignupUsingEmail(data any, c *gin.Context) map[string][]string {
 			"required:Password confirm is required",
 		},
 		"verify_code": []string{
-			"required:Verification code answer is required",
 			"digits:The verification code must be a 6-digit number",
 		},
 	}
@@ -150,7 +150,10 @@ func SignupUsingEmail(data any, c *gin.Context) map[string][]string {
 
 	_data := data.(*SignupUsingEmailRequest)
 	errs = validators.ValidatePasswordConfirm(_data.Password, _data.PasswordConfirm, errs)
-	errs = validators.ValidateVerifyCode(_data.Email, _data.VerifyCode, errs)
+	// Without a code the account is activated by the link of the verification email
+	if _data.VerifyCode != "" {
+		errs = validators.ValidateVerifyCode(_data.Email, _data.VerifyCode, errs)
+	}
 
 	return errs
 }
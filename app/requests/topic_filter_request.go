@@ -0,0 +1,96 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/topic"
+	"gohub/pkg/app"
+	"gohub/pkg/paginator"
+)
+
+// TopicFilterRequest Query parameters for listing topics, dates use the YYYY-MM-DD format
+type TopicFilterRequest struct {
+	CategoryID    string `valid:"category_id" form:"category_id"`
+	UserID        string `valid:"user_id" form:"user_id"`
+	CreatedAfter  string `valid:"created_after" form:"created_after"`
+	CreatedBefore string `valid:"created_before" form:"created_before"`
+
+	// Offset pagination, or keyset pagination when cursor is given, see topic.Paginate
+	Sort      string `valid:"sort" form:"sort"`
+	Order     string `valid:"order" form:"order"`
+	Limit     string `valid:"limit" form:"limit"`
+	Offset    string `valid:"offset" form:"offset"`
+	Cursor    string `valid:"cursor" form:"cursor"`
+	WithTotal string `valid:"with_total" form:"with_total"`
+}
+
+// CreatedAfterTime The start of the created_after day, zero when not given
+func (request TopicFilterRequest) CreatedAfterTime() time.Time {
+	return parseDate(request.CreatedAfter)
+}
+
+// CreatedBeforeTime The start of the created_before day, zero when not given
+func (request TopicFilterRequest) CreatedBeforeTime() time.Time {
+	return parseDate(request.CreatedBefore)
+}
+
+func TopicFilter(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"category_id":    []string{"numeric"},
+		"user_id":        []string{"numeric"},
+		"created_after":  []string{"datetime:2006-01-02"},
+		"created_before": []string{"datetime:2006-01-02"},
+		"with_total":     []string{"in:0,1,true,false"},
+	}
+	messages := MapData{
+		"category_id": []string{
+			"numeric:Category ID must be a number",
+		},
+		"user_id": []string{
+			"numeric:User ID must be a number",
+		},
+		"created_after": []string{
+			"datetime:created_after must be a date in the YYYY-MM-DD format",
+		},
+		"created_before": []string{
+			"datetime:created_before must be a date in the YYYY-MM-DD format",
+		},
+		"with_total": []string{
+			"in:with_total only supports 0, 1, true, false",
+		},
+	}
+
+	errs := validate(c, data, rules, messages)
+	for field, fieldErrs := range PaginationOf(&topic.Topic{})(data, c) {
+		errs[field] = append(errs[field], fieldErrs...)
+	}
+
+	_data := data.(*TopicFilterRequest)
+	if _, keyset := c.GetQuery("cursor"); keyset && _data.Offset != "" {
+		errs["offset"] = append(errs["offset"], "offset can not be combined with cursor")
+	}
+	if _data.Cursor != "" {
+		if _, err := paginator.DecodeCursor(_data.Cursor); err != nil {
+			errs["cursor"] = append(errs["cursor"], "Invalid cursor, use the next_cursor or prev_cursor of a previous page")
+		}
+	}
+
+	after, before := _data.CreatedAfterTime(), _data.CreatedBeforeTime()
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		errs["created_before"] = append(errs["created_before"], "created_before must be later than created_after")
+	}
+
+	return errs
+}
+
+func parseDate(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	date, err := time.ParseInLocation(time.DateOnly, value, app.Timezone())
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
@@ -2,6 +2,7 @@ package requests
 
 import (
 	"github.com/gin-gonic/gin"
+	"gohub/app/models/topic"
 )
 
 type TopicRequest struct {
@@ -34,3 +35,32 @@ func TopicSave(data any, c *gin.Context) map[string][]string {
 
 	return validate(c, data, rules, messages)
 }
+
+type TopicSearchRequest struct {
+	Q      string `valid:"q" form:"q"`
+	Sort   string `valid:"sort" form:"sort"`
+	Order  string `valid:"order" form:"order"`
+	Offset string `valid:"offset" form:"offset"`
+	Limit  string `valid:"limit" form:"limit"`
+}
+
+// TopicSearch Search keywords plus the usual paging parameters
+func TopicSearch(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"q": []string{"required", "min_cn:2", "max_cn:100"},
+	}
+	messages := MapData{
+		"q": []string{
+			"required:Search keywords are required, and the parameter name is 'q'",
+			"min_cn:Search keywords must be at least 2 characters",
+			"max_cn:Search keywords must be less than 100 characters",
+		},
+	}
+
+	errs := validate(c, data, rules, messages)
+	for field, fieldErrs := range PaginationOf(&topic.Topic{})(data, c) {
+		errs[field] = append(errs[field], fieldErrs...)
+	}
+
+	return errs
+}
@@ -0,0 +1,93 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code,omitempty" valid:"code"`
+}
+
+// TwoFactorConfirm Validate the form
+func TwoFactorConfirm(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"code": []string{"required", "digits:6"},
+	}
+
+	messages := MapData{
+		"code": []string{
+			"required:The code of the authenticator app is required",
+			"digits:The code of the authenticator app must be 6 digits",
+		},
+	}
+
+	return validate(c, data, rules, messages)
+}
+
+// TwoFactorCodeRequest A code of the authenticator app, or one of the recovery codes
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code,omitempty" valid:"code"`
+	RecoveryCode string `json:"recovery_code,omitempty" valid:"recovery_code"`
+}
+
+// TwoFactorCode Validate the form
+func TwoFactorCode(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"code":          []string{"digits:6"},
+		"recovery_code": []string{"max:32"},
+	}
+
+	messages := MapData{
+		"code": []string{
+			"digits:The code of the authenticator app must be 6 digits",
+		},
+		"recovery_code": []string{
+			"max:The recovery code is too long",
+		},
+	}
+
+	errs := validate(c, data, rules, messages)
+
+	_data := data.(*TwoFactorCodeRequest)
+	return validateTwoFactorCode(_data.Code, _data.RecoveryCode, errs)
+}
+
+type LoginByTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token,omitempty" valid:"challenge_token"`
+	Code           string `json:"code,omitempty" valid:"code"`
+	RecoveryCode   string `json:"recovery_code,omitempty" valid:"recovery_code"`
+}
+
+// LoginByTwoFactor Validate the form
+func LoginByTwoFactor(data any, c *gin.Context) map[string][]string {
+	rules := MapData{
+		"challenge_token": []string{"required"},
+		"code":            []string{"digits:6"},
+		"recovery_code":   []string{"max:32"},
+	}
+
+	messages := MapData{
+		"challenge_token": []string{
+			"required:The challenge token of the password login is required",
+		},
+		"code": []string{
+			"digits:The code of the authenticator app must be 6 digits",
+		},
+		"recovery_code": []string{
+			"max:The recovery code is too long",
+		},
+	}
+
+	errs := validate(c, data, rules, messages)
+
+	_data := data.(*LoginByTwoFactorRequest)
+	return validateTwoFactorCode(_data.Code, _data.RecoveryCode, errs)
+}
+
+// validateTwoFactorCode One of code and recovery_code is required
+func validateTwoFactorCode(code, recoveryCode string, errs map[string][]string) map[string][]string {
+	if code == "" && recoveryCode == "" {
+		errs["code"] = append(errs["code"], "The code of the authenticator app or a recovery code is required")
+	}
+	return errs
+}
@@ -59,7 +59,7 @@ func UserUpdateEmail(data any, c *gin.Context) map[string][]string {
 			"not_exists:users,email," + currentUser.GetStringID(),
 			"not_in:" + currentUser.Email,
 		},
-		"verify_code": []string{"required", "digits:6"},
+		"verify_code": []string{"digits:6"},
 	}
 
 	messages := MapData{
@@ -72,14 +72,16 @@ func UserUpdateEmail(data any, c *gin.Context) map[string][]string {
 			"not_in:The new email is the same as the old email",
 		},
 		"verify_code": []string{
-			"required:Verification code answer is required",
 			"digits:The verification code must be a 6-digit number",
 		},
 	}
 
 	errs := validate(c, data, rules, messages)
 	_data := data.(*UserUpdateEmailRequest)
-	errs = validators.ValidateVerifyCode(_data.Email, _data.VerifyCode, errs)
+	// Without a code the change applies once the link sent to the new address is opened
+	if _data.VerifyCode != "" {
+		errs = validators.ValidateVerifyCode(_data.Email, _data.VerifyCode, errs)
+	}
 
 	return errs
 }
@@ -50,8 +50,15 @@ func ValidatePasswordConfirm(password, passwordConfirm string, errs map[string][
 
 // ValidateVerifyCode Customize rules, verify [Mobile/Email Verification Code]
 func ValidateVerifyCode(key, answer string, errs map[string][]string) map[string][]string {
-	if ok := verifycode.NewVerifyCode().CheckAnswer(key, answer); !ok {
-		errs["verify_code"] = append(errs["verify_code"], "Verification code error")
+	ok, attemptsLeft := verifycode.NewVerifyCode().CheckAnswer(key, answer)
+	switch {
+	case ok:
+	case attemptsLeft > 0:
+		errs["verify_code"] = append(errs["verify_code"],
+			fmt.Sprintf("Verification code error, %d attempts left", attemptsLeft))
+	default:
+		errs["verify_code"] = append(errs["verify_code"],
+			"Verification code is invalid or expired, please request a new one")
 	}
 	return errs
 }
@@ -203,13 +210,13 @@ func ValidateFileSize(field validator.FieldLevel) bool {
 }
 
 func splitParam(param string) []string {
+	if strings.Contains(param, ",") {
+		return strings.Split(param, ",")
+	}
 	if strings.Contains(param, "_") {
 		return strings.Split(param, "_")
 	}
-	if strings.Contains(param, "|") {
-		return strings.Split(param, "|")
-	}
-	return strings.Split(param, ",")
+	return strings.Split(param, "|")
 }
 
 // ValidateFileRuleValue validates file rules without relying on validator tags.
@@ -3,13 +3,15 @@ package verifycode
 import "sync"
 
 type MemoryStore struct {
-	mu    sync.RWMutex
-	items map[string]string
+	mu       sync.RWMutex
+	items    map[string]string
+	failures map[string]int
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		items: make(map[string]string),
+		items:    make(map[string]string),
+		failures: make(map[string]int),
 	}
 }
 
@@ -17,6 +19,7 @@ func (store *MemoryStore) Set(id string, value string) bool {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 	store.items[id] = value
+	delete(store.failures, id)
 	return true
 }
 
@@ -32,6 +35,7 @@ func (store *MemoryStore) Get(id string, clear bool) string {
 	if clear {
 		store.mu.Lock()
 		delete(store.items, id)
+		delete(store.failures, id)
 		store.mu.Unlock()
 	}
 
@@ -42,3 +46,10 @@ func (store *MemoryStore) Verify(id, answer string, clear bool) bool {
 	value := store.Get(id, clear)
 	return value == answer
 }
+
+func (store *MemoryStore) Fail(id string) int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.failures[id]++
+	return store.failures[id]
+}
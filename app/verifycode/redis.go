@@ -5,9 +5,13 @@ import (
 
 	"gohub/pkg/app"
 	"gohub/pkg/config"
+	"gohub/pkg/logger"
 	"gohub/pkg/redis"
 )
 
+// failuresSuffix The wrong answers to a code are counted in <key>:failures
+const failuresSuffix = ":failures"
+
 // RedisStore Implement the verifycode.Store interface
 type RedisStore struct {
 	RedisClient *redis.Client
@@ -22,6 +26,7 @@ func (s *RedisStore) Set(key string, value string) bool {
 		ExpireTime = time.Minute * time.Duration(config.GetInt64("verifycode.debug_expire_time"))
 	}
 
+	s.RedisClient.Del(s.KeyPrefix + key + failuresSuffix)
 	return s.RedisClient.Set(s.KeyPrefix+key, value, ExpireTime)
 }
 
@@ -30,7 +35,7 @@ func (s *RedisStore) Get(key string, clear bool) (value string) {
 	key = s.KeyPrefix + key
 	value = s.RedisClient.Get(key)
 	if clear {
-		s.RedisClient.Del(key)
+		s.RedisClient.Del(key, key+failuresSuffix)
 	}
 	return
 }
@@ -40,3 +45,19 @@ func (s *RedisStore) Verify(key, answer string, clear bool) bool {
 	v := s.Get(key, clear)
 	return v == answer
 }
+
+// Fail Implement the Fail method of verifycode.Store, the counter expires with the code
+func (s *RedisStore) Fail(key string) int {
+	key = s.KeyPrefix + key
+	failures, err := s.RedisClient.Client.Incr(s.RedisClient.Context, key+failuresSuffix).Result()
+	if err != nil {
+		logger.ErrorString("Verify Code", "Fail", err.Error())
+		return 0
+	}
+	if failures == 1 {
+		if ttl := s.RedisClient.Client.PTTL(s.RedisClient.Context, key).Val(); ttl > 0 {
+			s.RedisClient.Client.PExpire(s.RedisClient.Context, key+failuresSuffix, ttl)
+		}
+	}
+	return int(failures)
+}
@@ -9,4 +9,8 @@ type Store interface {
 
 	// Verify Check verify code
 	Verify(id, answer string, clear bool) bool
+
+	// Fail Count a wrong answer to the code of id, returns the wrong answers so far,
+	// the count is reset when a new code is set
+	Fail(id string) int
 }
@@ -2,7 +2,7 @@
 package verifycode
 
 import (
-	"fmt"
+	"context"
 	"strings"
 	"sync"
 
@@ -48,58 +48,85 @@ func NewVerifyCode() *VerifyCode {
 // SendSMS Send SMS verification code, example:
 //
 //	verifycode.NewVerifyCode().SendSMS(request.Phone)
-func (vc *VerifyCode) SendSMS(phone string) bool {
+func (vc *VerifyCode) SendSMS(phone string) error {
 	// Generate verification code
 	code := vc.generateVerifyCode(phone)
 
 	if !app.IsProduction() && strings.HasPrefix(phone, config.GetString("verifycode.debug_phone_prefix")) {
-		return true
+		return nil
 	}
 
 	// Send sms
 	return sms.NewSMS().Send(phone, sms.Message{
-		Template: "",
+		Template: "verify_code",
 		Data:     map[string]string{"code": code},
-		Content:  fmt.Sprintf("Your verification code is %v", code),
+		Content:  "Your verification code is {code}",
 	})
 }
 
-// SendEmail Send Email verification code, example:
+// SendEmail Send Email verification code, locale picks the template variant, example:
 //
-//	verifycode.NewVerifyCode().SendEmail(request.Email)
-func (vc *VerifyCode) SendEmail(email string) error {
+//	verifycode.NewVerifyCode().SendEmail(request.Email, c.GetHeader("Accept-Language"))
+func (vc *VerifyCode) SendEmail(email, locale string) error {
 	// generate verify code
 	code := vc.generateVerifyCode(email)
 
 	if !app.IsProduction() && strings.HasSuffix(email, config.GetString("verifycode.debug_email_suffix")) {
 		return nil
 	}
-	content := fmt.Sprintf("<h1>Your email verification code is %v </h1>", code)
-	// Send email
-	mail.NewMailer().Send(mail.Email{
+
+	message := mail.Email{
 		From: mail.From{
 			Address: config.GetString("mail.from.address"),
 			Name:    config.GetString("mail.from.name"),
 		},
-		To:      []string{email},
-		Subject: "Email verify code",
-		HTML:    []byte(content),
-	})
+		To: []string{email},
+	}
+	err := mail.Template{
+		Name:   "verify_code",
+		Locale: locale,
+		Data: map[string]any{
+			"Code":          code,
+			"ExpireMinutes": config.GetInt("verifycode.expire_time"),
+		},
+	}.Render(&message)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	// Queue the email, `gohub queue:work` sends it
+	return mail.NewMailer().Queue(context.Background(), message)
 }
 
-// CheckAnswer Check whether the verification code submitted by the user is correct
-func (vc *VerifyCode) CheckAnswer(key, answer string) bool {
+// CheckAnswer Check whether the verification code submitted by the user is correct,
+// after verifycode.max_attempts wrong answers the code is invalidated and a new one has to be sent.
+// attemptsLeft is the number of answers the code still accepts.
+func (vc *VerifyCode) CheckAnswer(key, answer string) (ok bool, attemptsLeft int) {
 	logger.DebugJSON("Verify Code", "Check verify code", map[string]string{key: answer})
 
+	maxAttempts := config.GetInt("verifycode.max_attempts")
+
 	if !app.IsProduction() &&
 		(strings.HasSuffix(key, config.GetString("verifycode.debug_email_suffix")) ||
 			strings.HasPrefix(key, config.GetString("verifycode.debug_phone_prefix"))) {
-		return true
+		return true, maxAttempts
 	}
 
-	return vc.Store.Verify(key, answer, false)
+	code := vc.Store.Get(key, false)
+	if code == "" {
+		// Never sent, expired or invalidated
+		return false, 0
+	}
+	if code == answer {
+		return true, maxAttempts
+	}
+
+	failures := vc.Store.Fail(key)
+	if failures >= maxAttempts {
+		vc.Store.Get(key, true)
+		return false, 0
+	}
+	return false, maxAttempts - failures
 }
 
 // generateVerifyCode Generate verify code, and store in Redis
@@ -0,0 +1,92 @@
+// Package verifyemail Signed links that prove the ownership of an email address,
+// an alternative to the codes of verifycode
+package verifyemail
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"gohub/app/models/user"
+	"gohub/pkg/app"
+	"gohub/pkg/config"
+	"gohub/pkg/hash"
+	"gohub/pkg/mail"
+	"gohub/pkg/signedurl"
+)
+
+// Path The route the links open under the API prefix, see routes/api.go
+const Path = "/auth/email/verify"
+
+// VerifyURL The link that marks the current email of the user verified
+func VerifyURL(userModel user.User) (string, error) {
+	return link(url.Values{
+		"user":  {userModel.GetStringID()},
+		"email": {userModel.Email},
+	})
+}
+
+// ChangeURL The link that changes the email of the user to email, it stops working
+// once the email of the user is not the one the change was asked for
+func ChangeURL(userModel user.User, email string) (string, error) {
+	return link(url.Values{
+		"user":  {userModel.GetStringID()},
+		"email": {email},
+		"from":  {hash.SHA256(userModel.Email)},
+	})
+}
+
+// IsChangeOf Whether the change of a ChangeURL still applies to the user
+func IsChangeOf(userModel user.User, from string) bool {
+	return hash.SHA256Check(userModel.Email, from)
+}
+
+// SendVerify Queue the email with the VerifyURL, locale picks the template variant
+func SendVerify(ctx context.Context, userModel user.User, locale string) error {
+	link, err := VerifyURL(userModel)
+	if err != nil {
+		return err
+	}
+	return send(ctx, "verify_email", userModel, userModel.Email, link, locale)
+}
+
+// SendChange Queue the email with the ChangeURL to the new address
+func SendChange(ctx context.Context, userModel user.User, email, locale string) error {
+	link, err := ChangeURL(userModel, email)
+	if err != nil {
+		return err
+	}
+	return send(ctx, "change_email", userModel, email, link, locale)
+}
+
+func link(query url.Values) (string, error) {
+	expireAt := time.Now().Add(time.Minute * time.Duration(config.GetInt("verifycode.link_expire")))
+	// The path of the API prefix is signed, so the link must be built with the prefix the route has
+	return signedurl.Sign(app.APIURL(Path)+"?"+query.Encode(), expireAt)
+}
+
+func send(ctx context.Context, template string, userModel user.User, email, link, locale string) error {
+	message := mail.Email{
+		From: mail.From{
+			Address: config.GetString("mail.from.address"),
+			Name:    config.GetString("mail.from.name"),
+		},
+		To: []string{email},
+	}
+	err := mail.Template{
+		Name:   template,
+		Locale: locale,
+		Data: map[string]any{
+			"Name":          userModel.Name,
+			"Email":         email,
+			"URL":           link,
+			"ExpireMinutes": config.GetInt("verifycode.link_expire"),
+		},
+	}.Render(&message)
+	if err != nil {
+		return err
+	}
+
+	// Queue the email, `gohub queue:work` sends it
+	return mail.NewMailer().Queue(ctx, message)
+}
@@ -0,0 +1,36 @@
+package bootstrap
+
+import (
+	limiterLib "github.com/ulule/limiter/v3"
+	memoryStore "github.com/ulule/limiter/v3/drivers/store/memory"
+	sredis "github.com/ulule/limiter/v3/drivers/store/redis"
+	"gohub/app/limiter"
+	"gohub/pkg/app"
+	"gohub/pkg/config"
+	"gohub/pkg/logger"
+	"gohub/pkg/redis"
+)
+
+// SetupLimiter Set up the stores of the limiter, on the main Redis DB,
+// in memory when testing or when limit.store is memory
+func SetupLimiter() {
+	for name, prefix := range map[string]string{
+		limiter.RateStore:    ":limiter",
+		limiter.LockoutStore: ":lockout",
+	} {
+		if app.IsTesting() || config.GetString("limit.store") == "memory" {
+			limiter.SetStore(name, memoryStore.NewStore())
+			continue
+		}
+
+		store, err := sredis.NewStoreWithOptions(redis.Redis.Client, limiterLib.StoreOptions{
+			// Set a prefix for limiter to keep redis data tidy
+			Prefix: config.GetString("app.name") + prefix,
+		})
+		if err != nil {
+			logger.LogIf(err)
+			continue
+		}
+		limiter.SetStore(name, store)
+	}
+}
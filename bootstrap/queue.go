@@ -0,0 +1,18 @@
+package bootstrap
+
+import (
+	"gohub/pkg/app"
+	"gohub/pkg/config"
+	"gohub/pkg/queue"
+	"gohub/pkg/redis"
+)
+
+// SetupQueue Set up the job queue, on the main Redis DB
+func SetupQueue() {
+	if app.IsTesting() || config.GetString("queue.driver") == "memory" {
+		queue.InitWithDriver(queue.NewMemoryDriver())
+		return
+	}
+
+	queue.InitWithDriver(queue.NewRedisDriver(redis.Redis, config.GetString("app.name")+":queues:"))
+}
@@ -10,6 +10,8 @@ func init() {
 			"max_idle_connections": config.Env("DB_MAX_IDLE_CONNECTIONS", 100),
 			"max_open_connections": config.Env("DB_MAX_OPEN_CONNECTIONS", 25),
 			"max_life_seconds":     config.Env("DB_MAX_LIFE_SECONDS", 5*60),
+			// Seconds a migrator waits for another one to finish before giving up
+			"migration_lock_timeout": config.Env("DB_MIGRATION_LOCK_TIMEOUT", 60),
 			"mysql": map[string]any{
 				"host":     config.Env("DB_HOST", "127.0.0.1"),
 				"port":     config.Env("DB_PORT", "3306"),
@@ -10,8 +10,15 @@ func init() {
 
 			// Expiration time, in minutes, generally no more than two hours
 			"expire_time": config.Env("JWT_EXPIRE_TIME", 120),
-			// Allowed refresh time, the unit is minutes, 86400 is two months, counted from the token signature time
+			// Allowed refresh time, the unit is minutes, 86400 is two months, counted from the login time.
+			// After that the user has to log in again, no matter how often the token was refreshed
 			"max_refresh_time": config.Env("JWT_MAX_REFRESH_TIME", 86400),
+			// Expiration time of a refresh token, in minutes, every refresh issues a new one
+			"refresh_expire_time": config.Env("JWT_REFRESH_EXPIRE_TIME", 20160),
+			// Seconds the result of the session check of AuthJWT is cached, revoking a session forgets it
+			"session_cache_ttl": config.Env("JWT_SESSION_CACHE_TTL", 60),
+			// Seconds between the updates of the last seen time of a session
+			"session_touch_interval": config.Env("JWT_SESSION_TOUCH_INTERVAL", 60),
 			// The expiration time in debug mode is convenient for local debugging and development
 			"debug_expire_time": 86400,
 		}
@@ -0,0 +1,36 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("limit", func() map[string]any {
+		return map[string]any{
+			// redis, or memory which only counts the requests of this process
+			"store": config.Env("LIMIT_STORE", "redis"),
+
+			// fixed counts from the first request of a window, which allows bursts of twice the limit
+			// around the end of a window; sliding also weighs the previous window in
+			"algorithm": config.Env("LIMIT_ALGORITHM", "fixed"),
+
+			// Named policies used by the limit middlewares in routes/api.go,
+			// "<requests>-<period>" where the period is S, M, H or D, e.g. "200-H"
+			"policies": map[string]any{
+				// All API requests of an IP
+				"global": config.Env("LIMIT_GLOBAL", "200-H"),
+				// The /auth routes of an IP
+				"auth": config.Env("LIMIT_AUTH", "1000-H"),
+				// Requests of a logged in user, wherever they come from
+				"user": config.Env("LIMIT_USER", "1000-H"),
+				// Each verification code route of an IP
+				"verify_code": config.Env("LIMIT_VERIFY_CODE", "20-H"),
+				// Each login route of an IP
+				"login": config.Env("LIMIT_LOGIN", "60-H"),
+			},
+
+			// Per-route overrides, "<method> <path>" as registered to a policy name or a rate,
+			// the LimitPerRoute middleware of the route uses it instead of its policy, e.g.
+			// "POST /api/v1/auth/login/using-password": "10-H"
+			"routes": map[string]any{},
+		}
+	})
+}
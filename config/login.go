@@ -0,0 +1,19 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("login", func() map[string]any {
+		return map[string]any{
+			// Wrong passwords for an account before it is locked, for the IPs that keep failing
+			"max_attempts": config.Env("LOGIN_MAX_ATTEMPTS", 5),
+
+			// Seconds of the first lockout, doubled for each further wrong password up to max_lockout
+			"lockout":     config.Env("LOGIN_LOCKOUT", 60),
+			"max_lockout": config.Env("LOGIN_MAX_LOCKOUT", 3600),
+
+			// Minutes after the first wrong password when the count starts over
+			"decay": config.Env("LOGIN_DECAY", 1440),
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("notification", func() map[string]any {
+		return map[string]any{
+			// Also send notifications by email, see config/mail.go
+			"mail": config.Env("NOTIFICATION_MAIL", false),
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("oauth", func() map[string]any {
+		return map[string]any{
+			// Minutes the state of a redirect is valid, the user has to sign in at the provider meanwhile
+			"state_expire": config.Env("OAUTH_STATE_EXPIRE", 10),
+
+			// A provider is enabled when its client_id is set.
+			// The callback URL to register at the provider is redirect_url,
+			// <APP_URL>/api/v1/auth/oauth/<provider>/callback when it is empty,
+			// or <API_DOMAIN>/v1/auth/oauth/<provider>/callback with an API domain
+			"github": map[string]any{
+				"client_id":     config.Env("OAUTH_GITHUB_CLIENT_ID", ""),
+				"client_secret": config.Env("OAUTH_GITHUB_CLIENT_SECRET", ""),
+				"redirect_url":  config.Env("OAUTH_GITHUB_REDIRECT_URL", ""),
+				"scopes":        config.Env("OAUTH_GITHUB_SCOPES", "read:user user:email"),
+				"auth_url":      config.Env("OAUTH_GITHUB_AUTH_URL", "https://github.com/login/oauth/authorize"),
+				"token_url":     config.Env("OAUTH_GITHUB_TOKEN_URL", "https://github.com/login/oauth/access_token"),
+				"user_url":      config.Env("OAUTH_GITHUB_USER_URL", "https://api.github.com/user"),
+				"emails_url":    config.Env("OAUTH_GITHUB_EMAILS_URL", "https://api.github.com/user/emails"),
+			},
+
+			"google": map[string]any{
+				"client_id":     config.Env("OAUTH_GOOGLE_CLIENT_ID", ""),
+				"client_secret": config.Env("OAUTH_GOOGLE_CLIENT_SECRET", ""),
+				"redirect_url":  config.Env("OAUTH_GOOGLE_REDIRECT_URL", ""),
+				"scopes":        config.Env("OAUTH_GOOGLE_SCOPES", "openid email profile"),
+				"auth_url":      config.Env("OAUTH_GOOGLE_AUTH_URL", "https://accounts.google.com/o/oauth2/v2/auth"),
+				"token_url":     config.Env("OAUTH_GOOGLE_TOKEN_URL", "https://oauth2.googleapis.com/token"),
+				"user_url":      config.Env("OAUTH_GOOGLE_USER_URL", "https://openidconnect.googleapis.com/v1/userinfo"),
+			},
+		}
+	})
+}
@@ -23,6 +23,12 @@ func init() {
 			// The parameters in the URL to distinguish sorting rules (forward or reverse order)
 			// If this value is changed, the request validation rule must be changed as well
 			"url_query_order": "order",
+
+			// The parameter in the URL carrying the cursor of keyset pagination
+			"url_query_cursor": "cursor",
+
+			// The parameter in the URL asking keyset pagination to count the total as well
+			"url_query_with_total": "with_total",
 		}
 	})
 }
@@ -0,0 +1,13 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("prune", func() map[string]any {
+		return map[string]any{
+			// Deleted topics, users and categories stay in the trash this many days,
+			// after which `gohub prune` removes them permanently
+			"retention_days": config.Env("PRUNE_RETENTION_DAYS", 30),
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("queue", func() map[string]any {
+		return map[string]any{
+			// redis, or memory which only lives as long as the process, for tests
+			"driver": config.Env("QUEUE_DRIVER", "redis"),
+
+			// Queue used by queue.Dispatch and worked by `gohub queue:work`
+			"default": config.Env("QUEUE_DEFAULT", "default"),
+
+			// Attempts per job before it is moved to the failed_jobs table
+			"tries": config.Env("QUEUE_TRIES", 5),
+
+			// Seconds to wait before retrying, doubled for each further attempt up to max_backoff
+			"backoff":     config.Env("QUEUE_BACKOFF", 10),
+			"max_backoff": config.Env("QUEUE_MAX_BACKOFF", 3600),
+		}
+	})
+}
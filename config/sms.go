@@ -0,0 +1,47 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("sms", func() map[string]any {
+		return map[string]any{
+			// aliyun, tencent, twilio, log or noop
+			// log writes messages to the log instead of sending them and refuses to in production,
+			// without a configured driver sending fails
+			"driver": config.Env("SMS_DRIVER", "noop"),
+
+			// Attempts after a failed one, waiting retry_delay milliseconds and doubling it each time.
+			// Only network errors, throttling and server errors are retried.
+			"retries":     config.Env("SMS_RETRIES", 2),
+			"retry_delay": config.Env("SMS_RETRY_DELAY", 500),
+
+			// template_<name> maps the Message.Template names to the template IDs of the provider
+			"aliyun": map[string]any{
+				"access_key_id":        config.Env("SMS_ALIYUN_ACCESS_KEY_ID", ""),
+				"access_key_secret":    config.Env("SMS_ALIYUN_ACCESS_KEY_SECRET", ""),
+				"sign_name":            config.Env("SMS_ALIYUN_SIGN_NAME", ""),
+				"region":               config.Env("SMS_ALIYUN_REGION", "cn-hangzhou"),
+				"endpoint":             config.Env("SMS_ALIYUN_ENDPOINT", "https://dysmsapi.aliyuncs.com"),
+				"template_verify_code": config.Env("SMS_ALIYUN_TEMPLATE_VERIFY_CODE", ""),
+			},
+
+			"tencent": map[string]any{
+				"secret_id":            config.Env("SMS_TENCENT_SECRET_ID", ""),
+				"secret_key":           config.Env("SMS_TENCENT_SECRET_KEY", ""),
+				"sdk_app_id":           config.Env("SMS_TENCENT_SDK_APP_ID", ""),
+				"sign_name":            config.Env("SMS_TENCENT_SIGN_NAME", ""),
+				"region":               config.Env("SMS_TENCENT_REGION", "ap-guangzhou"),
+				"endpoint":             config.Env("SMS_TENCENT_ENDPOINT", "https://sms.tencentcloudapi.com"),
+				"template_verify_code": config.Env("SMS_TENCENT_TEMPLATE_VERIFY_CODE", ""),
+			},
+
+			// Any provider with a Twilio-compatible Messages API
+			"twilio": map[string]any{
+				"account_sid": config.Env("SMS_TWILIO_ACCOUNT_SID", ""),
+				"auth_token":  config.Env("SMS_TWILIO_AUTH_TOKEN", ""),
+				"from":        config.Env("SMS_TWILIO_FROM", ""),
+				"endpoint":    config.Env("SMS_TWILIO_ENDPOINT", "https://api.twilio.com"),
+			},
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("two_factor", func() map[string]any {
+		return map[string]any{
+			// Shown by authenticator apps next to the account, app.name when empty
+			"issuer": config.Env("TWO_FACTOR_ISSUER", ""),
+
+			// Periods of 30 seconds a code may be off, for clocks that are not in sync
+			"skew": config.Env("TWO_FACTOR_SKEW", 1),
+
+			// Minutes the challenge token of a password login is valid to enter the code
+			"challenge_expire": config.Env("TWO_FACTOR_CHALLENGE_EXPIRE", 5),
+
+			// Recovery codes given when 2FA is enabled, each can be used once instead of a code
+			"recovery_codes": config.Env("TWO_FACTOR_RECOVERY_CODES", 8),
+		}
+	})
+}
@@ -11,6 +11,12 @@ func init() {
 			// Expiration time, in minutes
 			"expire_time": config.Env("VERIFY_CODE_EXPIRE", 15),
 
+			// Minutes the signed links of verification emails are valid
+			"link_expire": config.Env("VERIFY_LINK_EXPIRE", 60),
+
+			// Wrong answers a code accepts before it is invalidated
+			"max_attempts": config.Env("VERIFY_CODE_MAX_ATTEMPTS", 5),
+
 			// Expiration time, in minutes
 			"debug_expire_time": 10080,
 			// The local development environment verification code uses debug_code
@@ -0,0 +1,19 @@
+package config
+
+import "gohub/pkg/config"
+
+func init() {
+	config.Add("vote", func() map[string]any {
+		return map[string]any{
+			// How often `gohub serve` writes the cached vote counters into topics.vote_count
+			// and refreshes the hot scores, in minutes, 0 disables it
+			"reconcile_interval": config.Env("VOTE_RECONCILE_INTERVAL", 5),
+
+			// Topics created within this many days are ranked by sort=hot
+			"hot_window_days": config.Env("VOTE_HOT_WINDOW_DAYS", 7),
+
+			// The higher the gravity, the faster older topics sink in sort=hot
+			"hot_gravity": config.Env("VOTE_HOT_GRAVITY", 1.8),
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package factories
+
+import (
+	"github.com/go-faker/faker/v4"
+	"gohub/app/models/reply"
+)
+
+func MakeReplies(count int) []reply.Reply {
+	var objs []reply.Reply
+
+	for range count {
+		replyModel := reply.Reply{
+			Body:    faker.Paragraph(),
+			TopicID: "1",
+			UserID:  "1",
+		}
+		objs = append(objs, replyModel)
+	}
+
+	return objs
+}
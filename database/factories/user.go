@@ -2,6 +2,8 @@
 package factories
 
 import (
+	"time"
+
 	"github.com/go-faker/faker/v4"
 	"gohub/app/models/user"
 	"gohub/pkg/helpers"
@@ -13,12 +15,15 @@ func MakeUsers(times int) []user.User {
 	// Set unique value
 	faker.SetGenerateUniqueValues(true)
 
+	verifiedAt := time.Now()
 	for range times {
 		model := user.User{
 			Name:     faker.Username(),
 			Email:    faker.Email(),
 			Phone:    helpers.RandomNumber(11),
 			Password: "$2a$14$oPzVkIdwJ8KqY0erYAYQxOuAAlbI/sFIsH0C0R4MPc.3JbWWSuaUe",
+
+			EmailVerifiedAt: &verifiedAt,
 		}
 		objs = append(objs, model)
 	}
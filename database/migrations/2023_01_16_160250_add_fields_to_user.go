@@ -15,14 +15,17 @@ func init() {
 		Avatar       string `gorm:"type:varchar(255);default:null"`
 	}
 
-	up := func(migrator gorm.Migrator, DB *sql.DB) {
-		_ = migrator.AutoMigrate(&User{})
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&User{})
 	}
 
-	down := func(migrator gorm.Migrator, DB *sql.DB) {
-		_ = migrator.DropColumn(&User{}, "City")
-		_ = migrator.DropColumn(&User{}, "Introduction")
-		_ = migrator.DropColumn(&User{}, "Avatar")
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		for _, column := range []string{"City", "Introduction", "Avatar"} {
+			if err := migrator.DropColumn(&User{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
 	migrate.Add("2023_01_16_160250_add_fields_to_user", up, down)
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"gohub/app/models"
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type User struct {
+		models.BaseModel
+	}
+
+	type Topic struct {
+		models.BaseModel
+	}
+
+	type Reply struct {
+		models.BaseModel
+
+		Body     string `gorm:"type:text;not null"`
+		TopicID  string `gorm:"type:bigint;not null;index"`
+		UserID   string `gorm:"type:bigint;not null;index"`
+		ParentID uint64 `gorm:"type:bigint;not null;default:0;index"`
+
+		User  User
+		Topic Topic
+
+		models.CommonTimestampsField
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&Reply{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.DropTable(&Reply{})
+	}
+
+	migrate.Add("2026_10_18_093015_add_replies_table", up, down)
+}
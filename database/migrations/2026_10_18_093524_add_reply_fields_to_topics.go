@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"database/sql"
+	"time"
+
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type Topic struct {
+		ReplyCount  int64      `gorm:"type:bigint;not null;default:0;index"`
+		LastReplyAt *time.Time `gorm:"index;default:null"`
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&Topic{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		for _, column := range []string{"ReplyCount", "LastReplyAt"} {
+			if err := migrator.DropColumn(&Topic{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	migrate.Add("2026_10_18_093524_add_reply_fields_to_topics", up, down)
+}
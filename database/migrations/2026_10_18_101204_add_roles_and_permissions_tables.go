@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"gohub/app/models"
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type Permission struct {
+		models.BaseModel
+
+		Name        string `gorm:"type:varchar(100);not null;uniqueIndex"`
+		Description string `gorm:"type:varchar(255);default:null"`
+
+		models.CommonTimestampsField
+	}
+
+	type Role struct {
+		models.BaseModel
+
+		Name        string `gorm:"type:varchar(100);not null;uniqueIndex"`
+		Description string `gorm:"type:varchar(255);default:null"`
+
+		models.CommonTimestampsField
+	}
+
+	type RolePermission struct {
+		RoleID       uint64 `gorm:"type:bigint;primaryKey;autoIncrement:false"`
+		PermissionID uint64 `gorm:"type:bigint;primaryKey;autoIncrement:false;index"`
+	}
+
+	type UserRole struct {
+		UserID uint64 `gorm:"type:bigint;primaryKey;autoIncrement:false"`
+		RoleID uint64 `gorm:"type:bigint;primaryKey;autoIncrement:false;index"`
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&Permission{}, &Role{}, &RolePermission{}, &UserRole{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.DropTable(&UserRole{}, &RolePermission{}, &Role{}, &Permission{})
+	}
+
+	migrate.Add("2026_10_18_101204_add_roles_and_permissions_tables", up, down)
+}
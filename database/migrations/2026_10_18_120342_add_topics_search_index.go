@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"gohub/pkg/config"
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		var statements []string
+		switch config.Get("database.connection") {
+		case "postgresql":
+			statements = []string{
+				`ALTER TABLE topics ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+				`UPDATE topics SET search_vector =
+				setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('simple', coalesce(body, '')), 'B')`,
+				`CREATE INDEX IF NOT EXISTS idx_topics_search_vector ON topics USING GIN (search_vector)`,
+			}
+		case "mysql":
+			statements = []string{`ALTER TABLE topics ADD FULLTEXT INDEX idx_topics_fulltext (title, body)`}
+		case "sqlite":
+			// FTS5 is only compiled in with the sqlite_fts5 build tag, FTS4 is always available
+			module := "fts4"
+			var fts5 bool
+			if err := DB.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5); err == nil && fts5 {
+				module = "fts5"
+			}
+			statements = []string{
+				`CREATE VIRTUAL TABLE IF NOT EXISTS topics_fts USING ` + module + `(title, body)`,
+				`INSERT INTO topics_fts (rowid, title, body) SELECT id, title, body FROM topics`,
+			}
+		}
+		return execAll(DB, statements)
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		var statements []string
+		switch config.Get("database.connection") {
+		case "postgresql":
+			statements = []string{
+				`DROP INDEX IF EXISTS idx_topics_search_vector`,
+				`ALTER TABLE topics DROP COLUMN IF EXISTS search_vector`,
+			}
+		case "mysql":
+			statements = []string{`ALTER TABLE topics DROP INDEX idx_topics_fulltext`}
+		case "sqlite":
+			statements = []string{`DROP TABLE IF EXISTS topics_fts`}
+		}
+		return execAll(DB, statements)
+	}
+
+	migrate.Add("2026_10_18_120342_add_topics_search_index", up, down)
+}
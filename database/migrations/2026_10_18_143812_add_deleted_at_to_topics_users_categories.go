@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type Topic struct {
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	type User struct {
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	type Category struct {
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&Topic{}, &User{}, &Category{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		for _, model := range []any{&Topic{}, &User{}, &Category{}} {
+			if err := migrator.DropColumn(model, "DeletedAt"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	migrate.Add("2026_10_18_143812_add_deleted_at_to_topics_users_categories", up, down)
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"gohub/app/models"
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type User struct {
+		models.BaseModel
+	}
+
+	type Topic struct {
+		models.BaseModel
+
+		VoteCount int64   `gorm:"type:bigint;not null;default:0;index"`
+		HotScore  float64 `gorm:"not null;default:0;index"`
+	}
+
+	type TopicVote struct {
+		models.BaseModel
+
+		UserID  string `gorm:"type:bigint;not null;uniqueIndex:idx_topic_votes_user_topic"`
+		TopicID string `gorm:"type:bigint;not null;uniqueIndex:idx_topic_votes_user_topic;index"`
+
+		User  User
+		Topic Topic
+
+		models.CommonTimestampsField
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&Topic{}, &TopicVote{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		if err := migrator.DropTable(&TopicVote{}); err != nil {
+			return err
+		}
+		for _, column := range []string{"VoteCount", "HotScore"} {
+			if err := migrator.DropColumn(&Topic{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	migrate.Add("2026_10_18_161427_add_topic_votes_table", up, down)
+}
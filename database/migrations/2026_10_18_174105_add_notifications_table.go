@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"database/sql"
+	"time"
+
+	"gohub/app/models"
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type Notification struct {
+		models.BaseModel
+
+		UserID  string     `gorm:"type:bigint;not null;index:idx_notifications_user_read"`
+		ActorID string     `gorm:"type:bigint;not null;index"`
+		Type    string     `gorm:"type:varchar(20);not null"`
+		TopicID string     `gorm:"type:bigint;not null;index"`
+		Title   string     `gorm:"type:varchar(255);not null"`
+		ReplyID uint64     `gorm:"type:bigint;not null;default:0"`
+		ReadAt  *time.Time `gorm:"index:idx_notifications_user_read;default:null"`
+
+		models.CommonTimestampsField
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&Notification{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.DropTable(&Notification{})
+	}
+
+	migrate.Add("2026_10_18_174105_add_notifications_table", up, down)
+}
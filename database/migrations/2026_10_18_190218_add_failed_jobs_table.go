@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"database/sql"
+	"time"
+
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type FailedJob struct {
+		ID       uint64    `gorm:"primaryKey;autoIncrement;"`
+		JobID    string    `gorm:"type:varchar(64);not null;index"`
+		Queue    string    `gorm:"type:varchar(64);not null"`
+		Type     string    `gorm:"type:varchar(64);not null"`
+		Payload  string    `gorm:"type:text;not null"`
+		Attempts int       `gorm:"not null;default:0"`
+		Error    string    `gorm:"type:text;not null"`
+		FailedAt time.Time `gorm:"not null;index"`
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&FailedJob{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.DropTable(&FailedJob{})
+	}
+
+	migrate.Add("2026_10_18_190218_add_failed_jobs_table", up, down)
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"gohub/app/models"
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type User struct {
+		models.BaseModel
+	}
+
+	type SocialAccount struct {
+		models.BaseModel
+
+		UserID         string `gorm:"type:bigint;not null;uniqueIndex:idx_social_accounts_user_provider"`
+		Provider       string `gorm:"type:varchar(20);not null;uniqueIndex:idx_social_accounts_provider_user;uniqueIndex:idx_social_accounts_user_provider"`
+		ProviderUserID string `gorm:"type:varchar(255);not null;uniqueIndex:idx_social_accounts_provider_user"`
+		Name           string `gorm:"type:varchar(255);not null;default:''"`
+		Email          string `gorm:"type:varchar(255);not null;default:''"`
+		Avatar         string `gorm:"type:varchar(255);not null;default:''"`
+
+		User User
+
+		models.CommonTimestampsField
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&SocialAccount{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.DropTable(&SocialAccount{})
+	}
+
+	migrate.Add("2026_10_18_203417_add_social_accounts_table", up, down)
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"database/sql"
+	"time"
+
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type User struct {
+		TwoFactorSecret        string     `gorm:"type:varchar(64);not null;default:''"`
+		TwoFactorRecoveryCodes string     `gorm:"type:text"`
+		TwoFactorConfirmedAt   *time.Time `gorm:"default:null"`
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&User{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		for _, column := range []string{"TwoFactorSecret", "TwoFactorRecoveryCodes", "TwoFactorConfirmedAt"} {
+			if err := migrator.DropColumn(&User{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	migrate.Add("2026_10_18_212905_add_two_factor_columns_to_users", up, down)
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"database/sql"
+	"time"
+
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type User struct {
+		EmailVerifiedAt *time.Time `gorm:"default:null"`
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		if err := migrator.AutoMigrate(&User{}); err != nil {
+			return err
+		}
+		// Emails were only saved after a code check or from a provider that verified them
+		_, err := DB.Exec(`UPDATE users SET email_verified_at = created_at WHERE email IS NOT NULL AND email <> ''`)
+		return err
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.DropColumn(&User{}, "EmailVerifiedAt")
+	}
+
+	migrate.Add("2026_10_18_231540_add_email_verified_at_to_users", up, down)
+}
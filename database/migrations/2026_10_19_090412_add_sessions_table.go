@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"database/sql"
+	"time"
+
+	"gohub/app/models"
+	"gohub/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	type User struct {
+		models.BaseModel
+	}
+
+	type Session struct {
+		models.BaseModel
+
+		UserID     string     `gorm:"type:bigint;not null;index"`
+		FamilyID   string     `gorm:"type:varchar(64);not null;uniqueIndex"`
+		Device     string     `gorm:"type:varchar(64);not null;default:''"`
+		IP         string     `gorm:"type:varchar(45);not null;default:''"`
+		UserAgent  string     `gorm:"type:varchar(255);not null;default:''"`
+		LastSeenAt time.Time  `gorm:"not null"`
+		ExpiresAt  time.Time  `gorm:"not null;index"`
+		RevokedAt  *time.Time `gorm:"default:null;index"`
+
+		User User
+
+		models.CommonTimestampsField
+	}
+
+	up := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.AutoMigrate(&Session{})
+	}
+
+	down := func(migrator gorm.Migrator, DB *sql.DB) error {
+		return migrator.DropTable(&Session{})
+	}
+
+	migrate.Add("2026_10_19_090412_add_sessions_table", up, down)
+}
@@ -1,6 +1,18 @@
 // Package migrations Store all database migration files
 package migrations
 
+import "database/sql"
+
 func Initialize() {
 	// The init method that triggers the loading of other files in this directory
 }
+
+// execAll Run the raw statements in order, stopping at the first failing one
+func execAll(DB *sql.DB, statements []string) error {
+	for _, statement := range statements {
+		if _, err := DB.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
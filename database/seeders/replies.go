@@ -0,0 +1,33 @@
+package seeders
+
+import (
+	"fmt"
+
+	"gohub/database/factories"
+	"gohub/pkg/console"
+	"gohub/pkg/logger"
+	"gohub/pkg/seed"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	seed.Add("SeedRepliesTable", func(db *gorm.DB) {
+		replies := factories.MakeReplies(10)
+
+		result := db.Table("replies").Create(&replies)
+
+		if err := result.Error; err != nil {
+			logger.LogIf(err)
+			return
+		}
+
+		console.Success(
+			fmt.Sprintf(
+				"Table [%v] %v rows seeded",
+				result.Statement.Table,
+				result.RowsAffected,
+			),
+		)
+	})
+}
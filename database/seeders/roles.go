@@ -0,0 +1,67 @@
+package seeders
+
+import (
+	"fmt"
+
+	"gohub/app/models/permission"
+	"gohub/app/models/role"
+	"gohub/pkg/console"
+	"gohub/pkg/logger"
+	"gohub/pkg/seed"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	seed.Add("SeedRolesTable", func(db *gorm.DB) {
+		permissions := map[string]*permission.Permission{
+			permission.CategoriesManage: {Name: permission.CategoriesManage, Description: "Create, update and delete categories"},
+			permission.LinksManage:      {Name: permission.LinksManage, Description: "Create, update and delete links"},
+			permission.TopicsModerate:   {Name: permission.TopicsModerate, Description: "Update and delete topics and replies of any user"},
+		}
+		for _, permissionModel := range permissions {
+			if err := db.Where("name = ?", permissionModel.Name).FirstOrCreate(permissionModel).Error; err != nil {
+				logger.LogIf(err)
+				return
+			}
+		}
+
+		// Permissions of the built-in roles
+		roles := map[string][]string{
+			role.Admin:     {permission.CategoriesManage, permission.LinksManage, permission.TopicsModerate},
+			role.Moderator: {permission.TopicsModerate},
+		}
+		for name, permissionNames := range roles {
+			roleModel := role.Role{Name: name}
+			if err := db.Where("name = ?", name).FirstOrCreate(&roleModel).Error; err != nil {
+				logger.LogIf(err)
+				return
+			}
+
+			var rolePermissions []permission.Permission
+			for _, permissionName := range permissionNames {
+				rolePermissions = append(rolePermissions, *permissions[permissionName])
+			}
+			if err := db.Model(&roleModel).Association("Permissions").Replace(rolePermissions); err != nil {
+				logger.LogIf(err)
+				return
+			}
+		}
+
+		// The first user is the administrator of a freshly seeded site
+		adminRole := role.Role{}
+		db.Where("name = ?", role.Admin).First(&adminRole)
+		if err := db.Where(role.UserRole{UserID: 1, RoleID: adminRole.ID}).FirstOrCreate(&role.UserRole{}).Error; err != nil {
+			logger.LogIf(err)
+			return
+		}
+
+		console.Success(
+			fmt.Sprintf(
+				"Table [%v] %v rows seeded",
+				"roles",
+				len(roles),
+			),
+		)
+	})
+}
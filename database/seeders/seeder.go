@@ -6,5 +6,9 @@ import "gohub/pkg/seed"
 func Initialize() {
 	seed.SetRunOrder([]string{
 		"SeedUsersTable",
+		"SeedRolesTable",
+		"SeedCategoriesTable",
+		"SeedTopicsTable",
+		"SeedRepliesTable",
 	})
 }
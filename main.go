@@ -40,6 +40,12 @@ func main() {
 
 			// Initialize the cache
 			bootstrap.SetupCache()
+
+			// Initialize the job queue
+			bootstrap.SetupQueue()
+
+			// Initialize the limiter stores
+			bootstrap.SetupLimiter()
 		},
 	}
 
@@ -52,6 +58,12 @@ func main() {
 		cmd.Migrate,
 		cmd.DBSeed,
 		cmd.Cache,
+		cmd.Role,
+		cmd.Prune,
+		cmd.Vote,
+		cmd.QueueWork,
+		cmd.QueueFailed,
+		cmd.QueueRetry,
 	)
 
 	// Configure the web service to run by default
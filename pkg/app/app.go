@@ -2,6 +2,8 @@
 package app
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
 	"gohub/pkg/config"
@@ -19,10 +21,18 @@ func IsTesting() bool {
 	return config.Get("app.env") == "testing"
 }
 
+// Timezone The location configured by app.timezone
+func Timezone() *time.Location {
+	location, err := time.LoadLocation(config.GetString("app.timezone"))
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
 // TimenowInTimezone Signature effective time
 func TimenowInTimezone() time.Time {
-	chinaTimezone, _ := time.LoadLocation(config.GetString("app.timezone"))
-	return time.Now().In(chinaTimezone)
+	return time.Now().In(Timezone())
 }
 
 // URL Pass the path parameter to splice the URL of the site
@@ -34,3 +44,25 @@ func URL(path string) string {
 func V1URL(path string) string {
 	return URL("/v1/" + path)
 }
+
+// APIPrefix The path the v1 API routes are mounted at, /v1 on app.api_domain, /api/v1 on the site otherwise
+func APIPrefix() string {
+	if len(config.Get("app.api_domain")) == 0 {
+		return "/api/v1"
+	}
+	return "/v1"
+}
+
+// APIURL The URL of path under the v1 API routes, such as "/auth/email/verify",
+// on app.api_domain with the scheme of app.url when it is set
+func APIURL(path string) string {
+	base := strings.TrimRight(config.Get("app.url"), "/")
+	if domain := strings.TrimRight(config.Get("app.api_domain"), "/"); domain != "" {
+		scheme := "https"
+		if site, err := url.Parse(base); err == nil && site.Scheme != "" {
+			scheme = site.Scheme
+		}
+		base = scheme + "://" + domain
+	}
+	return base + APIPrefix() + path
+}
@@ -46,4 +46,10 @@ func TestURLs(t *testing.T) {
 
 	require.Equal(t, "http://localhost:3000/health", URL("/health"))
 	require.Equal(t, "http://localhost:3000/v1/topics", V1URL("topics"))
+	require.Equal(t, "http://localhost:3000/api/v1/auth/email/verify", APIURL("/auth/email/verify"))
+
+	pkgconfig.Set("app.api_domain", "api.example.com")
+	t.Cleanup(func() { pkgconfig.Set("app.api_domain", "") })
+	require.Equal(t, "/v1", APIPrefix())
+	require.Equal(t, "http://api.example.com/v1/auth/email/verify", APIURL("/auth/email/verify"))
 }
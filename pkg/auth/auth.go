@@ -4,8 +4,10 @@ package auth
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/gin-gonic/gin"
+	"gohub/app/models/permission"
 	"gohub/app/models/user"
 	"gohub/pkg/logger"
 )
@@ -48,3 +50,24 @@ func CurrentUser(c *gin.Context) user.User {
 func CurrentUID(c *gin.Context) string {
 	return c.GetString("current_user_id")
 }
+
+// CurrentPermissions Permissions of the current login user, loaded once per request
+func CurrentPermissions(c *gin.Context) []string {
+	if permissions, ok := c.Get("current_user_permissions"); ok {
+		return permissions.([]string)
+	}
+
+	uid := CurrentUID(c)
+	if uid == "" {
+		return nil
+	}
+
+	permissions := permission.NamesOfUser(c.Request.Context(), uid)
+	c.Set("current_user_permissions", permissions)
+	return permissions
+}
+
+// Can Whether the current login user has the permission
+func Can(c *gin.Context, permissionName string) bool {
+	return slices.Contains(CurrentPermissions(c), permissionName)
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gohub/app/models/session"
+	"gohub/app/models/user"
+	"gohub/pkg/cache"
+	"gohub/pkg/config"
+	"gohub/pkg/jwt"
+	"gohub/pkg/logger"
+)
+
+// ErrIssueToken The token pair or the session of a login could not be created
+var ErrIssueToken = errors.New("failed to issue the token")
+
+// IssueToken Issue a token pair for the user and record the session of the login,
+// the device is named by the X-Device-Name header or guessed from the user agent
+func IssueToken(c *gin.Context, userModel user.User) (jwt.TokenPair, error) {
+	return NewSession(c.Request.Context(), userModel, c.ClientIP(), c.Request.UserAgent(), c.GetHeader("X-Device-Name"))
+}
+
+// NewSession Issue a token pair for the user and record the session it belongs to
+func NewSession(ctx context.Context, userModel user.User, ip, userAgent, device string) (jwt.TokenPair, error) {
+	jwtInstance := jwt.NewJWT()
+	pair := jwtInstance.IssueToken(userModel.GetStringID(), userModel.Name)
+	if pair.FamilyID == "" {
+		return jwt.TokenPair{}, ErrIssueToken
+	}
+
+	now := time.Now()
+	sessionModel := session.Session{
+		UserID:     userModel.GetStringID(),
+		FamilyID:   pair.FamilyID,
+		Device:     deviceName(device, userAgent),
+		IP:         ip,
+		UserAgent:  truncate(userAgent, 255),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(jwtInstance.MaxRefresh),
+	}
+	if err := sessionModel.Create(ctx); err != nil {
+		// Tokens without a session are refused by AuthJWT
+		logger.LogIf(err)
+		jwtInstance.RevokeFamily(pair.FamilyID)
+		return jwt.TokenPair{}, ErrIssueToken
+	}
+	return pair, nil
+}
+
+// SessionActive Whether the session of the token family is neither revoked nor expired,
+// the answer is cached for jwt.session_cache_ttl seconds
+func SessionActive(ctx context.Context, familyID string) bool {
+	key := sessionActiveKey(familyID)
+	if cache.Has(key) {
+		return cache.GetBool(key)
+	}
+
+	active := session.GetActiveByFamily(ctx, familyID).ID > 0
+	cache.Set(key, active, time.Duration(config.GetInt("jwt.session_cache_ttl"))*time.Second)
+	return active
+}
+
+// TouchSession Record the request in the current session, at most once per jwt.session_touch_interval
+func TouchSession(c *gin.Context) {
+	familyID := CurrentSessionFamily(c)
+	key := "session:seen:" + familyID
+	if familyID == "" || cache.Has(key) {
+		return
+	}
+
+	session.Touch(c.Request.Context(), familyID, c.ClientIP())
+	cache.Set(key, true, time.Duration(config.GetInt("jwt.session_touch_interval"))*time.Second)
+}
+
+// CurrentSessionFamily The token family of the current request, set by AuthJWT
+func CurrentSessionFamily(c *gin.Context) string {
+	return c.GetString("current_session_family")
+}
+
+// RevokeSession Log the session out, its tokens are refused from the next request on
+func RevokeSession(ctx context.Context, sessionModel session.Session) bool {
+	if sessionModel.Revoke(ctx) == 0 {
+		return false
+	}
+	jwt.NewJWT().RevokeFamily(sessionModel.FamilyID)
+	cache.Forget(sessionActiveKey(sessionModel.FamilyID))
+	return true
+}
+
+// RevokeAllSessions Log out all sessions of the user
+func RevokeAllSessions(ctx context.Context, userID string) {
+	jwt.NewJWT().RevokeAll(userID)
+	for _, sessionModel := range session.ActiveOf(ctx, userID) {
+		RevokeSession(ctx, sessionModel)
+	}
+}
+
+func sessionActiveKey(familyID string) string {
+	return "session:active:" + familyID
+}
+
+// deviceName The name the client gave, or a name like "Chrome on Windows" guessed from the user agent
+func deviceName(name, userAgent string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return truncate(name, 64)
+	}
+
+	var browser, system string
+	for _, known := range []struct{ token, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"}, {"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, known.token) {
+			browser = known.name
+			break
+		}
+	}
+	for _, known := range []struct{ token, name string }{
+		{"iPhone", "iPhone"}, {"iPad", "iPad"}, {"Android", "Android"},
+		{"Windows", "Windows"}, {"Macintosh", "macOS"}, {"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, known.token) {
+			system = known.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "" || system != "":
+		return browser + system
+	case userAgent != "":
+		return truncate(strings.SplitN(userAgent, " ", 2)[0], 64)
+	default:
+		return "Unknown device"
+	}
+}
+
+// truncate Cut s to at most n bytes, on a rune boundary
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
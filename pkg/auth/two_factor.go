@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gohub/app/models/user"
+	"gohub/pkg/cache"
+	"gohub/pkg/config"
+	"gohub/pkg/database"
+	"gohub/pkg/hash"
+	"gohub/pkg/helpers"
+	"gohub/pkg/totp"
+)
+
+// ErrTwoFactorCode The code of the authenticator app or the recovery code is wrong or used already
+var ErrTwoFactorCode = errors.New("invalid two-factor code")
+
+// AttemptTwoFactor Check the code of the authenticator app of a user with two-factor authentication,
+// or one of their recovery codes when code is empty. Both can be used only once.
+func AttemptTwoFactor(ctx context.Context, userModel user.User, code, recoveryCode string) error {
+	if code != "" {
+		return checkTOTP(userModel, userModel.TwoFactorSecret, code)
+	}
+	return useRecoveryCode(ctx, userModel, recoveryCode)
+}
+
+// CheckTwoFactorSetup Check the first code of the secret being set up
+func CheckTwoFactorSetup(userModel user.User, code string) error {
+	return checkTOTP(userModel, userModel.TwoFactorSecret, code)
+}
+
+// NewRecoveryCodes Random recovery codes like "a1b2c-3d4e5", two_factor.recovery_codes of them,
+// with the JSON array of their hashes to be stored
+func NewRecoveryCodes() (codes []string, hashes string) {
+	hashed := make([]string, 0, config.GetInt("two_factor.recovery_codes"))
+	for range cap(hashed) {
+		code := strings.ToLower(helpers.SecureRandomString(5) + "-" + helpers.SecureRandomString(5))
+		codes = append(codes, code)
+		hashed = append(hashed, hash.SHA256(code))
+	}
+	encoded, _ := json.Marshal(hashed)
+	return codes, string(encoded)
+}
+
+// checkTOTP A code is accepted once, its period is claimed atomically so that of parallel
+// requests with the same code only one passes. Codes of earlier periods are refused afterwards.
+func checkTOTP(userModel user.User, secret, code string) error {
+	skew := config.GetInt("two_factor.skew")
+	step, ok := totp.Validate(secret, code, time.Now(), skew)
+	if !ok {
+		return ErrTwoFactorCode
+	}
+
+	// Remembered as long as the code could be accepted
+	expire := time.Duration(2*skew+1) * totp.Period * time.Second
+	if !cache.Add(stepKey(userModel, step), true, expire) {
+		return ErrTwoFactorCode
+	}
+	for earlier := step - int64(2*skew); earlier < step; earlier++ {
+		cache.Set(stepKey(userModel, earlier), true, expire)
+	}
+	return nil
+}
+
+func stepKey(userModel user.User, step int64) string {
+	return "two_factor:step:" + userModel.GetStringID() + ":" + strconv.FormatInt(step, 10)
+}
+
+// useRecoveryCode Remove the recovery code from the user, when it has not been used concurrently
+func useRecoveryCode(ctx context.Context, userModel user.User, recoveryCode string) error {
+	var hashes []string
+	if err := json.Unmarshal([]byte(userModel.TwoFactorRecoveryCodes), &hashes); err != nil {
+		return ErrTwoFactorCode
+	}
+
+	index := slices.IndexFunc(hashes, func(hashed string) bool {
+		return hash.SHA256Check(strings.ToLower(strings.TrimSpace(recoveryCode)), hashed)
+	})
+	if index < 0 {
+		return ErrTwoFactorCode
+	}
+
+	remaining, _ := json.Marshal(slices.Delete(slices.Clone(hashes), index, index+1))
+	result := database.DBWithContext(ctx).Model(&user.User{}).
+		Where("id = ? AND two_factor_recovery_codes = ?", userModel.ID, userModel.TwoFactorRecoveryCodes).
+		Update("two_factor_recovery_codes", string(remaining))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTwoFactorCode
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gohub/app/models"
+	"gohub/app/models/user"
+	"gohub/pkg/cache"
+	"gohub/pkg/config"
+	"gohub/pkg/totp"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTOTPConcurrent(t *testing.T) {
+	cache.InitWithCacheStore(cache.NewMemoryStore())
+	cache.Flush()
+	config.Set("two_factor.skew", 1)
+
+	secret := totp.GenerateSecret()
+	for id := range uint64(50) {
+		userModel := user.User{BaseModel: models.BaseModel{ID: id + 1}, TwoFactorSecret: secret}
+		code, err := totp.Code(secret, time.Now().Unix()/totp.Period)
+		require.NoError(t, err)
+
+		var accepted atomic.Int32
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for range 16 {
+			wg.Go(func() {
+				<-start
+				if CheckTwoFactorSetup(userModel, code) == nil {
+					accepted.Add(1)
+				}
+			})
+		}
+		close(start)
+		wg.Wait()
+		require.EqualValues(t, 1, accepted.Load())
+	}
+}
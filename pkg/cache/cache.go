@@ -33,6 +33,14 @@ func Set(key string, obj any, expireTime time.Duration) {
 	Cache.Store.Set(key, string(b), expireTime)
 }
 
+// Add Set the key only when it does not exist, when several callers race for the
+// same key only one of them gets true
+func Add(key string, obj any, expireTime time.Duration) bool {
+	b, err := json.Marshal(&obj)
+	logger.LogIf(err)
+	return Cache.Store.Add(key, string(b), expireTime)
+}
+
 func Get(key string) any {
 	stringValue := Cache.Store.Get(key)
 	var wanted any
@@ -49,6 +57,25 @@ func Forget(key string) {
 	Cache.Store.Forget(key)
 }
 
+// Pull Delete the key and report whether it existed, when several callers race for the
+// same key only one of them gets true
+func Pull(key string) bool {
+	return Cache.Store.Pull(key) != ""
+}
+
+// PullObject Delete the key and decode its value into wanted, false when it did not exist
+func PullObject(key string, wanted any) bool {
+	val := Cache.Store.Pull(key)
+	if len(val) == 0 {
+		return false
+	}
+	if err := json.Unmarshal([]byte(val), &wanted); err != nil {
+		logger.LogIf(err)
+		return false
+	}
+	return true
+}
+
 func Forever(key, value string) {
 	Cache.Store.Forever(key, value)
 }
@@ -64,6 +64,37 @@ func (store *MemoryStore) Forget(key string) {
 	delete(store.items, key)
 }
 
+func (store *MemoryStore) Pull(key string) string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	item, ok := store.items[key]
+	if !ok {
+		return ""
+	}
+	delete(store.items, key)
+	if item.hasExpire && time.Now().After(item.expiresAt) {
+		return ""
+	}
+	return item.value
+}
+
+func (store *MemoryStore) Add(key, value string, expireTime time.Duration) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if item, ok := store.items[key]; ok && (!item.hasExpire || time.Now().Before(item.expiresAt)) {
+		return false
+	}
+	item := memoryItem{value: value}
+	if expireTime > 0 {
+		item.hasExpire = true
+		item.expiresAt = time.Now().Add(expireTime)
+	}
+	store.items[key] = item
+	return true
+}
+
 func (store *MemoryStore) Forever(key, value string) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
@@ -36,6 +36,14 @@ func (s *RedisStore) Forget(key string) {
 	s.RedisClient.Del(s.KeyPrefix + key)
 }
 
+func (s *RedisStore) Pull(key string) string {
+	return s.RedisClient.Pull(s.KeyPrefix + key)
+}
+
+func (s *RedisStore) Add(key, value string, expireTime time.Duration) bool {
+	return s.RedisClient.SetNX(s.KeyPrefix+key, value, expireTime)
+}
+
 func (s *RedisStore) Forever(key, value string) {
 	s.RedisClient.Set(s.KeyPrefix+key, value, 0)
 }
@@ -49,9 +57,19 @@ func (s *RedisStore) IsAlive() error {
 }
 
 func (s *RedisStore) Increment(parameters ...any) {
-	s.RedisClient.Increment(parameters...)
+	s.RedisClient.Increment(s.prefixKey(parameters)...)
 }
 
 func (s *RedisStore) Decrement(parameters ...any) {
-	s.RedisClient.Decrement(parameters...)
+	s.RedisClient.Decrement(s.prefixKey(parameters)...)
+}
+
+// prefixKey The first parameter of Increment and Decrement is the key, it shares the prefix of Get/Set
+func (s *RedisStore) prefixKey(parameters []any) []any {
+	if len(parameters) > 0 {
+		if key, ok := parameters[0].(string); ok {
+			parameters[0] = s.KeyPrefix + key
+		}
+	}
+	return parameters
 }
@@ -7,6 +7,10 @@ type Store interface {
 	Get(key string) string
 	Has(key string) bool
 	Forget(key string)
+	// Pull Get the value and delete the key atomically, the empty string when it does not exist
+	Pull(key string) string
+	// Add Set the key only when it does not exist, atomically, false when it did
+	Add(key, value string, expireTime time.Duration) bool
 	Forever(key, value string)
 	Flush()
 
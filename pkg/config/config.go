@@ -92,6 +92,11 @@ func Add(name string, configFn Func) {
 	Funcs[name] = configFn
 }
 
+// Set Override a configuration item at runtime, e.g. to point a service at a fake server in tests
+func Set(path string, value any) {
+	viper.Set(path, value)
+}
+
 // Get To get configuration items
 // param 'path' Dot passing is allowed, e.g.: app.name
 func Get(path string, defaultValue ...any) string {
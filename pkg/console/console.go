@@ -2,8 +2,11 @@
 package console
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/mgutz/ansi"
 	"gohub/pkg/logger"
@@ -37,6 +40,19 @@ func ExitIf(err error) {
 	}
 }
 
+// Confirm Ask a yes or no question on the terminal, anything but y or yes is a no
+func Confirm(question string) bool {
+	return confirm(os.Stdin, question)
+}
+
+func confirm(input io.Reader, question string) bool {
+	fmt.Fprint(os.Stdout, ansi.Color(question+" [y/N] ", "yellow"))
+
+	answer, _ := bufio.NewReader(input).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 // colorOut For internal use, set the highlight color
 func colorOut(message, color string) {
 	if os.Getenv("CONSOLE_SILENT") != "" && os.Getenv("APP_ENV") == "testing" {
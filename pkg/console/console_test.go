@@ -0,0 +1,16 @@
+package console
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirm(t *testing.T) {
+	require.True(t, confirm(strings.NewReader("y\n"), "Continue?"))
+	require.True(t, confirm(strings.NewReader(" YES \n"), "Continue?"))
+	require.False(t, confirm(strings.NewReader("n\n"), "Continue?"))
+	require.False(t, confirm(strings.NewReader("\n"), "Continue?"), "no is the default")
+	require.False(t, confirm(strings.NewReader(""), "Continue?"), "no input is a no")
+}
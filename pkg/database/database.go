@@ -117,7 +117,7 @@ func deleteAllSqliteTables() error {
 	var tables []string
 
 	// Read all tables
-	err := DB.Select(&tables, "SELECT name FROM sqlite_master WHERE type='table'").Error
+	err := DB.Raw("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'").Scan(&tables).Error
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,10 @@
 package hash
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
 	"gohub/pkg/logger"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -24,3 +28,15 @@ func BcryptCheck(password, hash string) bool {
 func BcryptIsHashed(str string) bool {
 	return len(str) == 60
 }
+
+// SHA256 Hash random tokens such as recovery codes, which are too long to guess
+// and have to be looked up quickly, unlike passwords
+func SHA256(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SHA256Check Compare a token to its SHA256 hash in constant time
+func SHA256Check(token, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(SHA256(token)), []byte(hash)) == 1
+}
@@ -18,3 +18,10 @@ func TestBcryptIsHashed(t *testing.T) {
 	require.True(t, BcryptIsHashed(BcryptHash("x")))
 	require.False(t, BcryptIsHashed("plain"))
 }
+
+func TestSHA256(t *testing.T) {
+	hashed := SHA256("abcde-12345")
+	require.Len(t, hashed, 64)
+	require.True(t, SHA256Check("abcde-12345", hashed))
+	require.False(t, SHA256Check("abcde-12346", hashed))
+}
@@ -10,6 +10,7 @@ import (
 	jwtPkg "github.com/golang-jwt/jwt/v5"
 	"gohub/pkg/app"
 	"gohub/pkg/config"
+	"gohub/pkg/helpers"
 	"gohub/pkg/logger"
 )
 
@@ -18,10 +19,21 @@ var (
 	ErrTokenExpiredMaxRefresh = errors.New("token has passed the maximum refresh time")
 	ErrTokenMalformed         = errors.New("malformed request token")
 	ErrTokenInvalid           = errors.New("invalid request token")
+	ErrTokenRevoked           = errors.New("token has been revoked")
+	ErrTokenReused            = errors.New("refresh token has already been used, the login has been revoked")
 	ErrHeaderEmpty            = errors.New("authentication is required to access")
 	ErrHeaderMalformed        = errors.New("bad format for 'Authorization' in request header")
 )
 
+const (
+	// TokenTypeAccess Token used in the Authorization header
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh Token that can only be exchanged for a new token pair
+	TokenTypeRefresh = "refresh"
+	// TokenTypeChallenge Token of a login that waits for the two-factor code
+	TokenTypeChallenge = "challenge"
+)
+
 // JWT define a jwt object
 type JWT struct {
 	// SignKey Key, used to encrypt JWT, read configuration information app.key
@@ -37,9 +49,26 @@ type CustomClaims struct {
 	UserName     string `json:"user_name"`
 	ExpireAtTime int64  `json:"expire_time"`
 
+	// TokenType access or refresh
+	TokenType string `json:"token_type"`
+	// FamilyID All tokens rotated from the same login share the family
+	FamilyID string `json:"family_id"`
+	// Generation Tokens issued before the user logged out everywhere have an older generation
+	Generation int64 `json:"generation"`
+
 	jwtPkg.RegisteredClaims
 }
 
+// TokenPair The access token and the refresh token issued together
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpireAtTime int64  `json:"expire_time"`
+
+	// FamilyID The login the tokens belong to, kept on refresh
+	FamilyID string `json:"-"`
+}
+
 func NewJWT() *JWT {
 	return &JWT{
 		SignKey:    []byte(config.GetString("app.key")),
@@ -54,78 +83,173 @@ func (jwt *JWT) ParseToken(c *gin.Context) (*CustomClaims, error) {
 		return nil, parseErr
 	}
 
-	token, err := jwt.parseTokenString(tokenString)
+	claims, err := jwt.parseClaims(tokenString, TokenTypeAccess)
 	if err != nil {
-		if errors.Is(err, jwtPkg.ErrTokenMalformed) {
-			return nil, ErrTokenMalformed
-		}
-		if errors.Is(err, jwtPkg.ErrTokenExpired) {
-			return nil, ErrTokenExpired
-		}
-		return nil, ErrTokenInvalid
+		return nil, err
 	}
 
-	// Parse the claims information in the token and verify it with the CustomClaims data structure
-	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid {
-		return claims, nil
+	if err := checkRevoked(claims); err != nil {
+		return nil, err
 	}
 
-	return nil, ErrTokenInvalid
+	return claims, nil
 }
 
-// RefreshToken Update Token to provide refresh token interface
-func (jwt *JWT) RefreshToken(c *gin.Context) (string, error) {
-	tokenString, parseErr := jwt.getTokenFromHeader(c)
-	if parseErr != nil {
-		return "", parseErr
-	}
-
-	token, err := jwt.parseTokenString(tokenString)
+// RefreshToken Exchange a refresh token for a new token pair, the used refresh token is invalidated.
+// Presenting a refresh token that has already been used revokes the whole login.
+func (jwt *JWT) RefreshToken(refreshToken string) (TokenPair, error) {
+	claims, err := jwt.parseClaims(refreshToken, TokenTypeRefresh)
 	if err != nil {
-		if !errors.Is(err, jwtPkg.ErrTokenExpired) {
-			return "", err
-		}
+		return TokenPair{}, err
 	}
 
-	// parse CustomClaims data
-	claims := token.Claims.(*CustomClaims)
-
 	// Check if the [Maximum Allowed Refresh Time] has passed
 	x := app.TimenowInTimezone().Add(-jwt.MaxRefresh).Unix()
-	if claims.IssuedAt != nil && claims.IssuedAt.Time.Unix() > x {
-		// Modify expiration time
-		claims.RegisteredClaims.ExpiresAt = jwtPkg.NewNumericDate(time.Unix(jwt.expireAtTime(), 0))
-		return jwt.createToken(*claims)
+	if claims.IssuedAt == nil || claims.IssuedAt.Time.Unix() <= x {
+		return TokenPair{}, ErrTokenExpiredMaxRefresh
+	}
+
+	if err := checkRevoked(claims); err != nil {
+		return TokenPair{}, err
+	}
+
+	if !consumeRefreshToken(claims.ID) {
+		// The refresh token was stolen or replayed, nobody may continue this login
+		revokeFamily(claims.FamilyID)
+		return TokenPair{}, ErrTokenReused
 	}
 
-	return "", ErrTokenExpiredMaxRefresh
+	return jwt.issuePair(claims.UserID, claims.UserName, claims.FamilyID, claims.IssuedAt.Time)
 }
 
 // IssueToken Generate Token and call when the login is successful
-func (jwt *JWT) IssueToken(userID, userName string) string {
-	// Construct user claims information (load)
-	expireAtTime := jwt.expireAtTime()
+func (jwt *JWT) IssueToken(userID, userName string) TokenPair {
 	now := app.TimenowInTimezone()
-	claims := CustomClaims{
+
+	// Every login starts a new token family
+	familyID := helpers.SecureRandomString(32)
+	rememberFamily(familyID, userID, jwt.MaxRefresh)
+
+	pair, err := jwt.issuePair(userID, userName, familyID, now)
+	if err != nil {
+		logger.LogIf(err)
+		return TokenPair{}
+	}
+
+	return pair
+}
+
+// IssueChallenge Generate a short-lived token after the first factor of a login of a user
+// with two-factor authentication was right, exchanged for a token pair with the code
+func (jwt *JWT) IssueChallenge(userID, userName string) (token string, expireAtTime int64, err error) {
+	now := app.TimenowInTimezone()
+	expireAt := now.Add(time.Duration(config.GetInt64("two_factor.challenge_expire")) * time.Minute)
+
+	token, err = jwt.createToken(CustomClaims{
+		userID,
+		userName,
+		expireAt.Unix(),
+		TokenTypeChallenge,
+		"",
+		currentGeneration(userID),
+		jwtPkg.RegisteredClaims{
+			ID:        helpers.SecureRandomString(32),
+			NotBefore: jwtPkg.NewNumericDate(now),
+			IssuedAt:  jwtPkg.NewNumericDate(now),
+			ExpiresAt: jwtPkg.NewNumericDate(expireAt),
+			Issuer:    config.GetString("app.name"),
+		},
+	})
+	return token, expireAt.Unix(), err
+}
+
+// ParseChallenge Parse a token of IssueChallenge, it is not valid after the user logged out everywhere
+func (jwt *JWT) ParseChallenge(token string) (*CustomClaims, error) {
+	claims, err := jwt.parseClaims(token, TokenTypeChallenge)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Generation != currentGeneration(claims.UserID) {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// Revoke Log out the login the token in the request header belongs to
+func (jwt *JWT) Revoke(c *gin.Context) error {
+	claims, err := jwt.ParseToken(c)
+	if err != nil {
+		return err
+	}
+
+	revokeFamily(claims.FamilyID)
+	return nil
+}
+
+// RevokeFamily Log out the login of the token family, such as a session revoked from another device
+func (jwt *JWT) RevokeFamily(familyID string) {
+	revokeFamily(familyID)
+}
+
+// RevokeAll Log out all logins of the user
+func (jwt *JWT) RevokeAll(userID string) {
+	increaseGeneration(userID)
+}
+
+// issuePair Sign an access token and a refresh token of the family
+// issuedAt is the time of the login and is kept on rotation
+func (jwt *JWT) issuePair(userID, userName, familyID string, issuedAt time.Time) (TokenPair, error) {
+	now := app.TimenowInTimezone()
+	generation := currentGeneration(userID)
+
+	expireAtTime := jwt.expireAtTime()
+	accessToken, err := jwt.createToken(CustomClaims{
 		userID,
 		userName,
 		expireAtTime,
+		TokenTypeAccess,
+		familyID,
+		generation,
 		jwtPkg.RegisteredClaims{
+			ID:        helpers.SecureRandomString(32),
 			NotBefore: jwtPkg.NewNumericDate(now),                        // Signature effective time
-			IssuedAt:  jwtPkg.NewNumericDate(now),                        // First signature time
+			IssuedAt:  jwtPkg.NewNumericDate(issuedAt),                   // First signature time
 			ExpiresAt: jwtPkg.NewNumericDate(time.Unix(expireAtTime, 0)), // Signature expiration time
 			Issuer:    config.GetString("app.name"),                      // Signature issuer
 		},
+	})
+	if err != nil {
+		return TokenPair{}, err
 	}
 
-	// Generate token object based on claims
-	token, err := jwt.createToken(claims)
+	refreshExpireAt := jwt.refreshExpireAt(issuedAt)
+	refreshID := helpers.SecureRandomString(32)
+	refreshToken, err := jwt.createToken(CustomClaims{
+		userID,
+		userName,
+		refreshExpireAt.Unix(),
+		TokenTypeRefresh,
+		familyID,
+		generation,
+		jwtPkg.RegisteredClaims{
+			ID:        refreshID,
+			NotBefore: jwtPkg.NewNumericDate(now),
+			IssuedAt:  jwtPkg.NewNumericDate(issuedAt),
+			ExpiresAt: jwtPkg.NewNumericDate(refreshExpireAt),
+			Issuer:    config.GetString("app.name"),
+		},
+	})
 	if err != nil {
-		logger.LogIf(err)
-		return ""
+		return TokenPair{}, err
 	}
+	rememberRefreshToken(refreshID, familyID, refreshExpireAt.Sub(now))
 
-	return token
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpireAtTime: expireAtTime,
+		FamilyID:     familyID,
+	}, nil
 }
 
 // createToken Create Token, internal use, please call IssueToken externally
@@ -149,6 +273,38 @@ func (jwt *JWT) expireAtTime() int64 {
 	return timenow.Add(expire).Unix()
 }
 
+// refreshExpireAt Expired time of the refresh token, never later than the maximum refresh time of the login
+func (jwt *JWT) refreshExpireAt(issuedAt time.Time) time.Time {
+	expire := time.Duration(config.GetInt64("jwt.refresh_expire_time")) * time.Minute
+	expireAt := app.TimenowInTimezone().Add(expire)
+
+	if maxExpireAt := issuedAt.Add(jwt.MaxRefresh); expireAt.After(maxExpireAt) {
+		return maxExpireAt
+	}
+	return expireAt
+}
+
+// parseClaims Parse the token string and make sure it is of the wanted type
+func (jwt *JWT) parseClaims(tokenString, tokenType string) (*CustomClaims, error) {
+	token, err := jwt.parseTokenString(tokenString)
+	if err != nil {
+		if errors.Is(err, jwtPkg.ErrTokenMalformed) {
+			return nil, ErrTokenMalformed
+		}
+		if errors.Is(err, jwtPkg.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrTokenInvalid
+	}
+
+	// Parse the claims information in the token and verify it with the CustomClaims data structure
+	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid && claims.TokenType == tokenType {
+		return claims, nil
+	}
+
+	return nil, ErrTokenInvalid
+}
+
 // parseTokenString Use jwtPkg.ParseWithClaims to parse Token
 func (jwt *JWT) parseTokenString(tokenString string) (*jwtPkg.Token, error) {
 	return jwtPkg.ParseWithClaims(
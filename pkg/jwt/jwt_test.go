@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	jwtPkg "github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/require"
 	appconfig "gohub/config"
+	"gohub/pkg/cache"
 	pkgconfig "gohub/pkg/config"
 )
 
@@ -37,6 +39,7 @@ func initJWTTestConfig(t *testing.T) {
 
 	appconfig.Initialize()
 	pkgconfig.InitConfig("")
+	cache.InitWithCacheStore(cache.NewMemoryStore())
 }
 
 func newGinContextWithToken(t *testing.T, token string) *gin.Context {
@@ -52,10 +55,11 @@ func TestIssueAndParseToken(t *testing.T) {
 	initJWTTestConfig(t)
 
 	jwt := NewJWT()
-	tokenString := jwt.IssueToken("1", "alice")
-	require.NotEmpty(t, tokenString)
+	pair := jwt.IssueToken("1", "alice")
+	require.NotEmpty(t, pair.AccessToken)
+	require.NotEmpty(t, pair.RefreshToken)
 
-	c := newGinContextWithToken(t, tokenString)
+	c := newGinContextWithToken(t, pair.AccessToken)
 	claims, err := jwt.ParseToken(c)
 	require.NoError(t, err)
 	require.Equal(t, "1", claims.UserID)
@@ -88,29 +92,97 @@ func TestParseTokenMalformedHeader(t *testing.T) {
 	require.ErrorIs(t, err, ErrHeaderMalformed)
 }
 
-func TestRefreshTokenExpiredWithinMaxRefresh(t *testing.T) {
+func TestParseTokenRejectsRefreshToken(t *testing.T) {
 	initJWTTestConfig(t)
 
 	jwt := NewJWT()
-	now := time.Now().UTC()
-	claims := CustomClaims{
-		UserID:       "1",
-		UserName:     "bob",
-		ExpireAtTime: now.Add(-time.Minute).Unix(),
-		RegisteredClaims: jwtPkg.RegisteredClaims{
-			IssuedAt:  jwtPkg.NewNumericDate(now.Add(-time.Minute)),
-			ExpiresAt: jwtPkg.NewNumericDate(now.Add(-time.Minute)),
-			Issuer:    "Gohub",
-		},
-	}
+	pair := jwt.IssueToken("1", "alice")
+
+	_, err := jwt.ParseToken(newGinContextWithToken(t, pair.RefreshToken))
+	require.ErrorIs(t, err, ErrTokenInvalid)
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	initJWTTestConfig(t)
 
-	expiredToken, err := jwt.createToken(claims)
+	jwt := NewJWT()
+	pair := jwt.IssueToken("1", "bob")
+
+	rotated, err := jwt.RefreshToken(pair.RefreshToken)
+	require.NoError(t, err)
+	require.NotEqual(t, pair.RefreshToken, rotated.RefreshToken)
+
+	_, err = jwt.ParseToken(newGinContextWithToken(t, rotated.AccessToken))
+	require.NoError(t, err)
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	initJWTTestConfig(t)
+
+	jwt := NewJWT()
+	pair := jwt.IssueToken("1", "bob")
+
+	rotated, err := jwt.RefreshToken(pair.RefreshToken)
 	require.NoError(t, err)
 
-	c := newGinContextWithToken(t, expiredToken)
-	newToken, err := jwt.RefreshToken(c)
+	// Replaying the old refresh token kills the whole login
+	_, err = jwt.RefreshToken(pair.RefreshToken)
+	require.ErrorIs(t, err, ErrTokenReused)
+
+	_, err = jwt.RefreshToken(rotated.RefreshToken)
+	require.ErrorIs(t, err, ErrTokenRevoked)
+
+	_, err = jwt.ParseToken(newGinContextWithToken(t, rotated.AccessToken))
+	require.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestRefreshTokenParallelReuse(t *testing.T) {
+	initJWTTestConfig(t)
+
+	jwt := NewJWT()
+	pair := jwt.IssueToken("1", "carol")
+
+	// Of parallel refreshes with the same token only one rotates, the others are reuse
+	const parallel = 8
+	errs := make(chan error, parallel)
+	var wg sync.WaitGroup
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := jwt.RefreshToken(pair.RefreshToken)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	require.Equal(t, 1, succeeded)
+}
+
+func TestRevokeAll(t *testing.T) {
+	initJWTTestConfig(t)
+
+	jwt := NewJWT()
+	first := jwt.IssueToken("7", "carol")
+	second := jwt.IssueToken("7", "carol")
+
+	jwt.RevokeAll("7")
+
+	_, err := jwt.ParseToken(newGinContextWithToken(t, first.AccessToken))
+	require.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = jwt.RefreshToken(second.RefreshToken)
+	require.ErrorIs(t, err, ErrTokenRevoked)
+
+	third := jwt.IssueToken("7", "carol")
+	_, err = jwt.ParseToken(newGinContextWithToken(t, third.AccessToken))
 	require.NoError(t, err)
-	require.NotEmpty(t, newToken)
 }
 
 func TestRefreshTokenExpiredBeyondMaxRefresh(t *testing.T) {
@@ -121,18 +193,42 @@ func TestRefreshTokenExpiredBeyondMaxRefresh(t *testing.T) {
 	claims := CustomClaims{
 		UserID:       "1",
 		UserName:     "bob",
-		ExpireAtTime: now.Add(-time.Hour).Unix(),
+		ExpireAtTime: now.Add(time.Hour).Unix(),
+		TokenType:    TokenTypeRefresh,
 		RegisteredClaims: jwtPkg.RegisteredClaims{
 			IssuedAt:  jwtPkg.NewNumericDate(now.Add(-time.Hour)),
-			ExpiresAt: jwtPkg.NewNumericDate(now.Add(-time.Hour)),
+			ExpiresAt: jwtPkg.NewNumericDate(now.Add(time.Hour)),
 			Issuer:    "Gohub",
 		},
 	}
 
-	expiredToken, err := jwt.createToken(claims)
+	refreshToken, err := jwt.createToken(claims)
 	require.NoError(t, err)
 
-	c := newGinContextWithToken(t, expiredToken)
-	_, err = jwt.RefreshToken(c)
+	_, err = jwt.RefreshToken(refreshToken)
 	require.ErrorIs(t, err, ErrTokenExpiredMaxRefresh)
 }
+
+func TestChallengeToken(t *testing.T) {
+	initJWTTestConfig(t)
+
+	jwt := NewJWT()
+	challenge, expireAt, err := jwt.IssueChallenge("3", "carol")
+	require.NoError(t, err)
+	require.Greater(t, expireAt, time.Now().Unix())
+
+	claims, err := jwt.ParseChallenge(challenge)
+	require.NoError(t, err)
+	require.Equal(t, "3", claims.UserID)
+
+	// A challenge is no access token and the other way round
+	_, err = jwt.ParseToken(newGinContextWithToken(t, challenge))
+	require.ErrorIs(t, err, ErrTokenInvalid)
+	_, err = jwt.ParseChallenge(jwt.IssueToken("3", "carol").AccessToken)
+	require.ErrorIs(t, err, ErrTokenInvalid)
+
+	// Logging out everywhere also ends the challenges
+	jwt.RevokeAll("3")
+	_, err = jwt.ParseChallenge(challenge)
+	require.ErrorIs(t, err, ErrTokenRevoked)
+}
@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"time"
+
+	"gohub/pkg/cache"
+)
+
+// Server-side state of the issued tokens, stored in the cache:
+//
+//	jwt:family:{family_id}    -> user id, exists while the login is active
+//	jwt:refresh:{jti}         -> family id, exists until the refresh token is used
+//	jwt:generation:{user_id}  -> increased when the user logs out everywhere
+
+func familyKey(familyID string) string {
+	return "jwt:family:" + familyID
+}
+
+func refreshKey(jti string) string {
+	return "jwt:refresh:" + jti
+}
+
+func generationKey(userID string) string {
+	return "jwt:generation:" + userID
+}
+
+func rememberFamily(familyID, userID string, ttl time.Duration) {
+	cache.Set(familyKey(familyID), userID, ttl)
+}
+
+func revokeFamily(familyID string) {
+	cache.Forget(familyKey(familyID))
+}
+
+func rememberRefreshToken(jti, familyID string, ttl time.Duration) {
+	cache.Set(refreshKey(jti), familyID, ttl)
+}
+
+// consumeRefreshToken Invalidate the refresh token, false means it has already been used
+func consumeRefreshToken(jti string) bool {
+	// Only the first of parallel refreshes with the same token removes the key
+	return cache.Pull(refreshKey(jti))
+}
+
+func currentGeneration(userID string) int64 {
+	if !cache.Has(generationKey(userID)) {
+		return 0
+	}
+	return cache.GetInt64(generationKey(userID))
+}
+
+func increaseGeneration(userID string) {
+	cache.Increment(generationKey(userID))
+}
+
+// checkRevoked Make sure the login of the token is still active
+func checkRevoked(claims *CustomClaims) error {
+	if !cache.Has(familyKey(claims.FamilyID)) {
+		return ErrTokenRevoked
+	}
+	if claims.Generation != currentGeneration(claims.UserID) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
@@ -1,6 +1,6 @@
 package mail
 
 type Driver interface {
-	// Send Check verify code
-	Send(email Email, config map[string]string) bool
+	// Send Email, the error tells why it was not delivered
+	Send(email Email, config map[string]string) error
 }
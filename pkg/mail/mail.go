@@ -2,9 +2,12 @@
 package mail
 
 import (
+	"context"
+	"encoding/json"
 	"sync"
 
 	"gohub/pkg/config"
+	"gohub/pkg/queue"
 )
 
 type From struct {
@@ -42,6 +45,25 @@ func NewMailer() *Mailer {
 	return internalMailer
 }
 
-func (mailer *Mailer) Send(email Email) bool {
+// Send Deliver the email right away, see Queue for sending it in the background
+func (mailer *Mailer) Send(email Email) error {
 	return mailer.Driver.Send(email, config.GetStringMapString("mail.smtp"))
 }
+
+// JobType Jobs of this type are emails queued by Queue
+const JobType = "mail"
+
+// Queue Let `gohub queue:work` send the email, it is retried with backoff when sending fails
+func (mailer *Mailer) Queue(ctx context.Context, email Email) error {
+	return queue.Dispatch(ctx, JobType, email)
+}
+
+func init() {
+	queue.Register(JobType, func(_ context.Context, payload []byte) error {
+		var email Email
+		if err := json.Unmarshal(payload, &email); err != nil {
+			return err
+		}
+		return NewMailer().Send(email)
+	})
+}
@@ -12,7 +12,7 @@ import (
 type SMTP struct{}
 
 // Send Implement the Send method of the email.Driver interface
-func (s *SMTP) Send(email Email, config map[string]string) bool {
+func (s *SMTP) Send(email Email, config map[string]string) error {
 	e := emailPKG.NewEmail()
 
 	e.From = fmt.Sprintf("%v <%v>", email.From.Name, email.From.Address)
@@ -36,9 +36,9 @@ func (s *SMTP) Send(email Email, config map[string]string) bool {
 	)
 	if err != nil {
 		logger.ErrorString("Send Email", "Error sending email", err.Error())
-		return false
+		return err
 	}
 
 	logger.DebugString("Send Email", "Send email success", "")
-	return true
+	return nil
 }
@@ -0,0 +1,184 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"maps"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gohub/pkg/config"
+	htmlParser "golang.org/x/net/html"
+)
+
+// templatesFS Layouts are in templates/layouts, messages in templates/<name>[.<locale>].html
+//
+//go:embed templates
+var templatesFS embed.FS
+
+// Template An email built from the embedded templates.
+//
+// A message template defines "subject" and "content", and may override the "footer" of the layout.
+// Data is available in the templates next to .AppName and .AppURL.
+//
+//	email := mail.Email{To: []string{address}}
+//	err := mail.Template{Name: "verify_code", Locale: "zh-CN", Data: data}.Render(&email)
+type Template struct {
+	Name string
+	// Layout in templates/layouts, "default" when empty
+	Layout string
+	// Locale A language tag or an Accept-Language header, the first one with a
+	// template variant is used, e.g. "zh-CN" tries verify_code.zh-CN.html and verify_code.zh.html
+	Locale string
+	Data   map[string]any
+}
+
+// parsed Templates are parsed once per layout and message file
+var parsed sync.Map
+
+// Render Fill in the Subject, HTML and the Text part generated from the HTML
+func (tpl Template) Render(email *Email) error {
+	layout := tpl.Layout
+	if layout == "" {
+		layout = "default"
+	}
+	file, err := tpl.file()
+	if err != nil {
+		return err
+	}
+
+	key := layout + "|" + file
+	value, ok := parsed.Load(key)
+	if !ok {
+		parsedTemplate, err := template.ParseFS(templatesFS, "templates/layouts/"+layout+".html", file)
+		if err != nil {
+			return fmt.Errorf("mail: parse template %s: %w", tpl.Name, err)
+		}
+		value, _ = parsed.LoadOrStore(key, parsedTemplate)
+	}
+	parsedTemplate := value.(*template.Template)
+
+	data := map[string]any{
+		"AppName": config.GetString("app.name"),
+		"AppURL":  config.GetString("app.url"),
+	}
+	maps.Copy(data, tpl.Data)
+
+	var subject, body bytes.Buffer
+	if err = parsedTemplate.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return fmt.Errorf("mail: render subject of %s: %w", tpl.Name, err)
+	}
+	if err = parsedTemplate.ExecuteTemplate(&body, layout+".html", data); err != nil {
+		return fmt.Errorf("mail: render %s: %w", tpl.Name, err)
+	}
+
+	// The subject is a header, not HTML
+	email.Subject = html.UnescapeString(strings.TrimSpace(subject.String()))
+	email.HTML = body.Bytes()
+	email.Text = []byte(HTMLToText(body.String()))
+	return nil
+}
+
+// DefaultLocale The language of the templates without a locale in their name
+const DefaultLocale = "en"
+
+// file The message template for the locale, falling back to the one without locale
+func (tpl Template) file() (string, error) {
+	for _, locale := range locales(tpl.Locale) {
+		if locale == DefaultLocale {
+			break
+		}
+		file := "templates/" + tpl.Name + "." + locale + ".html"
+		if _, err := fs.Stat(templatesFS, file); err == nil {
+			return file, nil
+		}
+	}
+
+	file := "templates/" + tpl.Name + ".html"
+	if _, err := fs.Stat(templatesFS, file); err != nil {
+		return "", fmt.Errorf("mail: no template %s", tpl.Name)
+	}
+	return file, nil
+}
+
+// locales Candidate locales in order of preference, "zh-CN,en;q=0.8" gives zh-CN, zh, en
+func locales(acceptLanguage string) (candidates []string) {
+	for _, tag := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ = strings.Cut(strings.TrimSpace(tag), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		candidates = append(candidates, tag)
+		if base, _, found := strings.Cut(tag, "-"); found {
+			candidates = append(candidates, base)
+		}
+	}
+	return candidates
+}
+
+var (
+	whitespace = regexp.MustCompile(`\s+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText The text of an HTML email: paragraphs and line breaks are kept,
+// links are followed by their URL, head, style and script are left out
+func HTMLToText(source string) string {
+	var text strings.Builder
+	var href string
+	skip := 0
+
+	tokenizer := htmlParser.NewTokenizer(strings.NewReader(source))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == htmlParser.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		switch tokenType {
+		case htmlParser.StartTagToken, htmlParser.SelfClosingTagToken:
+			switch token.Data {
+			case "head", "style", "script":
+				skip++
+			case "br":
+				text.WriteString("\n")
+			case "li":
+				text.WriteString("\n- ")
+			case "a":
+				for _, attr := range token.Attr {
+					if attr.Key == "href" {
+						href = attr.Val
+					}
+				}
+			}
+		case htmlParser.EndTagToken:
+			switch token.Data {
+			case "head", "style", "script":
+				skip--
+			case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "tr", "table", "ul", "ol":
+				text.WriteString("\n\n")
+			case "a":
+				if href != "" && !strings.HasPrefix(href, "#") {
+					text.WriteString(" (" + href + ")")
+				}
+				href = ""
+			}
+		case htmlParser.TextToken:
+			if skip == 0 {
+				text.WriteString(whitespace.ReplaceAllString(token.Data, " "))
+			}
+		}
+	}
+
+	lines := strings.Split(text.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")) + "\n"
+}
@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateRender(t *testing.T) {
+	email := Email{To: []string{"someone@example.com"}}
+	err := Template{
+		Name: "verify_code",
+		Data: map[string]any{"Code": "<b>123456</b>", "ExpireMinutes": 15},
+	}.Render(&email)
+	require.NoError(t, err)
+
+	html := string(email.HTML)
+	require.Contains(t, email.Subject, "verification code")
+	require.Contains(t, html, "<!DOCTYPE html>")
+	require.Contains(t, html, "&lt;b&gt;123456&lt;/b&gt;", "data is escaped")
+	require.Contains(t, html, "please do not reply", "the layout footer is used")
+
+	text := string(email.Text)
+	require.NotContains(t, text, "<p")
+	require.NotContains(t, text, "<!DOCTYPE")
+	require.Contains(t, text, "Your verification code is:\n\n<b>123456</b>\n\nIt expires in 15 minutes.")
+}
+
+func TestTemplateLocale(t *testing.T) {
+	render := func(locale string) Email {
+		t.Helper()
+		email := Email{}
+		err := Template{
+			Name:   "password_reset",
+			Locale: locale,
+			Data:   map[string]any{"Name": "summer", "ResetAt": "2026-10-18 12:00:00 UTC"},
+		}.Render(&email)
+		require.NoError(t, err)
+		return email
+	}
+
+	zh := render("zh-CN,zh;q=0.9,en;q=0.8")
+	require.Contains(t, zh.Subject, "密码已重置")
+	require.Contains(t, string(zh.Text), "summer，您好")
+	require.Contains(t, string(zh.HTML), "请勿回复", "the footer is overridden")
+
+	for _, locale := range []string{"", "fr-FR", "en-US,zh;q=0.5"} {
+		email := render(locale)
+		require.True(t, strings.HasPrefix(string(email.Text), "Hi summer,"), locale)
+	}
+}
+
+func TestTemplateMissing(t *testing.T) {
+	require.Error(t, Template{Name: "nope"}.Render(&Email{}))
+	require.Error(t, Template{Name: "verify_code", Layout: "nope"}.Render(&Email{}))
+}
+
+func TestHTMLToText(t *testing.T) {
+	source := `<html><head><style>p{color:red}</style></head><body>
+<h1>Hello   <b>world</b></h1>
+<p>Line one<br>line two, <a href="https://example.com/x">read more</a> &amp; bye</p>
+<ul><li>first</li><li>second</li></ul>
+</body></html>`
+
+	require.Equal(t,
+		"Hello world\n\nLine one\nline two, read more (https://example.com/x) & bye\n\n- first\n- second\n",
+		HTMLToText(source),
+	)
+}
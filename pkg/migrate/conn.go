@@ -0,0 +1,183 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// execQueryer Both *sql.DB and *sql.Tx
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// connector Lets the *sql.DB handed to the migrations run on a transaction, so that the
+// statements of GORM and the raw ones commit or roll back together.
+// When pretending, queries are still answered by the database, so schema checks see the
+// real tables, while the statements that would change it are only recorded.
+type connector struct {
+	target  execQueryer
+	pretend bool
+
+	mu         sync.Mutex
+	statements []string
+}
+
+// newTxConnector Run the statements on the transaction
+func newTxConnector(tx *sql.Tx) *connector {
+	return &connector{target: tx}
+}
+
+// newPretendConnector Record the statements instead of running them
+func newPretendConnector(db *sql.DB) *connector {
+	return &connector{target: db, pretend: true}
+}
+
+// Statements The recorded statements, with their arguments
+func (connector *connector) Statements() []string {
+	connector.mu.Lock()
+	defer connector.mu.Unlock()
+	return connector.statements
+}
+
+func (connector *connector) record(query string, args []any) {
+	statement := strings.TrimSpace(query)
+	if len(args) > 0 {
+		values := make([]string, 0, len(args))
+		for _, arg := range args {
+			values = append(values, fmt.Sprintf("%v", arg))
+		}
+		statement += " -- [" + strings.Join(values, ", ") + "]"
+	}
+
+	connector.mu.Lock()
+	defer connector.mu.Unlock()
+	connector.statements = append(connector.statements, statement)
+}
+
+func (connector *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{connector: connector}, nil
+}
+
+func (connector *connector) Driver() driver.Driver {
+	return connectorDriver{}
+}
+
+type connectorDriver struct{}
+
+func (connectorDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("migrate: the driver is only used through its connector")
+}
+
+type conn struct {
+	connector *connector
+}
+
+func (conn *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	values := namedValues(args)
+	if conn.connector.pretend {
+		conn.connector.record(query, values)
+		return driver.RowsAffected(0), nil
+	}
+	return conn.connector.target.ExecContext(ctx, query, values...)
+}
+
+func (conn *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := conn.connector.target.QueryContext(ctx, query, namedValues(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return &connRows{rows: rows}, nil
+}
+
+func (conn *conn) Prepare(query string) (driver.Stmt, error) {
+	return &connStmt{conn: conn, query: query}, nil
+}
+
+func (conn *conn) Close() error {
+	return nil
+}
+
+// Begin The statements already run in a transaction or are not run at all
+func (conn *conn) Begin() (driver.Tx, error) {
+	return connTx{}, nil
+}
+
+type connTx struct{}
+
+func (connTx) Commit() error   { return nil }
+func (connTx) Rollback() error { return nil }
+
+type connStmt struct {
+	conn  *conn
+	query string
+}
+
+func (stmt *connStmt) Close() error  { return nil }
+func (stmt *connStmt) NumInput() int { return -1 }
+
+func (stmt *connStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return stmt.conn.ExecContext(context.Background(), stmt.query, ordinalValues(args))
+}
+
+func (stmt *connStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return stmt.conn.QueryContext(context.Background(), stmt.query, ordinalValues(args))
+}
+
+func namedValues(args []driver.NamedValue) []any {
+	values := make([]any, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	return values
+}
+
+func ordinalValues(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, 0, len(args))
+	for i, arg := range args {
+		values = append(values, driver.NamedValue{Ordinal: i + 1, Value: arg})
+	}
+	return values
+}
+
+// connRows The rows of the target
+type connRows struct {
+	rows *sql.Rows
+}
+
+func (rows *connRows) Columns() []string {
+	columns, _ := rows.rows.Columns()
+	return columns
+}
+
+func (rows *connRows) Close() error {
+	return rows.rows.Close()
+}
+
+func (rows *connRows) Next(dest []driver.Value) error {
+	if !rows.rows.Next() {
+		if err := rows.rows.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	values := make([]any, len(dest))
+	pointers := make([]any, len(dest))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := rows.rows.Scan(pointers...); err != nil {
+		return err
+	}
+	for i, value := range values {
+		dest[i] = value
+	}
+	return nil
+}
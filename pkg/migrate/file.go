@@ -7,8 +7,9 @@ import (
 	"gorm.io/gorm"
 )
 
-// migrationFunc Define the type of up and down callback methods
-type migrationFunc func(migrator gorm.Migrator, db *sql.DB)
+// migrationFunc Define the type of up and down callback methods, an error stops the batch
+// and rolls the migration back where the database supports transactional DDL
+type migrationFunc func(migrator gorm.Migrator, db *sql.DB) error
 
 // migrationFiles Array of all migration files
 var migrationFiles []MigrationFile
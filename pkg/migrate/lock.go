@@ -0,0 +1,227 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+
+	"gohub/pkg/config"
+	"gohub/pkg/console"
+	"gohub/pkg/database"
+)
+
+// ErrLockTimeout Another migrator holds the lock for longer than database.migration_lock_timeout
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock, another migrator is running or a stale lock is left, see `migrate unlock`")
+
+// ErrLockHeld The lock of PostgreSQL and MySQL belongs to a live connection, the server releases it when
+// the connection ends. Unlock refuses to take it from a migrator that may still be changing the database.
+var ErrLockHeld = errors.New("the migration lock is held by a live connection, stop that migrator or end the connection, the database releases the lock then")
+
+// lockPollInterval How often a lock without a blocking wait is tried again
+const lockPollInterval = 200 * time.Millisecond
+
+// migrationLock Makes sure only one migrator changes the database at a time
+type migrationLock interface {
+	// acquire Wait at most timeout for the lock
+	acquire(ctx context.Context, timeout time.Duration) error
+	// release Give up the lock taken by acquire
+	release() error
+	// clear Remove a lock left behind, ErrLockHeld when a live connection holds it
+	clear(ctx context.Context) error
+}
+
+// newMigrationLock The lock of the database connection
+func newMigrationLock() migrationLock {
+	connection := config.GetString("database.connection")
+	name := "gohub_migrations:" + config.GetString("database."+connection+".database")
+
+	switch connection {
+	case "postgresql":
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(name))
+		return &postgresLock{key: int64(hash.Sum64())}
+	case "mysql":
+		return &mysqlLock{name: name}
+	default:
+		return &fileLock{path: config.GetString("database.sqlite.database") + ".migrate.lock"}
+	}
+}
+
+// withLock Run fn holding the migration lock
+func (migrator *Migrator) withLock(fn func() error) error {
+	ctx := context.Background()
+	timeout := time.Duration(config.GetInt("database.migration_lock_timeout")) * time.Second
+
+	lock := newMigrationLock()
+	if err := lock.acquire(ctx, timeout); err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.release(); err != nil {
+			console.Error("failed to release the migration lock: " + err.Error())
+		}
+	}()
+
+	return fn()
+}
+
+// Unlock Clear the migration lock left by a migrator that did not finish,
+// the lock of a connection that is still open is reported instead
+func (migrator *Migrator) Unlock() error {
+	return newMigrationLock().clear(context.Background())
+}
+
+// waitLock Try the lock until it is taken or the timeout has passed
+func waitLock(ctx context.Context, timeout time.Duration, try func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		ok, err := try()
+		if err != nil || ok {
+			return err
+		}
+		if !time.Now().Before(deadline) {
+			return ErrLockTimeout
+		}
+		if !waiting {
+			console.Warning("waiting for the migration lock held by another migrator...")
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// postgresLock A session level advisory lock, released by the database when the connection is gone
+type postgresLock struct {
+	key  int64
+	conn *sql.Conn
+}
+
+func (lock *postgresLock) acquire(ctx context.Context, timeout time.Duration) error {
+	// The lock belongs to the connection, it has to be kept until release
+	conn, err := database.SQLDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = waitLock(ctx, timeout, func() (ok bool, err error) {
+		err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, lock.key).Scan(&ok)
+		return ok, err
+	})
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	lock.conn = conn
+	return nil
+}
+
+func (lock *postgresLock) release() error {
+	defer lock.conn.Close()
+	_, err := lock.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lock.key)
+	return err
+}
+
+func (lock *postgresLock) clear(ctx context.Context) error {
+	// A bigint key is stored as its high and low 32 bits
+	var pid int64
+	err := database.SQLDB.QueryRowContext(ctx, `SELECT pid FROM pg_locks
+		WHERE locktype = 'advisory' AND granted AND objsubid = 1 AND ((classid::bigint << 32) | objid::bigint) = $1
+		LIMIT 1`, lock.key).Scan(&pid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: backend pid %d, see pg_stat_activity", ErrLockHeld, pid)
+}
+
+// mysqlLock A named lock, released by the server when the connection is gone
+type mysqlLock struct {
+	name string
+	conn *sql.Conn
+}
+
+func (lock *mysqlLock) acquire(ctx context.Context, timeout time.Duration) error {
+	conn, err := database.SQLDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	// GET_LOCK waits itself, 1 when taken, 0 on timeout
+	var result sql.NullInt64
+	err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lock.name, int(timeout.Seconds())).Scan(&result)
+	if err == nil && result.Int64 != 1 {
+		err = ErrLockTimeout
+	}
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	lock.conn = conn
+	return nil
+}
+
+func (lock *mysqlLock) release() error {
+	defer lock.conn.Close()
+	_, err := lock.conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, lock.name)
+	return err
+}
+
+func (lock *mysqlLock) clear(ctx context.Context) error {
+	var connectionID sql.NullInt64
+	if err := database.SQLDB.QueryRowContext(ctx, `SELECT IS_USED_LOCK(?)`, lock.name).Scan(&connectionID); err != nil {
+		return err
+	}
+	if !connectionID.Valid {
+		return nil
+	}
+	return fmt.Errorf("%w: connection id %d, see SHOW PROCESSLIST", ErrLockHeld, connectionID.Int64)
+}
+
+// fileLock A file next to the SQLite database, left behind when the migrator is killed
+type fileLock struct {
+	path string
+}
+
+func (lock *fileLock) acquire(ctx context.Context, timeout time.Duration) error {
+	return waitLock(ctx, timeout, func() (bool, error) {
+		file, err := os.OpenFile(lock.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		defer file.Close()
+
+		// Who holds the lock, for the one clearing a stale lock
+		hostname, _ := os.Hostname()
+		if _, err := fmt.Fprintf(file, "pid %d on %s since %s\n", os.Getpid(), hostname, time.Now().Format(time.RFC3339)); err != nil {
+			_ = os.Remove(lock.path)
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+func (lock *fileLock) release() error {
+	return os.Remove(lock.path)
+}
+
+func (lock *fileLock) clear(context.Context) error {
+	if err := os.Remove(lock.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
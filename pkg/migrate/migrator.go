@@ -2,8 +2,12 @@
 package migrate
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"os"
 
+	"gohub/pkg/config"
 	"gohub/pkg/console"
 	"gohub/pkg/database"
 	"gohub/pkg/file"
@@ -13,9 +17,11 @@ import (
 
 // Migrator Data migration operation class
 type Migrator struct {
-	Folder   string
-	DB       *gorm.DB
-	Migrator gorm.Migrator
+	Folder string
+	// SchemaPath The dump loaded into a database no migration has run on
+	SchemaPath string
+	DB         *gorm.DB
+	Migrator   gorm.Migrator
 }
 
 // Migration A data in the migrations table of the corresponding data
@@ -28,9 +34,10 @@ type Migration struct {
 // NewMigrator Create Migrator instances to perform migration operations
 func NewMigrator() *Migrator {
 	migrator := &Migrator{
-		Folder:   "database/migrations/",
-		DB:       database.DB,
-		Migrator: database.DB.Migrator(),
+		Folder:     "database/migrations/",
+		SchemaPath: schemaPath(),
+		DB:         database.DB,
+		Migrator:   database.DB.Migrator(),
 	}
 	// If migrations does not exist, create it
 	migrator.createMigrationsTable()
@@ -50,111 +57,245 @@ func (migrator *Migrator) createMigrationsTable() {
 	}
 }
 
-// Up Execute all files that have not been migrated
-func (migrator *Migrator) Up() {
-	// Read all migration files to ensure they are sorted by time
-	migrateFiles := migrator.readAllMigrationFiles()
-
-	// Get the current batch value
-	batch := migrator.getBatch()
+// MigrationStatus A registered migration file and whether it has run
+type MigrationStatus struct {
+	Name  string
+	Ran   bool
+	Batch int
+}
 
-	// Get all migration data
+// Status All migration files in order, with the batch of the ones that have run
+func (migrator *Migrator) Status() []MigrationStatus {
 	var migrations []Migration
 	migrator.DB.Find(&migrations)
 
-	// This value can be used to determine if the database is up-to-date
-	runed := false
+	var statuses []MigrationStatus
+	for _, mfile := range migrator.readAllMigrationFiles() {
+		status := MigrationStatus{Name: mfile.FileName}
+		for _, migration := range migrations {
+			if migration.Migration == mfile.FileName {
+				status.Ran = true
+				status.Batch = migration.Batch
+				break
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
 
-	// Iterate over the migration file and execute the up callback if it has not been executed before
-	for _, mfile := range migrateFiles {
-		// Compare file names to determine if they have been run
-		if mfile.isNotMigrated(migrations) {
-			migrator.runUpMigration(mfile, batch)
-			runed = true
+// Up Execute all files that have not been migrated
+func (migrator *Migrator) Up() error {
+	return migrator.UpStep(0)
+}
+
+// UpStep Execute at most step files that have not been migrated, each in a batch of its own,
+// so that they can be rolled back one by one. All files run in one batch when step is 0.
+// Stops at the first failing file.
+func (migrator *Migrator) UpStep(step int) error {
+	return migrator.withLock(func() error {
+		return migrator.upStep(step)
+	})
+}
+
+func (migrator *Migrator) upStep(step int) error {
+	// A new database starts from the dump, only the newer migrations run
+	if _, err := migrator.loadSchema(); err != nil {
+		return err
+	}
+
+	pending := migrator.pendingMigrationFiles(step)
+	if len(pending) == 0 {
+		console.Success("database is up-to-date.")
+		return nil
+	}
+
+	// Get the current batch value
+	batch := migrator.getBatch()
+
+	for _, mfile := range pending {
+		if err := migrator.runUpMigration(mfile, batch); err != nil {
+			return err
+		}
+		if step > 0 {
+			batch++
 		}
 	}
+	return nil
+}
 
-	if !runed {
+// Pretend Print the SQL the files that have not been migrated would run, without running it
+func (migrator *Migrator) Pretend(step int) error {
+	pending := migrator.pendingMigrationFiles(step)
+	if len(pending) == 0 {
 		console.Success("database is up-to-date.")
+		return nil
+	}
+
+	for _, mfile := range pending {
+		if mfile.Up == nil {
+			continue
+		}
+
+		// Schema checks are answered by the database, the changes are only recorded
+		connector := newPretendConnector(database.SQLDB)
+		pretendDB := sql.OpenDB(connector)
+		tx := migrator.DB.Session(&gorm.Session{NewDB: true, Context: context.Background()})
+		tx.Statement.ConnPool = pretendDB
+
+		err := callMigration(mfile.Up, tx.Migrator(), pretendDB)
+		_ = pretendDB.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", mfile.FileName, err)
+		}
+
+		console.Warning(mfile.FileName)
+		for _, statement := range connector.Statements() {
+			fmt.Println(statement + ";")
+		}
 	}
+	return nil
 }
 
 // Rollback of the previous operation
-func (migrator *Migrator) Rollback() {
-	// Get the last batch of migration data
-	lastMigration := Migration{}
-	migrator.DB.Order("id DESC").First(&lastMigration)
+func (migrator *Migrator) Rollback() error {
+	return migrator.RollbackStep(0)
+}
+
+// RollbackStep Roll back the last step migrations whatever their batch,
+// or the whole last batch when step is 0
+func (migrator *Migrator) RollbackStep(step int) error {
+	return migrator.withLock(func() error {
+		return migrator.rollbackStep(step)
+	})
+}
 
+func (migrator *Migrator) rollbackStep(step int) error {
 	var migrations []Migration
-	migrator.DB.Where("batch = ?", lastMigration.Batch).Order("id DESC").Find(&migrations)
+	if step > 0 {
+		migrator.DB.Order("id DESC").Limit(step).Find(&migrations)
+	} else {
+		// Get the last batch of migration data
+		lastMigration := Migration{}
+		migrator.DB.Order("id DESC").First(&lastMigration)
+
+		migrator.DB.Where("batch = ?", lastMigration.Batch).Order("id DESC").Find(&migrations)
+	}
 
 	// Rollback of the last migration
-	if !migrator.rollbackMigrations(migrations) {
+	runed, err := migrator.rollbackMigrations(migrations)
+	if err != nil {
+		return err
+	}
+	if !runed {
 		console.Success("[migrations] table is empty, nothing to rollback.")
 	}
+	return nil
 }
 
 // Fallback migration, execute the down method of migration in reverse order
-func (migrator *Migrator) rollbackMigrations(migrations []Migration) bool {
+func (migrator *Migrator) rollbackMigrations(migrations []Migration) (bool, error) {
 	// Flag whether a migration fallback has actually been performed
 	runed := false
 
 	for _, _migration := range migrations {
 		console.Warning("rollback " + _migration.Migration)
 
-		// Execute the down method of migrating files
+		// Execute the down method of migrating files, and delete the record with it
 		mfile := getMigrationFile(_migration.Migration)
-		if mfile.Down != nil {
-			mfile.Down(database.DB.Migrator(), database.SQLDB)
+		err := migrator.transaction(func(tx *gorm.DB, db *sql.DB) error {
+			if mfile.Down != nil {
+				if err := callMigration(mfile.Down, tx.Migrator(), db); err != nil {
+					return err
+				}
+			}
+			return tx.Delete(&_migration).Error
+		})
+		if err != nil {
+			return runed, fmt.Errorf("%s: %w", _migration.Migration, err)
 		}
 
 		runed = true
 
-		// Delete this record if the rollback is successful
-		migrator.DB.Delete(&_migration)
-
-		console.Success("finish " + mfile.FileName)
+		console.Success("finish " + _migration.Migration)
 	}
 
-	return runed
+	return runed, nil
 }
 
 // Reset all migrations
-func (migrator *Migrator) Reset() {
+func (migrator *Migrator) Reset() error {
+	return migrator.withLock(migrator.reset)
+}
+
+func (migrator *Migrator) reset() error {
 	var migrations []Migration
 
 	// Read all migration files in reverse order
 	migrator.DB.Order("id DESC").Find(&migrations)
 
 	// Rollback of all migrations
-	if !migrator.rollbackMigrations(migrations) {
+	runed, err := migrator.rollbackMigrations(migrations)
+	if err != nil {
+		return err
+	}
+	if !runed {
 		console.Success("[migrations] table is empty, nothing to reset.")
 	}
+	return nil
 }
 
 // Refresh Roll back all migrations and run all migrations
-func (migrator *Migrator) Refresh() {
-	// Rollback of all migrations
-	migrator.Reset()
-	// Run all migrations
-	migrator.Up()
+func (migrator *Migrator) Refresh() error {
+	return migrator.withLock(func() error {
+		// Rollback of all migrations
+		if err := migrator.reset(); err != nil {
+			return err
+		}
+		// Run all migrations
+		return migrator.upStep(0)
+	})
 }
 
 // Fresh Drop all tables and rerun all migrations
-func (migrator *Migrator) Fresh() {
+func (migrator *Migrator) Fresh() error {
+	return migrator.withLock(migrator.fresh)
+}
+
+func (migrator *Migrator) fresh() error {
 	// Get the database name to prompt for
 	dbName := database.CurrentDatabase()
 
 	// Delete all tables
-	err := database.DeleteAllTables()
-	console.ExitIf(err)
+	if err := database.DeleteAllTables(); err != nil {
+		return err
+	}
 	console.Success("clear up database " + dbName)
 
 	// Re-create the migrates table
 	migrator.createMigrationsTable()
 	console.Success("[migrations] table created.")
 
-	migrator.Up()
+	return migrator.upStep(0)
+}
+
+// pendingMigrationFiles The files that have not been migrated in order, at most step of them unless step is 0
+func (migrator *Migrator) pendingMigrationFiles(step int) []MigrationFile {
+	// Get all migration data
+	var migrations []Migration
+	migrator.DB.Find(&migrations)
+
+	var pending []MigrationFile
+	for _, mfile := range migrator.readAllMigrationFiles() {
+		if step > 0 && len(pending) == step {
+			break
+		}
+		// Compare file names to determine if they have been run
+		if mfile.isNotMigrated(migrations) {
+			pending = append(pending, mfile)
+		}
+	}
+	return pending
 }
 
 // Get the current value of this batch
@@ -199,20 +340,61 @@ func (migrator *Migrator) readAllMigrationFiles() []MigrationFile {
 	return migrateFiles
 }
 
-// Execute migration, execute the up method of migration
-func (migrator *Migrator) runUpMigration(mfile MigrationFile, batch int) {
-	// Execute sql for up block
-	if mfile.Up != nil {
-		console.Warning("migrating " + mfile.FileName)
-		// Execute up method
-		mfile.Up(database.DB.Migrator(), database.SQLDB)
-		// Prompts for that file to be migrated
-		console.Success("migrated " + mfile.FileName)
+// Execute migration, execute the up method of migration and record it in one transaction
+func (migrator *Migrator) runUpMigration(mfile MigrationFile, batch int) error {
+	console.Warning("migrating " + mfile.FileName)
+
+	err := migrator.transaction(func(tx *gorm.DB, db *sql.DB) error {
+		// Execute sql for up block
+		if mfile.Up != nil {
+			if err := callMigration(mfile.Up, tx.Migrator(), db); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&Migration{
+			Migration: mfile.FileName,
+			Batch:     batch,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", mfile.FileName, err)
 	}
 
-	err := migrator.DB.Create(&Migration{
-		Migration: mfile.FileName,
-		Batch:     batch,
-	}).Error
-	console.ExitIf(err)
+	// Prompts for that file to be migrated
+	console.Success("migrated " + mfile.FileName)
+	return nil
+}
+
+// transaction Run fn in a transaction where the database can roll back schema changes,
+// MySQL commits implicitly on DDL, so fn runs without one there
+func (migrator *Migrator) transaction(fn func(tx *gorm.DB, db *sql.DB) error) error {
+	switch config.Get("database.connection") {
+	case "sqlite", "postgresql":
+	default:
+		return fn(migrator.DB, database.SQLDB)
+	}
+
+	return migrator.DB.Transaction(func(tx *gorm.DB) error {
+		sqlTx, ok := tx.Statement.ConnPool.(*sql.Tx)
+		if !ok {
+			return fn(tx, database.SQLDB)
+		}
+
+		// The raw statements of the migration run on the transaction as well
+		db := sql.OpenDB(newTxConnector(sqlTx))
+		defer db.Close()
+		return fn(tx, db)
+	})
+}
+
+// callMigration Run the up or down callback, a panic of the callback is returned as an error
+func callMigration(fn migrationFunc, migrator gorm.Migrator, db *sql.DB) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return fn(migrator, db)
 }
@@ -0,0 +1,237 @@
+package migrate
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gohub/pkg/config"
+	"gohub/pkg/console"
+	"gohub/pkg/database"
+	"gorm.io/gorm"
+)
+
+// schemaPath The dump of the database connection, database/schema/sqlite-schema.sql for example
+func schemaPath() string {
+	return filepath.Join("database", "schema", config.GetString("database.connection")+"-schema.sql")
+}
+
+// Dump Write the schema of the database and the migrations that have run to the schema file.
+// A new environment loads it instead of replaying every migration, see loadSchema.
+func (migrator *Migrator) Dump() error {
+	var statements []string
+	var err error
+	switch config.Get("database.connection") {
+	case "mysql":
+		statements, err = dumpMySQLSchema()
+	case "postgresql":
+		statements, err = dumpPostgresQLSchema()
+	default:
+		statements, err = dumpSqliteSchema()
+	}
+	if err != nil {
+		return err
+	}
+
+	var migrations []Migration
+	if err := migrator.DB.Order("id").Find(&migrations).Error; err != nil {
+		return err
+	}
+	tableName := database.TableName(&Migration{})
+	for _, migration := range migrations {
+		statements = append(statements, fmt.Sprintf("INSERT INTO %s (migration, batch) VALUES ('%s', %d);",
+			tableName, strings.ReplaceAll(migration.Migration, "'", "''"), migration.Batch))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "-- Schema of %s with %d migrations, dumped at %s\n",
+		database.CurrentDatabase(), len(migrations), time.Now().Format(time.RFC3339))
+	fmt.Fprintln(&buf, "-- Loaded by `migrate up` and `migrate fresh` when the migrations table is empty")
+	for _, statement := range statements {
+		fmt.Fprintf(&buf, "\n%s\n", statement)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(migrator.SchemaPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(migrator.SchemaPath, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	console.Success(fmt.Sprintf("schema dumped to %s with %d migrations.", migrator.SchemaPath, len(migrations)))
+	return nil
+}
+
+// loadSchema Load the schema file into a database no migration has run on,
+// the migrations recorded in it are not run again. Returns false without a schema file.
+func (migrator *Migrator) loadSchema() (bool, error) {
+	if migrator.SchemaPath == "" {
+		return false, nil
+	}
+	var count int64
+	if err := migrator.DB.Model(&Migration{}).Count(&count).Error; err != nil || count > 0 {
+		return false, err
+	}
+	content, err := os.ReadFile(migrator.SchemaPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	console.Warning("loading schema " + migrator.SchemaPath)
+	err = migrator.transaction(func(tx *gorm.DB, db *sql.DB) error {
+		for _, statement := range splitStatements(content) {
+			if _, err := db.Exec(statement); err != nil {
+				return fmt.Errorf("%w\n%s", err, statement)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", migrator.SchemaPath, err)
+	}
+	console.Success("loaded schema " + migrator.SchemaPath)
+	return true, nil
+}
+
+// splitStatements The statements of a dump, each one ends with a line ending in a semicolon.
+// Comments and psql meta-commands are skipped.
+func splitStatements(content []byte) []string {
+	var statements []string
+	var statement strings.Builder
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if statement.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--") || strings.HasPrefix(trimmed, `\`)) {
+			continue
+		}
+
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(statement.String()))
+			statement.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(statement.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+func dumpSqliteSchema() ([]string, error) {
+	type object struct {
+		Type    string
+		Name    string
+		TblName string
+		SQL     string
+	}
+	var objects []object
+	// Tables first, in the order they were created, then their indexes
+	err := database.DB.Raw(`SELECT type, name, tbl_name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		ORDER BY CASE type WHEN 'table' THEN 0 ELSE 1 END, rowid`).Scan(&objects).Error
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsTable := database.TableName(&Migration{})
+	var virtualTables []string
+	var statements []string
+	for _, obj := range objects {
+		// The migrations table is created by the migrator
+		if obj.TblName == migrationsTable {
+			continue
+		}
+		// Virtual tables create their shadow tables themselves
+		if obj.Type == "table" && hasShadowPrefix(obj.Name, virtualTables) {
+			continue
+		}
+		if strings.HasPrefix(strings.ToUpper(obj.SQL), "CREATE VIRTUAL TABLE") {
+			virtualTables = append(virtualTables, obj.Name)
+		}
+		statements = append(statements, obj.SQL+";")
+	}
+	return statements, nil
+}
+
+// hasShadowPrefix topics_fts_content is a shadow table of the virtual table topics_fts
+func hasShadowPrefix(name string, virtualTables []string) bool {
+	for _, table := range virtualTables {
+		if strings.HasPrefix(name, table+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// autoIncrement The counter of the dumped database is not part of the schema
+var autoIncrement = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+func dumpMySQLSchema() ([]string, error) {
+	var tables []string
+	err := database.DB.Table("information_schema.tables").
+		Where("table_schema = ? AND table_type = ?", database.CurrentDatabase(), "BASE TABLE").
+		Order("table_name").
+		Pluck("table_name", &tables).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	// The tables are created in name order, foreign keys are checked afterwards
+	statements := []string{"SET foreign_key_checks = 0;"}
+	migrationsTable := database.TableName(&Migration{})
+	for _, table := range tables {
+		if table == migrationsTable {
+			continue
+		}
+		var name, createSQL string
+		if err := database.SQLDB.QueryRow("SHOW CREATE TABLE `"+table+"`").Scan(&name, &createSQL); err != nil {
+			return nil, err
+		}
+		statements = append(statements, autoIncrement.ReplaceAllString(createSQL, "")+";")
+	}
+	return append(statements, "SET foreign_key_checks = 1;"), nil
+}
+
+func dumpPostgresQLSchema() ([]string, error) {
+	migrationsTable := database.TableName(&Migration{})
+	cmd := exec.Command("pg_dump",
+		"--schema-only", "--no-owner", "--no-privileges", "--no-comments",
+		"--exclude-table="+migrationsTable,
+		"--host="+config.GetString("database.postgresql.host"),
+		"--port="+config.GetString("database.postgresql.port"),
+		"--username="+config.GetString("database.postgresql.username"),
+		config.GetString("database.postgresql.database"),
+	)
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+config.GetString("database.postgresql.password"))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("pg_dump: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var statements []string
+	for _, statement := range splitStatements(out) {
+		// An empty search_path would stay on the pooled connection loading the dump
+		if strings.Contains(statement, "set_config('search_path'") {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
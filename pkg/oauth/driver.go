@@ -0,0 +1,68 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Driver interface {
+	// User The account the access token belongs to
+	User(ctx context.Context, client *http.Client, token string, config map[string]string) (User, error)
+}
+
+// Error The provider refused the request, such as an expired code
+type Error struct {
+	Provider string
+	// Status HTTP status of the response
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s %s (HTTP %d)", e.Provider, e.Code, e.Message, e.Status)
+}
+
+// defaultClient Used for the providers without a Client of their own
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// get Request url with the access token and decode the JSON response into result
+func get(ctx context.Context, client *http.Client, provider, url, token string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	return do(client, provider, req, result)
+}
+
+// do Send the request and decode the JSON response into result,
+// responses with an error status are returned as *Error with the body as message
+func do(client *http.Client, provider string, req *http.Request, result any) error {
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &Error{Provider: provider, Status: resp.StatusCode, Code: resp.Status, Message: strings.TrimSpace(string(body))}
+	}
+	if err = json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("%s: decode response: %w", provider, err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// GitHub https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/authorizing-oauth-apps
+type GitHub struct{}
+
+func (g *GitHub) User(ctx context.Context, client *http.Client, token string, config map[string]string) (User, error) {
+	var profile struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := get(ctx, client, "github", config["user_url"], token, &profile); err != nil {
+		return User{}, err
+	}
+
+	user := User{Name: profile.Login, Avatar: profile.AvatarURL}
+	if profile.ID > 0 {
+		user.ID = strconv.FormatInt(profile.ID, 10)
+	}
+
+	// The public email of the profile may be empty or unverified, the primary one is asked for separately
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := get(ctx, client, "github", config["emails_url"], token, &emails); err != nil {
+		return User{}, err
+	}
+	for _, email := range emails {
+		if email.Primary {
+			user.Email, user.EmailVerified = email.Email, email.Verified
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,30 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+)
+
+// Google https://developers.google.com/identity/protocols/oauth2/web-server
+type Google struct{}
+
+func (g *Google) User(ctx context.Context, client *http.Client, token string, config map[string]string) (User, error) {
+	var profile struct {
+		Sub           string `json:"sub"`
+		Name          string `json:"name"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Picture       string `json:"picture"`
+	}
+	if err := get(ctx, client, "google", config["user_url"], token, &profile); err != nil {
+		return User{}, err
+	}
+
+	return User{
+		ID:            profile.Sub,
+		Name:          profile.Name,
+		Email:         profile.Email,
+		EmailVerified: profile.EmailVerified,
+		Avatar:        profile.Picture,
+	}, nil
+}
@@ -0,0 +1,118 @@
+// Package oauth Sign in with OAuth2 providers such as GitHub and Google
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gohub/pkg/config"
+)
+
+// User The account of the user at the provider
+type User struct {
+	// ID The ID at the provider, unique per provider
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Avatar string `json:"avatar"`
+	// EmailVerified Whether the provider verified the user owns Email
+	EmailVerified bool `json:"email_verified"`
+}
+
+// Provider An OAuth2 provider set up in config/oauth.go
+type Provider struct {
+	Name   string
+	Driver Driver
+	// Config client_id, client_secret, auth_url, token_url, user_url, scopes, see config/oauth.go
+	Config map[string]string
+	// Client Used for the requests to the provider, a default one when nil
+	Client *http.Client
+}
+
+// ErrUnknownProvider The provider is not supported, or has no client_id
+var ErrUnknownProvider = errors.New("unknown OAuth provider")
+
+// drivers The providers that can be set up
+var drivers = map[string]func() Driver{
+	"github": func() Driver { return &GitHub{} },
+	"google": func() Driver { return &Google{} },
+}
+
+// NewProvider The provider of name, configured by oauth.<name>
+func NewProvider(name string) (*Provider, error) {
+	driver, ok := drivers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	providerConfig := config.GetStringMapString("oauth." + name)
+	if providerConfig["client_id"] == "" {
+		return nil, ErrUnknownProvider
+	}
+
+	return &Provider{Name: name, Driver: driver(), Config: providerConfig}, nil
+}
+
+// AuthURL The page of the provider asking the user to sign in, it redirects back to redirectURL
+// with the code and the state, which has to be checked by the callback
+func (p *Provider) AuthURL(state, redirectURL string) string {
+	query := url.Values{
+		"client_id":     {p.Config["client_id"]},
+		"redirect_uri":  {redirectURL},
+		"response_type": {"code"},
+		"scope":         {p.Config["scopes"]},
+		"state":         {state},
+	}
+
+	separator := "?"
+	if strings.Contains(p.Config["auth_url"], "?") {
+		separator = "&"
+	}
+	return p.Config["auth_url"] + separator + query.Encode()
+}
+
+// Exchange Exchange the code of the callback for an access token
+func (p *Provider) Exchange(ctx context.Context, code, redirectURL string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"client_id":     {p.Config["client_id"]},
+		"client_secret": {p.Config["client_secret"]},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Config["token_url"], strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = do(p.Client, p.Name, req, &result); err != nil {
+		return "", err
+	}
+	// GitHub answers errors with 200
+	if result.Error != "" || result.AccessToken == "" {
+		return "", &Error{Provider: p.Name, Status: http.StatusOK, Code: result.Error, Message: result.ErrorDescription}
+	}
+	return result.AccessToken, nil
+}
+
+// User The account the access token belongs to
+func (p *Provider) User(ctx context.Context, token string) (User, error) {
+	user, err := p.Driver.User(ctx, p.Client, token, p.Config)
+	if err != nil {
+		return User{}, err
+	}
+	if user.ID == "" {
+		return User{}, fmt.Errorf("%s: no user ID in the response", p.Name)
+	}
+	return user, nil
+}
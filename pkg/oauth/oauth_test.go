@@ -0,0 +1,111 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider Answers like GitHub and Google do, accepting the code "good-code"
+func fakeProvider(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		assert.Equal(t, "secret", r.PostForm.Get("client_secret"))
+		assert.Equal(t, "http://localhost/callback", r.PostForm.Get("redirect_uri"))
+		if r.PostForm.Get("code") != "good-code" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code", "error_description": "The code is incorrect"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token", "token_type": "bearer"})
+	})
+	authorized := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+		}
+	}
+	mux.HandleFunc("/github/user", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 42, "login": "octocat", "name": "The Octocat", "avatar_url": "https://example.com/a.png"})
+	}))
+	mux.HandleFunc("/github/emails", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"email": "other@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	}))
+	mux.HandleFunc("/google/userinfo", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"sub": "1234", "name": "Jane Doe", "email": "jane@example.com", "email_verified": false})
+	}))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProviderGitHub(t *testing.T) {
+	server := fakeProvider(t)
+	provider := &Provider{Name: "github", Driver: &GitHub{}, Config: map[string]string{
+		"client_id":     "client",
+		"client_secret": "secret",
+		"scopes":        "read:user user:email",
+		"auth_url":      server.URL + "/authorize",
+		"token_url":     server.URL + "/token",
+		"user_url":      server.URL + "/github/user",
+		"emails_url":    server.URL + "/github/emails",
+	}}
+
+	authURL, err := url.Parse(provider.AuthURL("the-state", "http://localhost/callback"))
+	require.NoError(t, err)
+	require.Equal(t, "/authorize", authURL.Path)
+	require.Equal(t, "client", authURL.Query().Get("client_id"))
+	require.Equal(t, "the-state", authURL.Query().Get("state"))
+	require.Equal(t, "read:user user:email", authURL.Query().Get("scope"))
+	require.Equal(t, "http://localhost/callback", authURL.Query().Get("redirect_uri"))
+
+	token, err := provider.Exchange(context.Background(), "good-code", "http://localhost/callback")
+	require.NoError(t, err)
+
+	user, err := provider.User(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, User{ID: "42", Name: "octocat", Email: "octocat@example.com", EmailVerified: true, Avatar: "https://example.com/a.png"}, user)
+
+	// GitHub answers a wrong code with 200 and an error
+	_, err = provider.Exchange(context.Background(), "bad-code", "http://localhost/callback")
+	var providerErr *Error
+	require.ErrorAs(t, err, &providerErr)
+	require.Equal(t, "bad_verification_code", providerErr.Code)
+
+	_, err = provider.User(context.Background(), "wrong-token")
+	require.ErrorAs(t, err, &providerErr)
+	require.Equal(t, http.StatusUnauthorized, providerErr.Status)
+}
+
+func TestProviderGoogle(t *testing.T) {
+	server := fakeProvider(t)
+	provider := &Provider{Name: "google", Driver: &Google{}, Config: map[string]string{
+		"client_id":     "client",
+		"client_secret": "secret",
+		"token_url":     server.URL + "/token",
+		"user_url":      server.URL + "/google/userinfo",
+	}}
+
+	token, err := provider.Exchange(context.Background(), "good-code", "http://localhost/callback")
+	require.NoError(t, err)
+
+	user, err := provider.User(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, User{ID: "1234", Name: "Jane Doe", Email: "jane@example.com"}, user)
+}
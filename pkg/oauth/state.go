@@ -0,0 +1,90 @@
+package oauth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gohub/pkg/app"
+	"gohub/pkg/cache"
+	"gohub/pkg/config"
+	"gohub/pkg/helpers"
+)
+
+// nonceCookie Binds a state to the browser that started the redirect, a state URL opened
+// in another browser, such as a link URL an attacker sends to a victim, is refused
+const nonceCookie = "oauth_nonce"
+
+// nonceCookiePath The cookie is only sent to the callbacks, under the prefix of the API routes
+func nonceCookiePath() string {
+	return app.APIPrefix() + "/auth/oauth"
+}
+
+// State What a redirect to the provider was for, kept until the callback
+type State struct {
+	Provider string `json:"provider"`
+	// UserID The user linking the account, empty when signing in
+	UserID string `json:"user_id"`
+	// Nonce The value of the nonce cookie set on the client that started the redirect
+	Nonce string `json:"nonce"`
+}
+
+// NewState A random state for a redirect, valid for oauth.state_expire minutes and only once,
+// bound to the client of c with the nonce cookie
+func NewState(c *gin.Context, state State) string {
+	expire := time.Minute * time.Duration(config.GetInt("oauth.state_expire"))
+
+	state.Nonce = helpers.SecureRandomString(32)
+	value := helpers.SecureRandomString(32)
+	cache.Set(stateKey(value), state, expire)
+
+	setNonceCookie(c, state.Nonce, int(expire.Seconds()))
+	return value
+}
+
+// PullState The state of a callback, false when it is unknown, expired, used already
+// or was started by another client
+func PullState(c *gin.Context, value string) (state State, ok bool) {
+	if value == "" {
+		return State{}, false
+	}
+	// Consumed atomically, of parallel callbacks with the same state only one gets it
+	if !cache.PullObject(stateKey(value), &state) {
+		return State{}, false
+	}
+	setNonceCookie(c, "", -1)
+
+	nonce, err := c.Cookie(nonceCookie)
+	if err != nil || state.Nonce == "" || subtle.ConstantTimeCompare([]byte(nonce), []byte(state.Nonce)) != 1 {
+		return State{}, false
+	}
+	return state, state.Provider != ""
+}
+
+func stateKey(value string) string {
+	return "oauth:state:" + value
+}
+
+func setNonceCookie(c *gin.Context, nonce string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     nonceCookie,
+		Value:    nonce,
+		Path:     nonceCookiePath(),
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(config.GetString("app.url"), "https://"),
+		// Sent along with the top-level redirect back from the provider
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// CallbackURL Where the provider redirects back to, oauth.<provider>.redirect_url
+// or the callback route of the API, see app.APIURL
+func CallbackURL(provider string) string {
+	if url := config.GetString("oauth." + provider + ".redirect_url"); url != "" {
+		return url
+	}
+	return app.APIURL("/auth/oauth/" + provider + "/callback")
+}
@@ -0,0 +1,262 @@
+package paginator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"gohub/pkg/config"
+	"gohub/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Cursor Position of a row in a keyset ordering: its values of the sort columns, id last
+type Cursor struct {
+	Values []json.RawMessage `json:"v"`
+
+	// Points backwards, i.e. the page before this row
+	Prev bool `json:"p,omitempty"`
+}
+
+// EncodeCursor Opaque representation used in the URL
+func EncodeCursor(cur Cursor) string {
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor Reverse of EncodeCursor
+func DecodeCursor(encoded string) (cur Cursor, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cur, err
+	}
+	if err = json.Unmarshal(data, &cur); err != nil {
+		return cur, err
+	}
+	if len(cur.Values) == 0 {
+		return cur, errors.New("paginator: incomplete cursor")
+	}
+	return cur, nil
+}
+
+// CursorPaginate Keyset pagination
+// Instead of OFFSET, the page starts right after (or before) the row in the cursor,
+// so rows inserted meanwhile neither shift nor repeat pages.
+// Example: ?limit=20&sort=-created_at&cursor=eyJ2Ijo...&with_total=1
+//
+// Arguments are the same as Paginate, the model of db must have an id primary key,
+// which is appended to the sort columns to break ties.
+// The total count is only queried when the with_total parameter is true.
+// NULL values of a sort column are listed after the others in both directions.
+func CursorPaginate(ctx context.Context, c *gin.Context, db *gorm.DB, data any, limit int) Paging {
+	p := &Paginator{
+		query: db,
+		ctx:   c,
+	}
+	p.Limit = p.getLimit(limit)
+	p.initSort(data)
+
+	paging := Paging{Limit: p.Limit, Keyset: true}
+	if cast.ToBool(c.Query(config.Get("paging.url_query_with_total"))) {
+		paging.Total = p.getTotalCount()
+		paging.Counted = true
+	}
+
+	k, err := newKeyset(p, data)
+	if err != nil {
+		logger.LogIf(err)
+		return paging
+	}
+
+	var cur *Cursor
+	if encoded := c.Query(config.Get("paging.url_query_cursor")); encoded != "" {
+		decoded, err := DecodeCursor(encoded)
+		if err != nil {
+			logger.LogIf(err)
+		} else {
+			cur = &decoded
+		}
+	}
+	backward := cur != nil && cur.Prev
+
+	query := p.query.Preload(clause.Associations)
+	if cur != nil {
+		condition, err := k.after(*cur)
+		if err != nil {
+			logger.LogIf(err)
+			return paging
+		}
+		query = query.Where(condition)
+	}
+
+	// Read one more row to know whether there is a page after this one
+	err = k.order(query, backward).Limit(p.Limit + 1).Find(data).Error
+	if err != nil {
+		logger.LogIf(err)
+		return paging
+	}
+
+	rows := reflect.ValueOf(data).Elem()
+	more := rows.Len() > p.Limit
+	if more {
+		rows.SetLen(p.Limit)
+	}
+	if backward {
+		reverse(rows)
+	}
+	if rows.Len() == 0 {
+		return paging
+	}
+
+	if more || backward {
+		paging.NextCursor = k.cursor(ctx, rows.Index(rows.Len()-1), false)
+	}
+	if (cur != nil && !backward) || (backward && more) {
+		paging.PrevCursor = k.cursor(ctx, rows.Index(0), true)
+	}
+
+	return paging
+}
+
+// keyset Ordering by the sort columns, with id as the tie-breaker
+type keyset []keysetColumn
+
+type keysetColumn struct {
+	field    *schema.Field
+	column   clause.Column
+	desc     bool
+	nullable bool
+}
+
+func newKeyset(p *Paginator, data any) (keyset, error) {
+	model := p.query.Statement.Model
+	if model == nil {
+		model = data
+	}
+	stmt := &gorm.Statement{DB: p.query}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+
+	terms := p.Sort
+	if !slices.ContainsFunc(terms, func(term SortTerm) bool { return term.Field == "id" }) {
+		terms = append(slices.Clone(terms), SortTerm{Field: "id"})
+	}
+
+	k := make(keyset, 0, len(terms))
+	for _, term := range terms {
+		field := stmt.Schema.LookUpField(term.Field)
+		if field == nil {
+			return nil, fmt.Errorf("paginator: unknown column %s", term.Field)
+		}
+		k = append(k, keysetColumn{
+			field:    field,
+			column:   clause.Column{Table: stmt.Schema.Table, Name: field.DBName},
+			desc:     term.Desc,
+			nullable: field.FieldType.Kind() == reflect.Pointer,
+		})
+	}
+	return k, nil
+}
+
+func (k keyset) order(query *gorm.DB, backward bool) *gorm.DB {
+	var columns []clause.OrderByColumn
+	for _, col := range k {
+		if col.nullable {
+			columns = append(columns, clause.OrderByColumn{
+				Column: clause.Column{Name: query.Statement.Quote(col.column) + " IS NULL", Raw: true},
+				Desc:   backward,
+			})
+		}
+		// Walking backwards is the forward order turned around
+		columns = append(columns, clause.OrderByColumn{Column: col.column, Desc: col.desc != backward})
+	}
+
+	return query.Order(clause.OrderBy{Columns: columns})
+}
+
+// after Condition selecting the rows that follow the cursor in its direction:
+// equal on the first i columns and past the cursor on column i, for any i
+func (k keyset) after(cur Cursor) (clause.Expression, error) {
+	if len(cur.Values) != len(k) {
+		return nil, errors.New("paginator: the cursor does not match the sort columns")
+	}
+
+	var (
+		alternatives []clause.Expression
+		equal        []clause.Expression
+	)
+	for i, col := range k {
+		value := reflect.New(col.field.FieldType)
+		if err := json.Unmarshal(cur.Values[i], value.Interface()); err != nil {
+			return nil, err
+		}
+
+		if past := col.past(value.Elem(), cur.Prev); past != nil {
+			alternatives = append(alternatives, clause.And(append(slices.Clone(equal), past)...))
+		}
+		equal = append(equal, col.equal(value.Elem()))
+	}
+
+	return clause.Or(alternatives...), nil
+}
+
+// past Rows beyond value on this column, nil if there are none
+func (col keysetColumn) past(value reflect.Value, prev bool) clause.Expression {
+	isNull := col.nullable && value.IsNil()
+	switch {
+	case isNull && prev:
+		// Every non-NULL value comes before NULL
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []any{col.column}}
+	case isNull:
+		return nil
+	}
+
+	op := ">"
+	if col.desc != prev {
+		op = "<"
+	}
+	beyond := clause.Expr{SQL: "? " + op + " ?", Vars: []any{col.column, value.Interface()}}
+	if col.nullable && !prev {
+		return clause.Or(beyond, clause.Expr{SQL: "? IS NULL", Vars: []any{col.column}})
+	}
+	return beyond
+}
+
+func (col keysetColumn) equal(value reflect.Value) clause.Expression {
+	if col.nullable && value.IsNil() {
+		return clause.Expr{SQL: "? IS NULL", Vars: []any{col.column}}
+	}
+	return clause.Eq{Column: col.column, Value: value.Interface()}
+}
+
+// cursor Encode the position of row
+func (k keyset) cursor(ctx context.Context, row reflect.Value, prev bool) string {
+	row = reflect.Indirect(row)
+	cur := Cursor{Prev: prev}
+	for _, col := range k {
+		value, _ := col.field.ValueOf(ctx, row)
+		encoded, _ := json.Marshal(value)
+		cur.Values = append(cur.Values, encoded)
+	}
+	return EncodeCursor(cur)
+}
+
+func reverse(rows reflect.Value) {
+	items := make([]reflect.Value, rows.Len())
+	for i := range items {
+		items[i] = reflect.ValueOf(rows.Index(i).Interface())
+	}
+	slices.Reverse(items)
+	for i, item := range items {
+		rows.Index(i).Set(item)
+	}
+}
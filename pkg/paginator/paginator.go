@@ -15,25 +15,35 @@ import (
 // Paging Data
 // Offset-based pagination
 // Example: {"offset": 0, "limit": 20, "total": 200}
+// Keyset pagination, see CursorPaginate
+// Example: {"limit": 20, "next_cursor": "eyJ2Ijo...", "prev_cursor": null}
 type Paging struct {
 	Offset int   `json:"offset"`
 	Limit  int   `json:"limit"`
 	Total  int64 `json:"total"`
+
+	// Set by CursorPaginate, empty when there is no page in that direction
+	Keyset     bool   `json:"-"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+
+	// Whether Total was counted, keyset pagination only counts on demand
+	Counted bool `json:"-"`
 }
 
 // Paginator Page operation class
 // Offset-based pagination
 // Use offset/limit in the URL query for pagination
-// Example: ?offset=0&limit=20&sort=id&order=asc
+// Example: ?offset=0&limit=20&sort=-created_at,id&order=asc
 type Paginator struct {
 	Limit      int
 	Offset     int
 	TotalCount int64
-	Sort       string
-	Order      string
+	Sort       []SortTerm
 
 	query *gorm.DB
 	ctx   *gin.Context
+	table string
 }
 
 // Paginate
@@ -60,11 +70,11 @@ func Paginate(_ context.Context, c *gin.Context, db *gorm.DB, data any, limit in
 		query: db,
 		ctx:   c,
 	}
-	p.initProperties(limit)
+	p.initProperties(limit, data)
 
 	// Query database
 	err := p.query.Preload(clause.Associations). // Read Associations
-							Order(p.Sort + " " + p.Order). // Sort
+							Order(orderBy(p.table, p.Sort)). // Sort
 							Limit(p.Limit).
 							Offset(p.Offset).
 							Find(data).
@@ -75,24 +85,49 @@ func Paginate(_ context.Context, c *gin.Context, db *gorm.DB, data any, limit in
 	}
 
 	return Paging{
-		Offset: p.Offset,
-		Limit:  p.Limit,
-		Total:  p.TotalCount,
+		Offset:  p.Offset,
+		Limit:   p.Limit,
+		Total:   p.TotalCount,
+		Counted: true,
 	}
 }
 
 // The properties that must be used to initialize paging and query the database based on these properties
-func (p *Paginator) initProperties(limit int) {
+func (p *Paginator) initProperties(limit int, data any) {
 	p.Limit = p.getLimit(limit)
 	p.Offset = p.getOffset()
-
-	// Sort parameters
-	p.Order = p.ctx.DefaultQuery(config.Get("paging.url_query_order"), "asc")
-	p.Sort = p.ctx.DefaultQuery(config.Get("paging.url_query_sort"), "id")
+	p.initSort(data)
 
 	p.TotalCount = p.getTotalCount()
 }
 
+// initSort Read the sort parameters, limited to the sortable fields of the model
+// Requests are expected to reject invalid ones with ParseSort, here they fall back to id
+func (p *Paginator) initSort(data any) {
+	model := p.query.Statement.Model
+	if model == nil {
+		model = data
+	}
+
+	stmt := &gorm.Statement{DB: p.query}
+	if err := stmt.Parse(model); err != nil {
+		logger.LogIf(err)
+	} else {
+		p.table = stmt.Schema.Table
+	}
+
+	sort, err := ParseSort(
+		ExpandSort(p.ctx.Query(config.Get("paging.url_query_sort")), SortAliasesOf(model)),
+		p.ctx.Query(config.Get("paging.url_query_order")),
+		SortableFieldsOf(model),
+	)
+	if err != nil {
+		logger.LogIf(err)
+		sort = []SortTerm{{Field: "id"}}
+	}
+	p.Sort = sort
+}
+
 func (p *Paginator) getLimit(limit int) int {
 	// Preferred use of limit parameter
 	queryLimit := p.ctx.Query(config.Get("paging.url_query_limit"))
@@ -1,6 +1,7 @@
 package paginator
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -79,3 +80,65 @@ func TestGetOffsetNegative(t *testing.T) {
 	p := &Paginator{ctx: c}
 	require.Equal(t, 0, p.getOffset())
 }
+
+func TestCursorRoundTrip(t *testing.T) {
+	encoded := EncodeCursor(Cursor{Values: []json.RawMessage{[]byte(`"2026-01-01T00:00:00Z"`), []byte(`42`)}, Prev: true})
+
+	cur, err := DecodeCursor(encoded)
+	require.NoError(t, err)
+	require.Len(t, cur.Values, 2)
+	require.Equal(t, `"2026-01-01T00:00:00Z"`, string(cur.Values[0]))
+	require.Equal(t, `42`, string(cur.Values[1]))
+	require.True(t, cur.Prev)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	_, err := DecodeCursor("not-a-cursor")
+	require.Error(t, err)
+
+	_, err = DecodeCursor(EncodeCursor(Cursor{}))
+	require.Error(t, err)
+}
+
+type sortableModel struct{}
+
+func (m *sortableModel) SortableFields() []string {
+	return []string{"id", "created_at", "name"}
+}
+
+func (m *sortableModel) SortAliases() map[string]string {
+	return map[string]string{"newest": "-created_at"}
+}
+
+func TestSortableFieldsOf(t *testing.T) {
+	require.Equal(t, []string{"id", "created_at", "name"}, SortableFieldsOf(&[]sortableModel{}))
+	require.Equal(t, DefaultSortableFields, SortableFieldsOf(struct{}{}))
+}
+
+func TestParseSort(t *testing.T) {
+	sortable := []string{"id", "created_at", "name"}
+
+	terms, err := ParseSort("-created_at,id", "asc", sortable)
+	require.NoError(t, err)
+	require.Equal(t, []SortTerm{{Field: "created_at", Desc: true}, {Field: "id"}}, terms)
+
+	terms, err = ParseSort("name", "desc", sortable)
+	require.NoError(t, err)
+	require.Equal(t, []SortTerm{{Field: "name", Desc: true}}, terms)
+
+	terms, err = ParseSort("", "", sortable)
+	require.NoError(t, err)
+	require.Equal(t, []SortTerm{{Field: "id"}}, terms)
+
+	for _, sort := range []string{"password", "id;drop table users", "id,", "name,-name", "--id"} {
+		_, err = ParseSort(sort, "asc", sortable)
+		require.Error(t, err, sort)
+	}
+}
+
+func TestExpandSort(t *testing.T) {
+	aliases := SortAliasesOf(&sortableModel{})
+	require.Equal(t, "-created_at", ExpandSort("newest", aliases))
+	require.Equal(t, "name", ExpandSort("name", aliases))
+	require.Equal(t, "newest", ExpandSort("newest", SortAliasesOf(struct{}{})))
+}
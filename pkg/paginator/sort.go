@@ -0,0 +1,105 @@
+package paginator
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// Sortable Models declare the columns clients are allowed to sort by
+type Sortable interface {
+	SortableFields() []string
+}
+
+// SortAliased Models can name a sort order, e.g. "hot" standing for "-hot_score"
+type SortAliased interface {
+	SortAliases() map[string]string
+}
+
+// DefaultSortableFields For models that do not implement Sortable
+var DefaultSortableFields = []string{"id"}
+
+// SortableFieldsOf The sortable columns of model, a pointer or a slice of models works as well
+func SortableFieldsOf(model any) []string {
+	if sortable, ok := newModel(model).(Sortable); ok {
+		return sortable.SortableFields()
+	}
+	return DefaultSortableFields
+}
+
+// SortAliasesOf The named sort orders of model, nil when it has none
+func SortAliasesOf(model any) map[string]string {
+	if aliased, ok := newModel(model).(SortAliased); ok {
+		return aliased.SortAliases()
+	}
+	return nil
+}
+
+// newModel A pointer to a new instance of the model type behind model
+func newModel(model any) any {
+	typ := reflect.TypeOf(model)
+	for typ != nil && (typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice) {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return nil
+	}
+	return reflect.New(typ).Interface()
+}
+
+// ExpandSort Replace a sort alias by the sort it stands for, other values are returned as is
+func ExpandSort(sort string, aliases map[string]string) string {
+	if expanded, ok := aliases[strings.TrimSpace(sort)]; ok {
+		return expanded
+	}
+	return sort
+}
+
+// SortTerm One column of the sort parameter
+type SortTerm struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort Parse the sort parameter, e.g. "-created_at,id"
+// A "-" prefix sorts that column in descending order,
+// the other columns use order, which is "asc" or "desc".
+func ParseSort(sort, order string, sortable []string) ([]SortTerm, error) {
+	if sort == "" {
+		sort = "id"
+	}
+
+	var terms []SortTerm
+	for _, field := range strings.Split(sort, ",") {
+		term := SortTerm{Field: strings.TrimSpace(field), Desc: order == "desc"}
+		if strings.HasPrefix(term.Field, "-") {
+			term.Field = term.Field[1:]
+			term.Desc = true
+		}
+
+		if !slices.Contains(sortable, term.Field) {
+			return nil, fmt.Errorf("sort fields only support %s", strings.Join(sortable, ", "))
+		}
+		if slices.ContainsFunc(terms, func(t SortTerm) bool { return t.Field == term.Field }) {
+			return nil, fmt.Errorf("sort field %s is given more than once", term.Field)
+		}
+		terms = append(terms, term)
+	}
+
+	return terms, nil
+}
+
+// orderBy ORDER BY clause of terms, with the columns qualified by table
+func orderBy(table string, terms []SortTerm) clause.OrderBy {
+	columns := make([]clause.OrderByColumn, 0, len(terms))
+	for _, term := range terms {
+		columns = append(columns, clause.OrderByColumn{
+			Column: clause.Column{Table: table, Name: term.Field},
+			Desc:   term.Desc,
+		})
+	}
+	return clause.OrderBy{Columns: columns}
+}
@@ -0,0 +1,19 @@
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+type Driver interface {
+	// Push Add the job to its queue, it is not handed out before availableAt
+	Push(ctx context.Context, job Job, availableAt time.Time) error
+
+	// Pop Take the next available job of the queue, waiting up to timeout for one.
+	// Returns nil without an error when the queue stays empty.
+	Pop(ctx context.Context, queue string, timeout time.Duration) (*Job, error)
+
+	// Ack Forget a job of Pop once it is handled, pushed back or failed,
+	// until then a driver may hand it out again when the worker stopped
+	Ack(ctx context.Context, job Job) error
+}
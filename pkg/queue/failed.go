@@ -0,0 +1,70 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"gohub/pkg/database"
+)
+
+// FailedJob A job that ran out of attempts, kept in the failed_jobs table until retried
+type FailedJob struct {
+	ID       uint64 `gorm:"primaryKey;autoIncrement;"`
+	JobID    string
+	Queue    string
+	Type     string
+	Payload  string
+	Attempts int
+	// Error The error of the last attempt
+	Error    string
+	FailedAt time.Time
+}
+
+// fail Move the job to the failed_jobs table
+func fail(ctx context.Context, job Job, err error) error {
+	return database.DBWithContext(ctx).Create(&FailedJob{
+		JobID:    job.ID,
+		Queue:    job.Queue,
+		Type:     job.Type,
+		Payload:  string(job.Payload),
+		Attempts: job.Attempts,
+		Error:    err.Error(),
+		FailedAt: time.Now(),
+	}).Error
+}
+
+// Failed The failed jobs, oldest first
+func Failed(ctx context.Context) (failedJobs []FailedJob, err error) {
+	err = database.DBWithContext(ctx).Order("id").Find(&failedJobs).Error
+	return
+}
+
+// Retry Push the failed jobs with the given IDs back onto their queues with fresh attempts,
+// all of them when no ID is given. Returns the number of jobs pushed.
+func Retry(ctx context.Context, ids ...uint64) (retried int, err error) {
+	query := database.DBWithContext(ctx).Order("id")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	var failedJobs []FailedJob
+	if err = query.Find(&failedJobs).Error; err != nil {
+		return 0, err
+	}
+
+	for _, failedJob := range failedJobs {
+		job := Job{
+			ID:      failedJob.JobID,
+			Queue:   failedJob.Queue,
+			Type:    failedJob.Type,
+			Payload: []byte(failedJob.Payload),
+		}
+		if err = Queue.Driver.Push(ctx, job, time.Now()); err != nil {
+			return retried, err
+		}
+		if err = database.DBWithContext(ctx).Delete(&failedJob).Error; err != nil {
+			return retried, err
+		}
+		retried++
+	}
+	return retried, nil
+}
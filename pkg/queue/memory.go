@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryDriver Keeps the jobs in the process, for tests
+type MemoryDriver struct {
+	mu   sync.Mutex
+	jobs map[string][]memoryJob
+}
+
+type memoryJob struct {
+	job         Job
+	availableAt time.Time
+}
+
+func NewMemoryDriver() *MemoryDriver {
+	return &MemoryDriver{jobs: map[string][]memoryJob{}}
+}
+
+func (driver *MemoryDriver) Push(_ context.Context, job Job, availableAt time.Time) error {
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	driver.jobs[job.Queue] = append(driver.jobs[job.Queue], memoryJob{job: job, availableAt: availableAt})
+	return nil
+}
+
+func (driver *MemoryDriver) Pop(ctx context.Context, queue string, timeout time.Duration) (*Job, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if job := driver.take(queue); job != nil {
+			return job, nil
+		}
+		if !time.Now().Before(deadline) {
+			return nil, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// Ack Pop removes the jobs already, they only live as long as the process anyway
+func (driver *MemoryDriver) Ack(_ context.Context, _ Job) error {
+	return nil
+}
+
+// take The first available job, in push order
+func (driver *MemoryDriver) take(queue string) *Job {
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+
+	now := time.Now()
+	for i, entry := range driver.jobs[queue] {
+		if !entry.availableAt.After(now) {
+			driver.jobs[queue] = append(driver.jobs[queue][:i:i], driver.jobs[queue][i+1:]...)
+			return &entry.job
+		}
+	}
+	return nil
+}
+
+// Size Number of jobs in the queue, including the delayed ones
+func (driver *MemoryDriver) Size(queue string) int {
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	return len(driver.jobs[queue])
+}
@@ -0,0 +1,36 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryDriverDelaysJobs(t *testing.T) {
+	driver := NewMemoryDriver()
+	require.NoError(t, driver.Push(t.Context(), Job{ID: "later", Queue: "q"}, time.Now().Add(50*time.Millisecond)))
+	require.NoError(t, driver.Push(t.Context(), Job{ID: "now", Queue: "q"}, time.Now()))
+
+	job, err := driver.Pop(t.Context(), "q", 0)
+	require.NoError(t, err)
+	require.Equal(t, "now", job.ID)
+
+	job, err = driver.Pop(t.Context(), "q", 0)
+	require.NoError(t, err)
+	require.Nil(t, job, "the delayed job is not available yet")
+
+	job, err = driver.Pop(t.Context(), "q", time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "later", job.ID)
+	require.Zero(t, driver.Size("q"))
+}
+
+func TestWorkerBackoff(t *testing.T) {
+	worker := &Worker{Backoff: 10 * time.Second, MaxBackoff: time.Minute}
+	require.Equal(t, 10*time.Second, worker.backoff(1))
+	require.Equal(t, 20*time.Second, worker.backoff(2))
+	require.Equal(t, 40*time.Second, worker.backoff(3))
+	require.Equal(t, time.Minute, worker.backoff(4))
+	require.Equal(t, time.Minute, worker.backoff(10))
+}
@@ -0,0 +1,82 @@
+// Package queue Background jobs, pushed by the API and run by `gohub queue:work`
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"gohub/pkg/config"
+	"gohub/pkg/helpers"
+)
+
+// Job A unit of work, its Payload is decoded by the handler registered for its Type
+type Job struct {
+	ID       string          `json:"id"`
+	Queue    string          `json:"queue"`
+	Type     string          `json:"type"`
+	Payload  json.RawMessage `json:"payload"`
+	Attempts int             `json:"attempts"`
+
+	// reserved The job as Pop took it, for Ack
+	reserved string
+}
+
+// Handler Runs a job, an error makes the worker try again later
+type Handler func(ctx context.Context, payload []byte) error
+
+type Service struct {
+	Driver Driver
+}
+
+var (
+	once  sync.Once
+	Queue *Service
+
+	handlersMu sync.RWMutex
+	handlers   = map[string]Handler{}
+)
+
+func InitWithDriver(driver Driver) {
+	once.Do(func() {
+		Queue = &Service{
+			Driver: driver,
+		}
+	})
+}
+
+// Register Handle the jobs of jobType, usually called from the init of the package dispatching them
+func Register(jobType string, handler Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[jobType] = handler
+}
+
+func handlerOf(jobType string) (Handler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	handler, ok := handlers[jobType]
+	return handler, ok
+}
+
+// Dispatch Push a job of jobType onto the default queue, payload is encoded as JSON
+func Dispatch(ctx context.Context, jobType string, payload any) error {
+	return DispatchOn(ctx, config.GetString("queue.default"), jobType, payload)
+}
+
+// DispatchOn Push a job of jobType onto the named queue
+func DispatchOn(ctx context.Context, queue, jobType string, payload any) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("queue: encode %s payload: %w", jobType, err)
+	}
+
+	return Queue.Driver.Push(ctx, Job{
+		ID:      helpers.SecureRandomString(20),
+		Queue:   queue,
+		Type:    jobType,
+		Payload: encoded,
+	}, time.Now())
+}
@@ -0,0 +1,192 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	redisLib "github.com/redis/go-redis/v9"
+	"gohub/pkg/helpers"
+	"gohub/pkg/logger"
+	"gohub/pkg/redis"
+)
+
+// RedisDriver Ready jobs are kept in a list, delayed jobs in a sorted set scored by their time.
+// Pop moves a job to the processing list of the worker until Ack, so a worker that crashes does not
+// lose it: the processing lists of workers whose heartbeat expired go back to the queue on the next Pop.
+type RedisDriver struct {
+	RedisClient *redis.Client
+	KeyPrefix   string
+
+	// Worker Names the processing lists and heartbeats of this process
+	Worker string
+	// Heartbeat How long a worker counts as alive after its last heartbeat, it beats every third of it
+	Heartbeat time.Duration
+
+	beating sync.Once
+	mu      sync.Mutex
+	queues  map[string]bool
+}
+
+func NewRedisDriver(client *redis.Client, keyPrefix string) *RedisDriver {
+	hostname, _ := os.Hostname()
+	return &RedisDriver{
+		RedisClient: client,
+		KeyPrefix:   keyPrefix,
+		Worker:      fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), helpers.SecureRandomString(6)),
+		Heartbeat:   30 * time.Second,
+		queues:      map[string]bool{},
+	}
+}
+
+// migrateDelayed Move the due jobs of the sorted set KEYS[1] to the list KEYS[2] atomically
+var migrateDelayed = redisLib.NewScript(`
+local jobs = redis.call('zrangebyscore', KEYS[1], '-inf', ARGV[1])
+if #jobs > 0 then
+	redis.call('zremrangebyrank', KEYS[1], 0, #jobs - 1)
+	redis.call('rpush', KEYS[2], unpack(jobs))
+end
+return #jobs
+`)
+
+// reapAbandoned Move the processing lists of the workers in the set KEYS[1] without a heartbeat
+// (ARGV[1] .. worker) to the front of the list KEYS[2], oldest first, and forget the workers
+var reapAbandoned = redisLib.NewScript(`
+local reaped = 0
+for _, worker in ipairs(redis.call('smembers', KEYS[1])) do
+	if redis.call('exists', ARGV[1] .. worker) == 0 then
+		while redis.call('lmove', ARGV[2] .. worker, KEYS[2], 'LEFT', 'LEFT') do
+			reaped = reaped + 1
+		end
+		redis.call('srem', KEYS[1], worker)
+	end
+end
+return reaped
+`)
+
+func (driver *RedisDriver) Push(ctx context.Context, job Job, availableAt time.Time) error {
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	client := driver.RedisClient.Client
+	if availableAt.After(time.Now()) {
+		return client.ZAdd(ctx, driver.delayedKey(job.Queue), redisLib.Z{
+			Score:  float64(availableAt.UnixMilli()),
+			Member: encoded,
+		}).Err()
+	}
+	return client.RPush(ctx, driver.readyKey(job.Queue), encoded).Err()
+}
+
+func (driver *RedisDriver) Pop(ctx context.Context, queue string, timeout time.Duration) (*Job, error) {
+	if err := driver.beat(ctx, queue); err != nil {
+		return nil, err
+	}
+
+	client := driver.RedisClient.Client
+	reaped, err := reapAbandoned.Run(ctx, client,
+		[]string{driver.workersKey(queue), driver.readyKey(queue)},
+		driver.heartbeatKey(queue, ""), driver.processingKey(queue, ""),
+	).Int()
+	if err != nil {
+		return nil, err
+	}
+	if reaped > 0 {
+		logger.WarnString("Queue", "reap", fmt.Sprintf("%d jobs of stopped workers pushed back to [%s]", reaped, queue))
+	}
+
+	err = migrateDelayed.Run(ctx, client,
+		[]string{driver.delayedKey(queue), driver.readyKey(queue)},
+		strconv.FormatInt(time.Now().UnixMilli(), 10),
+	).Err()
+	if err != nil {
+		return nil, err
+	}
+
+	// The job stays in the processing list of the worker until Ack
+	encoded, err := client.BLMove(ctx, driver.readyKey(queue), driver.processingKey(queue, driver.Worker), "LEFT", "LEFT", timeout).Result()
+	if errors.Is(err, redisLib.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job Job
+	if err = json.Unmarshal([]byte(encoded), &job); err != nil {
+		return nil, err
+	}
+	job.reserved = encoded
+	return &job, nil
+}
+
+// Ack Remove the job from the processing list of the worker
+func (driver *RedisDriver) Ack(ctx context.Context, job Job) error {
+	return driver.RedisClient.Client.LRem(ctx, driver.processingKey(job.Queue, driver.Worker), 1, job.reserved).Err()
+}
+
+// beat Register the worker on the queue and refresh its heartbeat,
+// from then on it keeps beating in the background while the process runs
+func (driver *RedisDriver) beat(ctx context.Context, queue string) error {
+	driver.mu.Lock()
+	driver.queues[queue] = true
+	driver.mu.Unlock()
+
+	if err := driver.refresh(ctx, queue); err != nil {
+		return err
+	}
+
+	driver.beating.Do(func() {
+		go func() {
+			for range time.Tick(driver.Heartbeat / 3) {
+				driver.mu.Lock()
+				queues := make([]string, 0, len(driver.queues))
+				for name := range driver.queues {
+					queues = append(queues, name)
+				}
+				driver.mu.Unlock()
+
+				for _, name := range queues {
+					logger.LogIf(driver.refresh(context.Background(), name))
+				}
+			}
+		}()
+	})
+	return nil
+}
+
+func (driver *RedisDriver) refresh(ctx context.Context, queue string) error {
+	_, err := driver.RedisClient.Client.TxPipelined(ctx, func(pipe redisLib.Pipeliner) error {
+		pipe.Set(ctx, driver.heartbeatKey(queue, driver.Worker), time.Now().Unix(), driver.Heartbeat)
+		pipe.SAdd(ctx, driver.workersKey(queue), driver.Worker)
+		return nil
+	})
+	return err
+}
+
+func (driver *RedisDriver) readyKey(queue string) string {
+	return driver.KeyPrefix + queue
+}
+
+func (driver *RedisDriver) delayedKey(queue string) string {
+	return driver.KeyPrefix + queue + ":delayed"
+}
+
+func (driver *RedisDriver) workersKey(queue string) string {
+	return driver.KeyPrefix + queue + ":workers"
+}
+
+func (driver *RedisDriver) processingKey(queue, worker string) string {
+	return driver.KeyPrefix + queue + ":processing:" + worker
+}
+
+func (driver *RedisDriver) heartbeatKey(queue, worker string) string {
+	return driver.KeyPrefix + queue + ":heartbeat:" + worker
+}
@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gohub/pkg/logger"
+)
+
+// Worker Runs the jobs of one queue
+type Worker struct {
+	Queue string
+	// Tries Attempts per job before it is moved to failed_jobs
+	Tries int
+	// Backoff Wait before the second attempt, doubled for each further one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout How long Pop blocks waiting for a job
+	Timeout time.Duration
+}
+
+// Run Work until ctx is done
+func (worker *Worker) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if _, err := worker.RunNext(ctx); err != nil && ctx.Err() == nil {
+			logger.ErrorString("Queue", "pop", err.Error())
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// RunNext Run the next job, false when there was none within the timeout
+func (worker *Worker) RunNext(ctx context.Context) (bool, error) {
+	job, err := Queue.Driver.Pop(ctx, worker.Queue, worker.Timeout)
+	if err != nil || job == nil {
+		return false, err
+	}
+	return true, worker.process(ctx, *job)
+}
+
+func (worker *Worker) process(ctx context.Context, job Job) error {
+	job.Attempts++
+	err := worker.handle(ctx, job)
+
+	// A shutdown cancels ctx and fails the running job, it must still be pushed back or stored
+	ctx = context.WithoutCancel(ctx)
+	if err == nil {
+		logger.DebugString("Queue", job.Type, "processed job "+job.ID)
+		return Queue.Driver.Ack(ctx, job)
+	}
+	logger.WarnString("Queue", job.Type, fmt.Sprintf("job %s attempt %d failed: %v", job.ID, job.Attempts, err))
+
+	if job.Attempts < worker.Tries && !errors.Is(err, errNoHandler) {
+		err = Queue.Driver.Push(ctx, job, time.Now().Add(worker.backoff(job.Attempts)))
+	} else {
+		err = fail(ctx, job, err)
+	}
+	// Without the push or the failed job the reservation stays, the job is handed out again later
+	if err != nil {
+		return err
+	}
+	return Queue.Driver.Ack(ctx, job)
+}
+
+var errNoHandler = errors.New("no handler registered")
+
+// handle Run the handler of the job, a panic counts as a failed attempt
+func (worker *Worker) handle(ctx context.Context, job Job) (err error) {
+	handler, ok := handlerOf(job.Type)
+	if !ok {
+		return fmt.Errorf("%w for job type %s", errNoHandler, job.Type)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, job.Payload)
+}
+
+// backoff Wait after the given number of failed attempts
+func (worker *Worker) backoff(attempts int) time.Duration {
+	delay := worker.Backoff
+	for i := 1; i < attempts && delay < worker.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, worker.MaxBackoff)
+}
@@ -66,6 +66,16 @@ func (rds Client) Set(key string, value any, expiration time.Duration) bool {
 	return true
 }
 
+// SetNX Store the value only when the key does not exist, false when it did or on errors
+func (rds Client) SetNX(key string, value any, expiration time.Duration) bool {
+	ok, err := rds.Client.SetNX(rds.Context, key, value, expiration).Result()
+	if err != nil {
+		logger.ErrorString("Redis", "SetNX", err.Error())
+		return false
+	}
+	return ok
+}
+
 // Get the value corresponding to the key
 func (rds Client) Get(key string) string {
 	result, err := rds.Client.Get(rds.Context, key).Result()
@@ -90,6 +100,18 @@ func (rds Client) Has(key string) bool {
 	return true
 }
 
+// Pull Get the value of the key and delete it in one step, only one caller gets the value
+func (rds Client) Pull(key string) string {
+	result, err := rds.Client.GetDel(rds.Context, key).Result()
+	if err != nil {
+		if err != redis.Nil {
+			logger.ErrorString("Redis", "Pull", err.Error())
+		}
+		return ""
+	}
+	return result
+}
+
 // Del Delete data stored in redis, support multiple key parameters
 func (rds Client) Del(keys ...string) bool {
 	if err := rds.Client.Del(rds.Context, keys...).Err(); err != nil {
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gohub/pkg/logger"
@@ -22,6 +25,7 @@ const (
 	CodeNotFound      = "ERR_NOT_FOUND"
 	CodeValidation    = "ERR_VALIDATION"
 	CodeUnprocessable = "ERR_UNPROCESSABLE"
+	CodeTooMany       = "ERR_TOO_MANY_REQUESTS"
 	CodeInternal      = "ERR_INTERNAL"
 )
 
@@ -54,14 +58,37 @@ func Data(c *gin.Context, data any) {
 }
 
 // Paginated
-// Response 200 and JSON data in offset/limit pagination format
+// Response 200 and JSON data in offset/limit pagination format,
+// or with next_cursor/prev_cursor for keyset pagination, where total is optional
 func Paginated(c *gin.Context, items any, paging paginator.Paging) {
-	respond(c, http.StatusOK, CodeOK, "OK", gin.H{
-		"items":  items,
-		"offset": paging.Offset,
-		"limit":  paging.Limit,
-		"total":  paging.Total,
-	}, nil)
+	if !paging.Keyset {
+		respond(c, http.StatusOK, CodeOK, "OK", gin.H{
+			"items":  items,
+			"offset": paging.Offset,
+			"limit":  paging.Limit,
+			"total":  paging.Total,
+		}, nil)
+		return
+	}
+
+	data := gin.H{
+		"items":       items,
+		"limit":       paging.Limit,
+		"next_cursor": nullableCursor(paging.NextCursor),
+		"prev_cursor": nullableCursor(paging.PrevCursor),
+	}
+	if paging.Counted {
+		data["total"] = paging.Total
+	}
+	respond(c, http.StatusOK, CodeOK, "OK", data, nil)
+}
+
+// nullableCursor No page in that direction is sent as null
+func nullableCursor(cursor string) any {
+	if cursor == "" {
+		return nil
+	}
+	return cursor
 }
 
 // Created
@@ -150,6 +177,17 @@ func Unauthorized(c *gin.Context, msg ...string) {
 		defaultMessage("Unauthorized", msg...), nil)
 }
 
+// TooManyRequests
+// Response 429 with the Retry-After header in seconds, use the default message when no msg parameter is passed
+// Called when a client is throttled or locked out
+func TooManyRequests(c *gin.Context, retryAfter time.Duration, msg ...string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	errorResponse(c, http.StatusTooManyRequests, CodeTooMany,
+		defaultMessage("Too many requests, please try again later", msg...),
+		map[string][]string{"retry_after": {strconv.Itoa(seconds)}})
+}
+
 func respond(c *gin.Context, status int, code string, msg string, data any, errs map[string][]string) {
 	payload := envelope{Msg: msg, Code: code, Data: data, Errors: errs}
 	c.JSON(status, payload)
@@ -0,0 +1,65 @@
+// Package signedurl URLs whose query can not be changed, such as the links sent by email
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gohub/pkg/config"
+)
+
+var (
+	// ErrInvalidSignature The URL was not signed by Sign or changed afterwards
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrExpired The URL was signed, but it is too late to use it
+	ErrExpired = errors.New("signed URL expired")
+)
+
+// Sign Add the expires and signature query parameters, the URL is valid until expireAt.
+// Path and query are signed with app.key, the host is not, so links work behind proxies.
+func Sign(rawURL string, expireAt time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Del("signature")
+	query.Set("expires", strconv.FormatInt(expireAt.Unix(), 10))
+	query.Set("signature", signature(u.Path, query))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Verify Check the signature and the expiry of a URL of Sign, such as c.Request.URL
+func Verify(u *url.URL) error {
+	query := u.Query()
+	given, err := hex.DecodeString(query.Get("signature"))
+	if err != nil || len(given) == 0 {
+		return ErrInvalidSignature
+	}
+	query.Del("signature")
+
+	expected, _ := hex.DecodeString(signature(u.Path, query))
+	if !hmac.Equal(given, expected) {
+		return ErrInvalidSignature
+	}
+
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrExpired
+	}
+	return nil
+}
+
+// signature HMAC-SHA256 of the path and the sorted query
+func signature(path string, query url.Values) string {
+	mac := hmac.New(sha256.New, []byte(config.GetString("app.key")))
+	mac.Write([]byte(path + "?" + query.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package signedurl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gohub/pkg/config"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	config.Set("app.key", "unit-test-key")
+
+	signed, err := Sign("http://localhost/api/v1/auth/email/verify?user=1&email=a%40example.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	require.NoError(t, Verify(u))
+
+	// Another host, as behind a proxy
+	u.Host = "example.com"
+	require.NoError(t, Verify(u))
+
+	changed := *u
+	query := changed.Query()
+	query.Set("user", "2")
+	changed.RawQuery = query.Encode()
+	require.ErrorIs(t, Verify(&changed), ErrInvalidSignature)
+
+	changed = *u
+	changed.Path = "/api/v1/auth/other"
+	require.ErrorIs(t, Verify(&changed), ErrInvalidSignature)
+
+	config.Set("app.key", "another-key")
+	require.ErrorIs(t, Verify(u), ErrInvalidSignature)
+	config.Set("app.key", "unit-test-key")
+
+	unsigned, _ := url.Parse("http://localhost/api/v1/auth/email/verify?user=1")
+	require.ErrorIs(t, Verify(unsigned), ErrInvalidSignature)
+}
+
+func TestVerifyExpired(t *testing.T) {
+	config.Set("app.key", "unit-test-key")
+
+	signed, err := Sign("http://localhost/verify?user=1", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	u, _ := url.Parse(signed)
+	require.ErrorIs(t, Verify(u), ErrExpired)
+
+	// The expiry is signed as well
+	query := u.Query()
+	query.Set("expires", "9999999999")
+	u.RawQuery = query.Encode()
+	require.ErrorIs(t, Verify(u), ErrInvalidSignature)
+}
@@ -0,0 +1,84 @@
+package sms
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gohub/pkg/helpers"
+)
+
+// Aliyun Alibaba Cloud SMS, signed with the RPC signature version 1.0
+//
+// Config: access_key_id, access_key_secret, sign_name, region, endpoint and template_<name>
+type Aliyun struct {
+	Client *http.Client
+}
+
+func (s *Aliyun) Send(phone string, message Message, config map[string]string) error {
+	templateParam, err := json.Marshal(message.Data)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{
+		"AccessKeyId":      {config["access_key_id"]},
+		"Action":           {"SendSms"},
+		"Format":           {"JSON"},
+		"PhoneNumbers":     {phone},
+		"RegionId":         {config["region"]},
+		"SignName":         {config["sign_name"]},
+		"SignatureMethod":  {"HMAC-SHA1"},
+		"SignatureNonce":   {helpers.RandomString(16)},
+		"SignatureVersion": {"1.0"},
+		"TemplateCode":     {message.templateID(config)},
+		"TemplateParam":    {string(templateParam)},
+		"Timestamp":        {time.Now().UTC().Format("2006-01-02T15:04:05Z")},
+		"Version":          {"2017-05-25"},
+	}
+	params.Set("Signature", aliyunSignature(http.MethodPost, params, config["access_key_secret"]))
+
+	req, err := http.NewRequest(http.MethodPost, config["endpoint"], strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result struct {
+		Code    string
+		Message string
+	}
+	if err = do(s.Client, "aliyun", req, &result); err != nil {
+		return err
+	}
+	if result.Code != "OK" {
+		return &Error{Driver: "aliyun", Status: http.StatusOK, Code: result.Code, Message: result.Message}
+	}
+	return nil
+}
+
+// aliyunSignature HMAC-SHA1 of the canonicalized parameters
+func aliyunSignature(method string, params url.Values, secret string) string {
+	// Encode sorts by key
+	canonicalized := aliyunEncode(params.Encode())
+	stringToSign := method + "&" + aliyunEscape("/") + "&" + aliyunEscape(canonicalized)
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliyunEscape RFC 3986 percent-encoding
+func aliyunEscape(s string) string {
+	return aliyunEncode(url.QueryEscape(s))
+}
+
+// aliyunEncode Turn url.QueryEscape output into RFC 3986 percent-encoding
+func aliyunEncode(s string) string {
+	return strings.NewReplacer("+", "%20", "*", "%2A", "%7E", "~").Replace(s)
+}
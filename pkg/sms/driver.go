@@ -1,6 +1,77 @@
 package sms
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
 type Driver interface {
-	// Send SMS
-	Send(phone string, message Message, config map[string]string) bool
+	// Send SMS, the error tells why the provider did not accept it
+	Send(phone string, message Message, config map[string]string) error
+}
+
+// Error The provider answered, but did not accept the message
+type Error struct {
+	Driver string
+	// Status HTTP status of the response
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s %s (HTTP %d)", e.Driver, e.Code, e.Message, e.Status)
+}
+
+// Retryable Whether sending again may succeed: network errors, throttling and server errors
+func Retryable(err error) bool {
+	var providerErr *Error
+	if errors.As(err, &providerErr) {
+		return providerErr.Status == http.StatusTooManyRequests || providerErr.Status >= http.StatusInternalServerError
+	}
+	return !errors.Is(err, ErrDisabled)
+}
+
+// ErrDisabled No SMS provider is configured
+var ErrDisabled = errors.New("SMS provider disabled")
+
+// defaultClient Used by the HTTP drivers without a Client of their own
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// do Send the request and decode the JSON response into result,
+// responses with an error status are returned as *Error with the body as message
+func do(client *http.Client, driver string, req *http.Request, result any) error {
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &Error{Driver: driver, Status: resp.StatusCode, Code: resp.Status, Message: strings.TrimSpace(string(body))}
+	}
+	if err = json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("%s: decode response: %w", driver, err)
+	}
+	return nil
+}
+
+// e164 Phone numbers without a country code are taken as mainland China numbers
+func e164(phone string) string {
+	if strings.HasPrefix(phone, "+") {
+		return phone
+	}
+	return "+86" + phone
 }
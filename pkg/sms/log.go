@@ -0,0 +1,29 @@
+package sms
+
+import (
+	"fmt"
+
+	"gohub/pkg/app"
+	"gohub/pkg/logger"
+)
+
+// Log driver writes messages to the log instead of sending them, for local development.
+// In production it fails like Noop, codes in the log would reach nobody but the operators.
+type Log struct{}
+
+func (l *Log) Send(phone string, message Message, _ map[string]string) error {
+	if app.IsProduction() {
+		logger.WarnString("SMS", "Send", "the log driver does not send SMS in production; message not sent")
+		return ErrDisabled
+	}
+	logger.InfoString("SMS", "Send", fmt.Sprintf("to %s [%s]: %s", phone, message.Template, message.Text()))
+	return nil
+}
+
+// Noop driver disables SMS sending when no provider is configured.
+type Noop struct{}
+
+func (noop *Noop) Send(_ string, _ Message, _ map[string]string) error {
+	logger.WarnString("SMS", "Send", "SMS provider disabled; message not sent")
+	return ErrDisabled
+}
@@ -2,22 +2,69 @@
 package sms
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"gohub/pkg/config"
 	"gohub/pkg/logger"
 )
 
 // Message SMS struct
 type Message struct {
+	// Template Name of the provider template, e.g. "verify_code", drivers look up
+	// the template ID as template_<name> in their config and fall back to the name itself
 	Template string
-	Data     map[string]string
+	// Data Template parameters, {key} placeholders in Content are replaced by them as well
+	Data map[string]string
 
+	// Content Text for the drivers without templates
 	Content string
 }
 
+// Text Content with the {key} placeholders replaced by Data
+func (message Message) Text() string {
+	pairs := make([]string, 0, len(message.Data)*2)
+	for key, value := range message.Data {
+		pairs = append(pairs, "{"+key+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(message.Content)
+}
+
+// templateID The provider template ID of the message
+func (message Message) templateID(config map[string]string) string {
+	if id := config["template_"+message.Template]; id != "" {
+		return id
+	}
+	return message.Template
+}
+
+// params Data ordered by key, for providers taking positional template parameters
+func (message Message) params() []string {
+	keys := make([]string, 0, len(message.Data))
+	for key := range message.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		params = append(params, message.Data[key])
+	}
+	return params
+}
+
 // SMS Action class for sending SMS
 type SMS struct {
 	Driver Driver
+	// Config Settings of the driver, see config/sms.go
+	Config map[string]string
+
+	// Retries Attempts after the first one failed, waiting RetryDelay and doubling it each time
+	Retries    int
+	RetryDelay time.Duration
 }
 
 // once singleton pattern
@@ -26,25 +73,50 @@ var once sync.Once
 // internalSMS SMS object used internally
 var internalSMS *SMS
 
-// NewSMS Singleton mode acquisition
+// NewSMS Singleton mode acquisition, the driver is chosen by sms.driver
 func NewSMS() *SMS {
 	once.Do(func() {
+		name := config.GetString("sms.driver")
+		driver, ok := drivers[name]
+		if !ok {
+			logger.WarnString("SMS", "NewSMS", fmt.Sprintf("unknown SMS driver %q, SMS sending is disabled", name))
+			driver = func() Driver { return &Noop{} }
+		}
+
 		internalSMS = &SMS{
-			Driver: &Noop{},
+			Driver:     driver(),
+			Config:     config.GetStringMapString("sms." + name),
+			Retries:    config.GetInt("sms.retries"),
+			RetryDelay: time.Duration(config.GetInt("sms.retry_delay")) * time.Millisecond,
 		}
 	})
 
 	return internalSMS
 }
 
-func (sms *SMS) Send(phone string, message Message) bool {
-	return sms.Driver.Send(phone, message, map[string]string{})
+// drivers The drivers sms.driver can choose from
+var drivers = map[string]func() Driver{
+	"aliyun":  func() Driver { return &Aliyun{} },
+	"tencent": func() Driver { return &Tencent{} },
+	"twilio":  func() Driver { return &Twilio{} },
+	"log":     func() Driver { return &Log{} },
+	"noop":    func() Driver { return &Noop{} },
 }
 
-// Noop driver disables SMS sending when no provider is configured.
-type Noop struct{}
+// Send Send the message, retrying when the provider fails temporarily
+func (sms *SMS) Send(phone string, message Message) (err error) {
+	delay := sms.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if err = sms.Driver.Send(phone, message, sms.Config); err == nil {
+			return nil
+		}
+		if attempt >= sms.Retries || !Retryable(err) {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
 
-func (noop *Noop) Send(phone string, message Message, _ map[string]string) bool {
-	logger.WarnString("SMS", "Send", "SMS provider disabled; message not sent")
-	return false
+	logger.ErrorString("SMS", "Send", err.Error())
+	return fmt.Errorf("send SMS to %s: %w", phone, err)
 }
@@ -0,0 +1,212 @@
+package sms
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gohub/pkg/config"
+	"gohub/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	os.Exit(m.Run())
+}
+
+var verifyCode = Message{
+	Template: "verify_code",
+	Data:     map[string]string{"code": "123456"},
+	Content:  "Your verification code is {code}",
+}
+
+// provider A stand-in provider answering with the given status and body, the requests are counted
+func provider(t *testing.T, handle func(r *http.Request) (int, string)) (*httptest.Server, *int) {
+	t.Helper()
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		status, body := handle(r)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestMessageText(t *testing.T) {
+	require.Equal(t, "Your verification code is 123456", verifyCode.Text())
+	require.Equal(t, "SMS_1", verifyCode.templateID(map[string]string{"template_verify_code": "SMS_1"}))
+	require.Equal(t, "verify_code", verifyCode.templateID(map[string]string{}))
+
+	message := Message{Data: map[string]string{"2": "b", "1": "a"}}
+	require.Equal(t, []string{"a", "b"}, message.params())
+}
+
+func TestAliyunSend(t *testing.T) {
+	config := map[string]string{
+		"access_key_id":        "key",
+		"access_key_secret":    "secret",
+		"sign_name":            "Gohub",
+		"region":               "cn-hangzhou",
+		"template_verify_code": "SMS_123",
+	}
+
+	server, hits := provider(t, func(r *http.Request) (int, string) {
+		assert.NoError(t, r.ParseForm())
+		form := url.Values{}
+		for key, values := range r.PostForm {
+			form[key] = values
+		}
+		signature := form.Get("Signature")
+		form.Del("Signature")
+		assert.Equal(t, aliyunSignature(http.MethodPost, form, "secret"), signature)
+
+		assert.Equal(t, "SendSms", form.Get("Action"))
+		assert.Equal(t, "13800138000", form.Get("PhoneNumbers"))
+		assert.Equal(t, "SMS_123", form.Get("TemplateCode"))
+		assert.JSONEq(t, `{"code":"123456"}`, form.Get("TemplateParam"))
+		return http.StatusOK, `{"Code":"OK","Message":"OK"}`
+	})
+	config["endpoint"] = server.URL
+
+	sms := &SMS{Driver: &Aliyun{Client: server.Client()}, Config: config, Retries: 2}
+	require.NoError(t, sms.Send("13800138000", verifyCode))
+	require.Equal(t, 1, *hits)
+}
+
+func TestAliyunRejected(t *testing.T) {
+	server, hits := provider(t, func(r *http.Request) (int, string) {
+		return http.StatusOK, `{"Code":"isv.MOBILE_NUMBER_ILLEGAL","Message":"invalid number"}`
+	})
+
+	sms := &SMS{Driver: &Aliyun{}, Config: map[string]string{"endpoint": server.URL}, Retries: 2}
+	err := sms.Send("123", verifyCode)
+
+	var providerErr *Error
+	require.ErrorAs(t, err, &providerErr)
+	require.Equal(t, "isv.MOBILE_NUMBER_ILLEGAL", providerErr.Code)
+	require.Equal(t, 1, *hits, "a rejected message is not retried")
+}
+
+func TestTencentSend(t *testing.T) {
+	config := map[string]string{
+		"secret_id":            "id",
+		"secret_key":           "key",
+		"sdk_app_id":           "1400000000",
+		"sign_name":            "Gohub",
+		"region":               "ap-guangzhou",
+		"template_verify_code": "449739",
+	}
+
+	server, _ := provider(t, func(r *http.Request) (int, string) {
+		payload, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get("X-TC-Timestamp"), 10, 64)
+		assert.NoError(t, err)
+		assert.Equal(t, "SendSms", r.Header.Get("X-TC-Action"))
+		assert.Equal(t, "ap-guangzhou", r.Header.Get("X-TC-Region"))
+		assert.Equal(t, tencentAuthorization(r.Host, payload, time.Unix(timestamp, 0).UTC(), config), r.Header.Get("Authorization"))
+
+		var body struct {
+			PhoneNumberSet   []string
+			TemplateID       string `json:"TemplateId"`
+			TemplateParamSet []string
+		}
+		assert.NoError(t, json.Unmarshal(payload, &body))
+		assert.Equal(t, []string{"+8613800138000"}, body.PhoneNumberSet)
+		assert.Equal(t, "449739", body.TemplateID)
+		assert.Equal(t, []string{"123456"}, body.TemplateParamSet)
+
+		return http.StatusOK, `{"Response":{"SendStatusSet":[{"Code":"Ok","Message":"send success"}]}}`
+	})
+	config["endpoint"] = server.URL
+
+	sms := &SMS{Driver: &Tencent{}, Config: config}
+	require.NoError(t, sms.Send("13800138000", verifyCode))
+}
+
+func TestTwilioSend(t *testing.T) {
+	server, _ := provider(t, func(r *http.Request) (int, string) {
+		assert.Equal(t, "/2010-04-01/Accounts/AC1/Messages.json", r.URL.Path)
+		sid, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC1", sid)
+		assert.Equal(t, "token", token)
+
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "+8613800138000", r.PostForm.Get("To"))
+		assert.Equal(t, "+15005550006", r.PostForm.Get("From"))
+		assert.Equal(t, "Your verification code is 123456", r.PostForm.Get("Body"))
+		return http.StatusCreated, `{"sid":"SM1","status":"queued"}`
+	})
+
+	sms := &SMS{Driver: &Twilio{}, Config: map[string]string{
+		"account_sid": "AC1",
+		"auth_token":  "token",
+		"from":        "+15005550006",
+		"endpoint":    server.URL,
+	}}
+	require.NoError(t, sms.Send("13800138000", verifyCode))
+}
+
+func TestSendRetries(t *testing.T) {
+	failures := 2
+	server, hits := provider(t, func(r *http.Request) (int, string) {
+		if failures > 0 {
+			failures--
+			return http.StatusServiceUnavailable, `{"message":"try later"}`
+		}
+		return http.StatusCreated, `{"sid":"SM1"}`
+	})
+	config := map[string]string{"endpoint": server.URL}
+
+	sms := &SMS{Driver: &Twilio{}, Config: config, Retries: 2, RetryDelay: time.Millisecond}
+	require.NoError(t, sms.Send("13800138000", verifyCode))
+	require.Equal(t, 3, *hits)
+
+	// Out of retries, the last error is reported
+	failures, *hits = 5, 0
+	err := sms.Send("13800138000", verifyCode)
+	var providerErr *Error
+	require.ErrorAs(t, err, &providerErr)
+	require.Equal(t, http.StatusServiceUnavailable, providerErr.Status)
+	require.Equal(t, 3, *hits)
+}
+
+func TestSendClientErrorNotRetried(t *testing.T) {
+	server, hits := provider(t, func(r *http.Request) (int, string) {
+		return http.StatusBadRequest, `{"code":21211,"message":"invalid To number"}`
+	})
+
+	sms := &SMS{Driver: &Twilio{}, Config: map[string]string{"endpoint": server.URL}, Retries: 2, RetryDelay: time.Millisecond}
+	err := sms.Send("123", verifyCode)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid To number")
+	require.Equal(t, 1, *hits)
+}
+
+func TestNoopDisabled(t *testing.T) {
+	sms := &SMS{Driver: &Noop{}, Retries: 2}
+	require.ErrorIs(t, sms.Send("13800138000", verifyCode), ErrDisabled)
+	require.NoError(t, (&SMS{Driver: &Log{}}).Send("13800138000", verifyCode))
+}
+
+func TestLogRefusesInProduction(t *testing.T) {
+	env := config.Get("app.env")
+	config.Set("app.env", "production")
+	t.Cleanup(func() { config.Set("app.env", env) })
+
+	require.ErrorIs(t, (&SMS{Driver: &Log{}, Retries: 2}).Send("13800138000", verifyCode), ErrDisabled)
+}
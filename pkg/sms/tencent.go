@@ -0,0 +1,107 @@
+package sms
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Tencent Tencent Cloud SMS, signed with TC3-HMAC-SHA256
+//
+// Config: secret_id, secret_key, sdk_app_id, sign_name, region, endpoint and template_<name>.
+// Tencent templates take positional parameters, Data is passed in the order of its keys.
+type Tencent struct {
+	Client *http.Client
+}
+
+func (s *Tencent) Send(phone string, message Message, config map[string]string) error {
+	payload, err := json.Marshal(map[string]any{
+		"PhoneNumberSet":   []string{e164(phone)},
+		"SmsSdkAppId":      config["sdk_app_id"],
+		"SignName":         config["sign_name"],
+		"TemplateId":       message.templateID(config),
+		"TemplateParamSet": message.params(),
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(config["endpoint"])
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint.String(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-TC-Action", "SendSms")
+	req.Header.Set("X-TC-Version", "2021-01-11")
+	req.Header.Set("X-TC-Region", config["region"])
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("Authorization", tencentAuthorization(endpoint.Host, payload, now, config))
+
+	var result struct {
+		Response struct {
+			Error *struct {
+				Code    string
+				Message string
+			}
+			SendStatusSet []struct {
+				Code    string
+				Message string
+			}
+		}
+	}
+	if err = do(s.Client, "tencent", req, &result); err != nil {
+		return err
+	}
+	if e := result.Response.Error; e != nil {
+		return &Error{Driver: "tencent", Status: http.StatusOK, Code: e.Code, Message: e.Message}
+	}
+	for _, status := range result.Response.SendStatusSet {
+		if status.Code != "Ok" {
+			return &Error{Driver: "tencent", Status: http.StatusOK, Code: status.Code, Message: status.Message}
+		}
+	}
+	return nil
+}
+
+// tencentAuthorization The Authorization header of a TC3-HMAC-SHA256 signed request
+func tencentAuthorization(host string, payload []byte, now time.Time, config map[string]string) string {
+	const service, signedHeaders = "sms", "content-type;host"
+	date := now.Format("2006-01-02")
+	scope := date + "/" + service + "/tc3_request"
+
+	canonicalRequest := fmt.Sprintf("POST\n/\n\ncontent-type:application/json; charset=utf-8\nhost:%s\n\n%s\n%s",
+		host, signedHeaders, sha256Hex(payload))
+	stringToSign := fmt.Sprintf("TC3-HMAC-SHA256\n%d\n%s\n%s", now.Unix(), scope, sha256Hex([]byte(canonicalRequest)))
+
+	key := hmacSHA256([]byte("TC3"+config["secret_key"]), date)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config["secret_id"], scope, signedHeaders, signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
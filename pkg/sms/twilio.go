@@ -0,0 +1,42 @@
+package sms
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Twilio Twilio, or any provider with a Twilio-compatible Messages API
+//
+// Config: account_sid, auth_token, from and endpoint. Twilio has no templates, Message.Text() is sent.
+type Twilio struct {
+	Client *http.Client
+}
+
+func (s *Twilio) Send(phone string, message Message, config map[string]string) error {
+	form := url.Values{
+		"To":   {e164(phone)},
+		"From": {config["from"]},
+		"Body": {message.Text()},
+	}
+	endpoint := strings.TrimRight(config["endpoint"], "/") +
+		"/2010-04-01/Accounts/" + url.PathEscape(config["account_sid"]) + "/Messages.json"
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(config["account_sid"], config["auth_token"])
+
+	var result struct {
+		ErrorMessage string `json:"error_message"`
+	}
+	if err = do(s.Client, "twilio", req, &result); err != nil {
+		return err
+	}
+	if result.ErrorMessage != "" {
+		return &Error{Driver: "twilio", Status: http.StatusOK, Message: result.ErrorMessage}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Package totp Time-based one-time passwords (RFC 6238) as shown by authenticator apps
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	// Digits of a code
+	Digits = 6
+	// Period Seconds a code is valid, authenticator apps only support 30
+	Period = 30
+
+	// modulus 10^Digits
+	modulus = 1_000_000
+)
+
+// encoding Secrets are base32 without padding, as authenticator apps expect them
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret A random 160 bit secret
+func GenerateSecret() string {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return encoding.EncodeToString(secret)
+}
+
+// Step The time step of t
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// Code The code of the secret at the time step
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", fmt.Errorf("totp: invalid secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%modulus), nil
+}
+
+// Validate Whether code is the code of the secret at t, or of skew steps before or after it
+// for clocks that are off. Returns the step the code belongs to, so that it can be used only once.
+func Validate(secret, code string, t time.Time, skew int) (step int64, ok bool) {
+	current := Step(t)
+	for offset := -int64(skew); offset <= int64(skew); offset++ {
+		expected, err := Code(secret, current+offset)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return current + offset, true
+		}
+	}
+	return 0, false
+}
+
+// URI The otpauth:// URI authenticator apps add the account with
+func URI(issuer, account, secret string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(Digits)},
+		"period":    {fmt.Sprint(Period)},
+	}
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// QRCode The URI as a PNG QR code of size pixels, to be scanned by authenticator apps
+func QRCode(uri string, size int) ([]byte, error) {
+	return qrcode.Encode(uri, qrcode.Medium, size)
+}
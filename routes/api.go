@@ -6,23 +6,20 @@ import (
 	controllers "gohub/app/http/controllers/api/v1"
 	"gohub/app/http/controllers/api/v1/auth"
 	"gohub/app/http/middlewares"
-	"gohub/pkg/config"
+	"gohub/app/models/permission"
+	"gohub/pkg/app"
 )
 
 // RegisterAPIRoutes Registration page related routing
 func RegisterAPIRoutes(r *gin.Engine) {
-	var v1 *gin.RouterGroup
-	if len(config.Get("app.api_domain")) == 0 {
-		v1 = r.Group("/api/v1")
-	} else {
-		v1 = r.Group("/v1")
-	}
+	// /v1 on the API domain, /api/v1 otherwise, links to the routes are built with app.APIURL
+	v1 := r.Group(app.APIPrefix())
 
 	// Global middleware: rate limit per hour. Here is where all API requests add up.
-	v1.Use(middlewares.LimitIP("200-H"))
+	v1.Use(middlewares.LimitIP("global"))
 	{
 		authGroup := v1.Group("/auth")
-		authGroup.Use(middlewares.LimitIP("1000-H"))
+		authGroup.Use(middlewares.LimitIP("auth"))
 		{
 			// Sign up
 			suc := new(auth.SignupController)
@@ -35,61 +32,133 @@ func RegisterAPIRoutes(r *gin.Engine) {
 
 			// Send verification code
 			vcc := new(auth.VerifyController)
-			// Image verification code, need to add current limit
-			authGroup.POST("/verify-codes/captcha", vcc.ShowCaptcha)
-			authGroup.POST("/verify-codes/phone", vcc.SendUsingPhone)
-			authGroup.POST("/verify-codes/email", vcc.SendUsingEmail)
+			// Verification codes, each route is limited per IP
+			authGroup.POST("/verify-codes/captcha", middlewares.LimitPerRoute("verify_code"), vcc.ShowCaptcha)
+			authGroup.POST("/verify-codes/phone", middlewares.LimitPerRoute("verify_code"), vcc.SendUsingPhone)
+			authGroup.POST("/verify-codes/email", middlewares.LimitPerRoute("verify_code"), vcc.SendUsingEmail)
 
 			// Login
 			lgc := new(auth.LoginController)
 			// Use phone, SMS verify code to login
-			authGroup.POST("/login/using-phone", lgc.LoginByPhone)
+			authGroup.POST("/login/using-phone", middlewares.LimitPerRoute("login"), lgc.LoginByPhone)
 			// Support phone, username, email
-			authGroup.POST("/login/using-password", lgc.LoginByPassword)
+			authGroup.POST("/login/using-password", middlewares.LimitPerRoute("login"), lgc.LoginByPassword)
+			// The code of the authenticator app after the password, for users with two-factor authentication
+			authGroup.POST("/login/2fa", middlewares.LimitPerRoute("login"), lgc.LoginByTwoFactor)
 			authGroup.POST("/login/refresh-token", lgc.RefreshToken)
+			// Logout the current login, or all logins of the user
+			authGroup.POST("/logout", middlewares.AuthJWT(), middlewares.LimitUser("user"), lgc.Logout)
+			authGroup.POST("/logout-all", middlewares.AuthJWT(), middlewares.LimitUser("user"), lgc.LogoutAll)
+
+			// Sign in with GitHub or Google
+			oac := new(auth.OAuthController)
+			authGroup.GET("/oauth/:provider/redirect", oac.Redirect)
+			authGroup.GET("/oauth/:provider/callback", middlewares.LimitPerRoute("login"), oac.Callback)
 
 			// Reset password
 			pwc := new(auth.PasswordController)
 			authGroup.POST("/password-reset/using-phone", pwc.ResetByPhone)
 			authGroup.POST("/password-reset/using-email", pwc.ResetByEmail)
+
+			// Links of the verification emails
+			evc := new(controllers.EmailVerificationController)
+			authGroup.GET("/email/verify", evc.Verify)
 		}
 	}
 
+	// Topics are also listed under their category and author
+	tpc := new(controllers.TopicsController)
+
 	uc := new(controllers.UsersController)
 	// Get current user
-	v1.GET("/user", middlewares.AuthJWT(), uc.CurrentUser)
+	v1.GET("/user", middlewares.AuthJWT(), middlewares.LimitUser("user"), uc.CurrentUser)
+	ntc := new(controllers.NotificationsController)
+	ntcGroup := v1.Group("/user/notifications", middlewares.AuthJWT(), middlewares.LimitUser("user"))
+	{
+		ntcGroup.GET("", ntc.Index)
+		ntcGroup.POST("/read-all", ntc.ReadAll)
+		ntcGroup.POST("/:id/read", ntc.Read)
+	}
+
+	sac := new(controllers.SocialAccountsController)
+	sacGroup := v1.Group("/user/social-accounts", middlewares.AuthJWT(), middlewares.LimitUser("user"))
+	{
+		sacGroup.GET("", sac.Index)
+		sacGroup.POST("/:provider", sac.Link)
+		sacGroup.DELETE("/:provider", sac.Unlink)
+	}
+
+	ssc := new(controllers.SessionsController)
+	sscGroup := v1.Group("/user/sessions", middlewares.AuthJWT(), middlewares.LimitUser("user"))
+	{
+		sscGroup.GET("", ssc.Index)
+		sscGroup.DELETE("/:id", ssc.Delete)
+	}
+
+	evcGroup := v1.Group("/user/email", middlewares.AuthJWT(), middlewares.LimitUser("user"))
+	{
+		evc := new(controllers.EmailVerificationController)
+		evcGroup.POST("/verification", middlewares.LimitPerRoute("verify_code"), evc.Send)
+		evcGroup.POST("/verify", evc.VerifyByCode)
+	}
+
+	tfc := new(controllers.TwoFactorController)
+	tfcGroup := v1.Group("/user/2fa", middlewares.AuthJWT(), middlewares.LimitUser("user"))
+	{
+		tfcGroup.POST("/setup", tfc.Setup)
+		tfcGroup.POST("/confirm", tfc.Confirm)
+		tfcGroup.DELETE("", tfc.Disable)
+	}
+
 	userGroup := v1.Group("/users")
 	{
 		userGroup.GET("", uc.Index)
-		userGroup.PUT("", middlewares.AuthJWT(), uc.UpdateProfile)
-		userGroup.PUT("/email", middlewares.AuthJWT(), uc.UpdateEmail)
-		userGroup.PUT("/phone", middlewares.AuthJWT(), uc.UpdatePhone)
-		userGroup.PUT("/password", middlewares.AuthJWT(), uc.UpdatePassword)
-		userGroup.PUT("/avatar", middlewares.AuthJWT(), uc.UpdateAvatar)
+		userGroup.GET("/:id/topics", tpc.IndexByUser)
+		userGroup.PUT("", middlewares.AuthJWT(), middlewares.LimitUser("user"), uc.UpdateProfile)
+		userGroup.PUT("/email", middlewares.AuthJWT(), middlewares.LimitUser("user"), uc.UpdateEmail)
+		userGroup.PUT("/phone", middlewares.AuthJWT(), middlewares.LimitUser("user"), uc.UpdatePhone)
+		userGroup.PUT("/password", middlewares.AuthJWT(), middlewares.LimitUser("user"), uc.UpdatePassword)
+		userGroup.PUT("/avatar", middlewares.AuthJWT(), middlewares.LimitUser("user"), uc.UpdateAvatar)
 	}
 
 	cgc := new(controllers.CategoriesController)
 	cgcGroup := v1.Group("/categories")
 	{
 		cgcGroup.GET("", cgc.Index)
-		cgcGroup.POST("", middlewares.AuthJWT(), cgc.Store)
-		cgcGroup.PUT("/:id", middlewares.AuthJWT(), cgc.Update)
-		cgcGroup.DELETE("/:id", middlewares.AuthJWT(), cgc.Delete)
+		cgcGroup.GET("/:id/topics", tpc.IndexByCategory)
+		cgcGroup.POST("", middlewares.AuthJWT(), middlewares.LimitUser("user"), middlewares.Can(permission.CategoriesManage), cgc.Store)
+		cgcGroup.PUT("/:id", middlewares.AuthJWT(), middlewares.LimitUser("user"), middlewares.Can(permission.CategoriesManage), cgc.Update)
+		cgcGroup.DELETE("/:id", middlewares.AuthJWT(), middlewares.LimitUser("user"), middlewares.Can(permission.CategoriesManage), cgc.Delete)
 	}
 
-	tpc := new(controllers.TopicsController)
 	tpcGroup := v1.Group("/topics")
 	{
 		tpcGroup.GET("", tpc.Index)
+		tpcGroup.GET("/search", tpc.Search)
+		// Deleted topics can be restored until `gohub prune` removes them
+		tpcGroup.GET("/trashed", middlewares.AuthJWT(), middlewares.LimitUser("user"), tpc.Trashed)
+		tpcGroup.POST("/:id/restore", middlewares.AuthJWT(), middlewares.LimitUser("user"), tpc.Restore)
 		tpcGroup.GET("/:id", tpc.Show)
-		tpcGroup.POST("", middlewares.AuthJWT(), tpc.Store)
-		tpcGroup.PUT("/:id", middlewares.AuthJWT(), tpc.Update)
-		tpcGroup.DELETE("/:id", middlewares.AuthJWT(), tpc.Delete)
+		tpcGroup.POST("", middlewares.AuthJWT(), middlewares.LimitUser("user"), middlewares.EnsureVerified(), tpc.Store)
+		tpcGroup.PUT("/:id", middlewares.AuthJWT(), middlewares.LimitUser("user"), tpc.Update)
+		tpcGroup.DELETE("/:id", middlewares.AuthJWT(), middlewares.LimitUser("user"), tpc.Delete)
+
+		vtc := new(controllers.VotesController)
+		tpcGroup.POST("/:id/vote", middlewares.AuthJWT(), middlewares.LimitUser("user"), vtc.Store)
+		tpcGroup.DELETE("/:id/vote", middlewares.AuthJWT(), middlewares.LimitUser("user"), vtc.Delete)
+
+		rpc := new(controllers.RepliesController)
+		tpcGroup.GET("/:id/replies", rpc.Index)
+		tpcGroup.POST("/:id/replies", middlewares.AuthJWT(), middlewares.LimitUser("user"), rpc.Store)
+		tpcGroup.DELETE("/:id/replies/:reply_id", middlewares.AuthJWT(), middlewares.LimitUser("user"), rpc.Delete)
 	}
 
 	lsc := new(controllers.LinksController)
 	linksGroup := v1.Group("/links")
 	{
 		linksGroup.GET("", lsc.Index)
+		linksGroup.POST("", middlewares.AuthJWT(), middlewares.LimitUser("user"), middlewares.Can(permission.LinksManage), lsc.Store)
+		linksGroup.PUT("/:id", middlewares.AuthJWT(), middlewares.LimitUser("user"), middlewares.Can(permission.LinksManage), lsc.Update)
+		linksGroup.DELETE("/:id", middlewares.AuthJWT(), middlewares.LimitUser("user"), middlewares.Can(permission.LinksManage), lsc.Delete)
 	}
 }
@@ -2,10 +2,18 @@ package routes_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"gohub/app/limiter"
+	"gohub/app/verifycode"
 	"gohub/pkg/auth"
+	"gohub/pkg/config"
 	"gohub/tests"
 )
 
@@ -81,23 +89,56 @@ func TestAuthSignupUsingEmail(t *testing.T) {
 	tests.ResetState(t)
 	router := tests.NewRouter()
 
-	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/signup/using-email", map[string]any{
-		"email":            "user@testing.com",
-		"verify_code":      "123456",
-		"name":             "emailuser",
-		"password":         "password123",
-		"password_confirm": "password123",
-	}, nil)
+	signup := func(email, verifyCode string) *httptest.ResponseRecorder {
+		body := map[string]any{
+			"email":            email,
+			"name":             strings.Split(email, "@")[0],
+			"password":         "password123",
+			"password_confirm": "password123",
+		}
+		if verifyCode != "" {
+			body["verify_code"] = verifyCode
+		}
+		return tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/signup/using-email", body, nil)
+	}
+	signedUp := func(rec *httptest.ResponseRecorder) (emailVerified bool) {
+		t.Helper()
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var payload struct {
+			Data struct {
+				Token         string `json:"token"`
+				EmailVerified bool   `json:"email_verified"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		if payload.Data.Token == "" {
+			t.Fatalf("expected token in response")
+		}
+		return payload.Data.EmailVerified
+	}
 
-	if rec.Code != http.StatusCreated {
-		t.Fatalf("expected 201, got %d", rec.Code)
+	// The code verifies the email right away
+	if !signedUp(signup("codeuser@testing.com", "123456")) {
+		t.Fatalf("expected the email to be verified by the code")
 	}
 
-	var payload map[string]any
-	tests.DecodeJSON(t, rec, &payload)
-	data, _ := payload["data"].(map[string]any)
-	if data["token"] == "" {
-		t.Fatalf("expected token in response")
+	// A wrong code is refused, it does not fall back to the link
+	if rec := signup("wrongcode@example.com", "654321"); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a wrong code, got %d", rec.Code)
+	}
+	if rec := signup("shortcode@testing.com", "123"); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a malformed code, got %d", rec.Code)
+	}
+
+	// Without a code the account is created unverified and the activation link is emailed
+	driver := mailQueue(t)
+	if signedUp(signup("linkuser@testing.com", "")) {
+		t.Fatalf("expected the email to wait for the link")
+	}
+	if to, _ := queuedLink(t, driver); to != "linkuser@testing.com" {
+		t.Fatalf("expected the activation link to be emailed, got %q", to)
 	}
 }
 
@@ -169,16 +210,70 @@ func TestAuthLoginAndRefresh(t *testing.T) {
 	tests.DecodeJSON(t, rec, &loginPayload)
 	data, _ := loginPayload["data"].(map[string]any)
 	token, _ := data["token"].(string)
-	if token == "" {
-		t.Fatalf("expected token")
+	refreshToken, _ := data["refresh_token"].(string)
+	if token == "" || refreshToken == "" {
+		t.Fatalf("expected token and refresh_token")
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/refresh-token", map[string]any{
+		"refresh_token": refreshToken,
+	}, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
 	}
 
-	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/refresh-token", nil, map[string]string{
+	// The refresh token is rotated, using it again is rejected
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/refresh-token", map[string]any{
+		"refresh_token": refreshToken,
+	}, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestAuthLogout(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "logoutuser"})
+	token := tests.IssueToken(user)
+	otherToken := tests.IssueToken(user)
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/logout", nil, map[string]string{
 		"Authorization": "Bearer " + token,
 	})
 	if rec.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", rec.Code)
 	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+
+	// Other logins are not affected
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, map[string]string{
+		"Authorization": "Bearer " + otherToken,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/logout-all", nil, map[string]string{
+		"Authorization": "Bearer " + otherToken,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, map[string]string{
+		"Authorization": "Bearer " + otherToken,
+	})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
 }
 
 func TestAuthPasswordReset(t *testing.T) {
@@ -215,3 +310,225 @@ func TestAuthPasswordReset(t *testing.T) {
 		t.Fatalf("expected password reset to succeed: %v", err)
 	}
 }
+
+func TestAuthVerifyCodeAttempts(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Phone: "13800138000"})
+	verifycode.NewVerifyCode().Store.Set(user.Phone, "654321")
+
+	login := func(code string) *httptest.ResponseRecorder {
+		return tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-phone", map[string]any{
+			"phone":       user.Phone,
+			"verify_code": code,
+		}, nil)
+	}
+
+	for attemptsLeft := 4; attemptsLeft > 0; attemptsLeft-- {
+		rec := login("000000")
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected 422, got %d", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), fmt.Sprintf("%d attempts left", attemptsLeft)) {
+			t.Fatalf("expected %d attempts left, got %s", attemptsLeft, rec.Body.String())
+		}
+	}
+
+	// The last wrong answer invalidates the code, the right one is rejected afterwards
+	if rec := login("000000"); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	rec := login("654321")
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "request a new one") {
+		t.Fatalf("expected the code to be invalidated, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// A new code starts over
+	verifycode.NewVerifyCode().Store.Set(user.Phone, "654321")
+	if rec := login("654321"); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestAuthLoginLockout(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "lockoutuser", Password: "password123"})
+
+	login := func(password string) *httptest.ResponseRecorder {
+		return tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+			"login_id":       user.Name,
+			"password":       password,
+			"captcha_id":     "captcha_skip_test",
+			"captcha_answer": "123456",
+		}, nil)
+	}
+
+	for i := 0; i < 4; i++ {
+		if rec := login("wrongpassword"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", rec.Code)
+		}
+	}
+
+	rec := login("wrongpassword")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected Retry-After 60, got %q", rec.Header().Get("Retry-After"))
+	}
+
+	// Locked even with the right password
+	rec = login("password123")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+	var payload map[string]any
+	tests.DecodeJSON(t, rec, &payload)
+	if payload["code"] != "ERR_TOO_MANY_REQUESTS" {
+		t.Fatalf("expected ERR_TOO_MANY_REQUESTS, got %v", payload["code"])
+	}
+
+	// Each further failure doubles the lockout
+	if retryAfter, _ := limiter.NewLoginLockout(context.Background(), user.Name, "192.0.2.1").Fail(context.Background()); retryAfter != 2*time.Minute {
+		t.Fatalf("expected a 2m lockout, got %s", retryAfter)
+	}
+
+	// The owner logs in from another IP, which only gets locked by a wrong password of its own
+	fromOwner := func(password string) *httptest.ResponseRecorder {
+		return tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+			"login_id":       user.Name,
+			"password":       password,
+			"captcha_id":     "captcha_skip_test",
+			"captcha_answer": "123456",
+		}, map[string]string{"X-Forwarded-For": "198.51.100.7"})
+	}
+	// Past the count of the account, a wrong password locks any IP at once
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+		"login_id":       user.Name,
+		"password":       "wrongpassword",
+		"captcha_id":     "captcha_skip_test",
+		"captcha_answer": "123456",
+	}, map[string]string{"X-Forwarded-For": "203.0.113.9"})
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for a wrong password from a new IP, got %d", rec.Code)
+	}
+	if rec = fromOwner("password123"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the owner to log in from another IP, got %d", rec.Code)
+	}
+
+	// Other accounts are not affected, a success clears the failures
+	other := tests.SeedUser(t, tests.UserParams{Name: "otheruser", Password: "password123"})
+	for i := 0; i < 2; i++ {
+		lockout := limiter.NewLoginLockout(context.Background(), other.Name, "192.0.2.1")
+		if retryAfter, _ := lockout.Fail(context.Background()); retryAfter > 0 {
+			t.Fatalf("expected no lockout yet")
+		}
+	}
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+		"login_id":       other.Name,
+		"password":       "password123",
+		"captcha_id":     "captcha_skip_test",
+		"captcha_answer": "123456",
+	}, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if _, attemptsLeft := limiter.NewLoginLockout(context.Background(), other.Name, "192.0.2.1").Fail(context.Background()); attemptsLeft != 4 {
+		t.Fatalf("expected failures to be cleared, %d attempts left", attemptsLeft)
+	}
+}
+
+func TestAuthLoginLockoutIdentifiers(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "manyids", Email: "manyids@testing.com", Phone: "00012345678", Password: "password123"})
+	login := func(loginID string) int {
+		return tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+			"login_id":       loginID,
+			"password":       "wrongpassword",
+			"captcha_id":     "captcha_skip_test",
+			"captcha_answer": "123456",
+		}, nil).Code
+	}
+
+	// The name, email and phone number of the account share the failures
+	for _, loginID := range []string{user.Name, user.Email, user.Phone, user.Name} {
+		if code := login(loginID); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", code)
+		}
+	}
+	if code := login(user.Email); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the account to be locked across its identifiers, got %d", code)
+	}
+
+	// Logins of no account are counted by the identifier
+	for i := 0; i < 4; i++ {
+		if code := login("Nobody"); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", code)
+		}
+	}
+	if code := login(" nobody "); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the unknown identifier to be locked, got %d", code)
+	}
+}
+
+func TestAuthVerifyCodeRateLimit(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	for i := 0; i < 20; i++ {
+		rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/verify-codes/captcha", map[string]any{}, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+	}
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/verify-codes/captcha", map[string]any{}, nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("expected Retry-After and RateLimit-Remaining 0, got %v", rec.Header())
+	}
+
+	// The budget is per route
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/signup/email/exist", map[string]any{
+		"email": "nobody@testing.com",
+	}, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestAuthRouteLimitOverride(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	config.Set("limit.routes", map[string]any{"post /api/v1/auth/verify-codes/captcha": "2-H"})
+	t.Cleanup(func() { config.Set("limit.routes", map[string]any{}) })
+
+	for i := 0; i < 2; i++ {
+		rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/verify-codes/captcha", map[string]any{}, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+	}
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/verify-codes/captcha", map[string]any{}, nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 after the overridden limit, got %d", rec.Code)
+	}
+
+	// The override only replaces the per-route policy, the requests still count against the global limit
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/categories", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	limit, _ := strconv.Atoi(rec.Header().Get("RateLimit-Limit"))
+	if remaining := rec.Header().Get("RateLimit-Remaining"); remaining != strconv.Itoa(limit-4) {
+		t.Fatalf("expected %d of %d global requests remaining, got %s", limit-4, limit, remaining)
+	}
+}
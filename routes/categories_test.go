@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"testing"
 
+	"gohub/app/models/permission"
+	"gohub/app/models/role"
 	"gohub/tests"
 )
 
@@ -25,6 +27,7 @@ func TestCategoriesStoreUpdateDelete(t *testing.T) {
 	router := tests.NewRouter()
 
 	user := tests.SeedUser(t, tests.UserParams{Name: "catuser"})
+	tests.GrantRole(t, user, role.Admin, permission.CategoriesManage)
 	token := tests.IssueToken(user)
 
 	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/categories", map[string]any{
@@ -57,11 +60,30 @@ func TestCategoriesStoreUpdateDelete(t *testing.T) {
 	}
 }
 
+func TestCategoriesStoreForbidden(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "catuser"})
+	token := tests.IssueToken(user)
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/categories", map[string]any{
+		"name":        "cat",
+		"description": "desc",
+	}, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
+
 func TestCategoriesDeleteNotFound(t *testing.T) {
 	tests.ResetState(t)
 	router := tests.NewRouter()
 
 	user := tests.SeedUser(t, tests.UserParams{Name: "catuser"})
+	tests.GrantRole(t, user, role.Admin, permission.CategoriesManage)
 	token := tests.IssueToken(user)
 
 	rec := tests.DoJSON(t, router, http.MethodDelete, "/api/v1/categories/999999", nil, map[string]string{
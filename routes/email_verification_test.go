@@ -0,0 +1,256 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"gohub/app/models/user"
+	"gohub/app/verifyemail"
+	"gohub/pkg/config"
+	"gohub/pkg/mail"
+	"gohub/pkg/queue"
+	"gohub/tests"
+)
+
+// mailQueue The memory queue of the tests, without the emails of earlier tests
+func mailQueue(t *testing.T) *queue.MemoryDriver {
+	t.Helper()
+	driver, ok := queue.Queue.Driver.(*queue.MemoryDriver)
+	if !ok {
+		t.Fatalf("expected the memory queue driver, got %T", queue.Queue.Driver)
+	}
+	for {
+		if job, _ := driver.Pop(t.Context(), config.GetString("queue.default"), 0); job == nil {
+			break
+		}
+	}
+	return driver
+}
+
+var hrefPattern = regexp.MustCompile(`href="([^"]*/auth/email/verify[^"]*)"`)
+
+// queuedLink The request URI of the verification link in the next queued email, with its recipient
+func queuedLink(t *testing.T, driver *queue.MemoryDriver) (to, requestURI string) {
+	t.Helper()
+	recipient, link := queuedURL(t, driver)
+	return recipient, link.RequestURI()
+}
+
+// queuedURL The verification link in the next queued email, with its recipient
+func queuedURL(t *testing.T, driver *queue.MemoryDriver) (to string, link *url.URL) {
+	t.Helper()
+	job, err := driver.Pop(t.Context(), config.GetString("queue.default"), 10*time.Millisecond)
+	if err != nil || job == nil {
+		t.Fatalf("expected a queued email, got %v", err)
+	}
+	var email mail.Email
+	if err := json.Unmarshal(job.Payload, &email); err != nil {
+		t.Fatalf("decode email: %v", err)
+	}
+	match := hrefPattern.FindSubmatch(email.HTML)
+	if match == nil {
+		t.Fatalf("expected a verification link in %s", email.HTML)
+	}
+	link, err = url.Parse(html.UnescapeString(string(match[1])))
+	if err != nil {
+		t.Fatalf("parse link: %v", err)
+	}
+	return email.To[0], link
+}
+
+func TestEmailVerificationSignupLink(t *testing.T) {
+	tests.ResetState(t)
+	driver := mailQueue(t)
+	router := tests.NewRouter()
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "verified"})
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/signup/using-email", map[string]any{
+		"email":            "activate@testing.com",
+		"name":             "activateuser",
+		"password":         "password123",
+		"password_confirm": "password123",
+	}, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body.String())
+	}
+	var signup struct {
+		Data struct {
+			Token         string `json:"token"`
+			EmailVerified bool   `json:"email_verified"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &signup)
+	if signup.Data.EmailVerified {
+		t.Fatalf("expected an unverified email without a code")
+	}
+	headers := map[string]string{"Authorization": "Bearer " + signup.Data.Token}
+
+	storeTopic := func() int {
+		return tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics", map[string]any{
+			"title":       "new topic",
+			"body":        "this is a new topic body",
+			"category_id": category.GetStringID(),
+		}, headers).Code
+	}
+	if code := storeTopic(); code != http.StatusForbidden {
+		t.Fatalf("expected 403 before the verification, got %d", code)
+	}
+
+	to, link := queuedLink(t, driver)
+	if to != "activate@testing.com" {
+		t.Fatalf("expected the email to activate@testing.com, got %s", to)
+	}
+
+	// The query is signed
+	if rec = tests.DoJSON(t, router, http.MethodGet, link+"x", nil, nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a changed link, got %d", rec.Code)
+	}
+	if rec = tests.DoJSON(t, router, http.MethodGet, link, nil, nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	if code := storeTopic(); code != http.StatusCreated {
+		t.Fatalf("expected 201 after the verification, got %d", code)
+	}
+}
+
+func TestEmailVerificationAPIDomain(t *testing.T) {
+	tests.ResetState(t)
+	driver := mailQueue(t)
+
+	// The routes are mounted at /v1 on the API domain
+	config.Set("app.api_domain", "api.testing.com")
+	t.Cleanup(func() { config.Set("app.api_domain", "") })
+	router := tests.NewRouter()
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/v1/auth/signup/using-email", map[string]any{
+		"email":            "apidomain@testing.com",
+		"name":             "apidomainuser",
+		"password":         "password123",
+		"password_confirm": "password123",
+	}, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	_, link := queuedURL(t, driver)
+	if link.Host != "api.testing.com" || link.Path != "/v1/auth/email/verify" {
+		t.Fatalf("expected a link to the API domain, got %s", link)
+	}
+	if rec = tests.DoJSON(t, router, http.MethodGet, link.RequestURI(), nil, nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if user.GetByEmail(t.Context(), "apidomain@testing.com").EmailVerifiedAt == nil {
+		t.Fatalf("expected the email to be verified")
+	}
+}
+
+func TestEmailVerificationCode(t *testing.T) {
+	tests.ResetState(t)
+	driver := mailQueue(t)
+	router := tests.NewRouter()
+
+	userModel := tests.SeedUser(t, tests.UserParams{Name: "codeuser", UnverifiedEmail: true})
+	headers := map[string]string{"Authorization": "Bearer " + tests.IssueToken(userModel)}
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/email/verification", nil, headers)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if to, _ := queuedLink(t, driver); to != userModel.Email {
+		t.Fatalf("expected the email to %s, got %s", userModel.Email, to)
+	}
+
+	if rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/email/verify", map[string]any{"verify_code": "123456"}, headers); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, headers)
+	var current map[string]any
+	tests.DecodeJSON(t, rec, &current)
+	if current["data"].(map[string]any)["email_verified"] != true {
+		t.Fatalf("expected email_verified, got %v", current["data"])
+	}
+
+	// Nothing to send any more
+	if rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/email/verification", nil, headers); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
+
+func TestEmailVerificationChange(t *testing.T) {
+	tests.ResetState(t)
+	driver := mailQueue(t)
+	router := tests.NewRouter()
+
+	userModel := tests.SeedUser(t, tests.UserParams{Name: "changeuser"})
+	headers := map[string]string{"Authorization": "Bearer " + tests.IssueToken(userModel)}
+
+	rec := tests.DoJSON(t, router, http.MethodPut, "/api/v1/users/email", map[string]any{
+		"email": "changed@testing.com",
+	}, headers)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Not changed before the link is opened
+	if email := user.Get(t.Context(), userModel.GetStringID()).Email; email != userModel.Email {
+		t.Fatalf("expected the email to stay %s, got %s", userModel.Email, email)
+	}
+
+	to, link := queuedLink(t, driver)
+	if to != "changed@testing.com" {
+		t.Fatalf("expected the email to the new address, got %s", to)
+	}
+	if rec = tests.DoJSON(t, router, http.MethodGet, link, nil, nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	changed := user.Get(t.Context(), userModel.GetStringID())
+	if changed.Email != "changed@testing.com" || !changed.HasVerifiedEmail() {
+		t.Fatalf("expected the verified new email, got %s %v", changed.Email, changed.EmailVerifiedAt)
+	}
+
+	// The link stops working once the email changed
+	if rec = tests.DoJSON(t, router, http.MethodGet, link, nil, nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a used link, got %d", rec.Code)
+	}
+
+	// Links of another address taken in the meantime are refused
+	other := tests.SeedUser(t, tests.UserParams{Name: "otheruser"})
+	stale, err := verifyemail.ChangeURL(changed, other.Email)
+	if err != nil {
+		t.Fatalf("change url: %v", err)
+	}
+	staleURL, _ := url.Parse(stale)
+	if rec = tests.DoJSON(t, router, http.MethodGet, staleURL.RequestURI(), nil, nil); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a taken email, got %d", rec.Code)
+	}
+}
+
+func TestEmailVerificationExpiredLink(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	userModel := tests.SeedUser(t, tests.UserParams{Name: "expireduser", UnverifiedEmail: true})
+
+	config.Set("verifycode.link_expire", -1)
+	link, err := verifyemail.VerifyURL(userModel)
+	config.Set("verifycode.link_expire", 60)
+	if err != nil {
+		t.Fatalf("verify url: %v", err)
+	}
+	linkURL, _ := url.Parse(link)
+
+	rec := tests.DoJSON(t, router, http.MethodGet, linkURL.RequestURI(), nil, nil)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+	if unverified := user.Get(t.Context(), userModel.GetStringID()); unverified.HasVerifiedEmail() {
+		t.Fatalf("expected the email to stay unverified")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"testing"
 
+	"gohub/app/models/permission"
+	"gohub/app/models/role"
 	"gohub/tests"
 )
 
@@ -18,3 +20,58 @@ func TestLinksIndex(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rec.Code)
 	}
 }
+
+func TestLinksStoreUpdateDelete(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	admin := tests.SeedUser(t, tests.UserParams{Name: "linkadmin"})
+	tests.GrantRole(t, admin, role.Admin, permission.LinksManage)
+	token := tests.IssueToken(admin)
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/links", map[string]any{
+		"name": "gohub",
+		"url":  "https://example.com",
+	}, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	link := tests.SeedLink(t, tests.LinkParams{Name: "old"})
+
+	rec = tests.DoJSON(t, router, http.MethodPut, "/api/v1/links/"+link.GetStringID(), map[string]any{
+		"name": "new name",
+		"url":  "https://example.org",
+	}, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/links/"+link.GetStringID(), nil, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestLinksStoreForbidden(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "linkuser"})
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/links", map[string]any{
+		"name": "gohub",
+		"url":  "https://example.com",
+	}, map[string]string{
+		"Authorization": "Bearer " + tests.IssueToken(user),
+	})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,180 @@
+package routes_test
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gohub/app/models/session"
+	"gohub/pkg/config"
+	"gohub/pkg/database"
+	"gohub/pkg/migrate"
+	"gohub/tests"
+	"gorm.io/gorm"
+)
+
+func TestMigrateSteps(t *testing.T) {
+	tests.ResetState(t)
+
+	migrator := migrate.NewMigrator()
+	statuses := migrator.Status()
+	require.NotEmpty(t, statuses)
+	for _, status := range statuses {
+		require.True(t, status.Ran, status.Name)
+		require.Equal(t, 1, status.Batch, status.Name)
+	}
+
+	// The last two migrations roll back although they ran in the same batch
+	require.NoError(t, migrator.RollbackStep(2))
+	statuses = migrator.Status()
+	last := len(statuses) - 1
+	require.False(t, statuses[last].Ran)
+	require.False(t, statuses[last-1].Ran)
+	require.True(t, statuses[last-2].Ran)
+	require.False(t, database.DB.Migrator().HasTable(&session.Session{}))
+
+	// Pretending prints the SQL and changes nothing
+	out := captureStdout(t, func() {
+		require.NoError(t, migrator.Pretend(1))
+	})
+	require.Contains(t, strings.ToLower(out), "email_verified_at")
+	require.NotContains(t, out, "sessions")
+	require.False(t, migrator.Status()[last-1].Ran)
+	require.False(t, database.DB.Migrator().HasColumn("users", "email_verified_at"))
+
+	// Each step runs in a batch of its own
+	require.NoError(t, migrator.UpStep(1))
+	require.NoError(t, migrator.UpStep(1))
+	statuses = migrator.Status()
+	require.Equal(t, 2, statuses[last-1].Batch)
+	require.Equal(t, 3, statuses[last].Batch)
+	require.True(t, database.DB.Migrator().HasTable(&session.Session{}))
+
+	require.NoError(t, migrator.Rollback())
+	require.False(t, migrator.Status()[last].Ran)
+	require.True(t, migrator.Status()[last-1].Ran)
+}
+
+func TestMigrateFailureRollsBack(t *testing.T) {
+	tests.ResetState(t)
+
+	type Widget struct {
+		ID   uint64
+		Name string
+	}
+	ranAfter := false
+	migrate.Add("2099_01_01_000000_add_widgets_table", func(migrator gorm.Migrator, DB *sql.DB) error {
+		if err := migrator.AutoMigrate(&Widget{}); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	}, nil)
+	migrate.Add("2099_01_01_000001_after_widgets", func(migrator gorm.Migrator, DB *sql.DB) error {
+		ranAfter = true
+		return nil
+	}, nil)
+
+	folder := t.TempDir()
+	for _, name := range []string{"2099_01_01_000000_add_widgets_table.go", "2099_01_01_000001_after_widgets.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(folder, name), nil, 0o644))
+	}
+	migrator := migrate.NewMigrator()
+	migrator.Folder = folder
+
+	err := migrator.Up()
+	require.ErrorContains(t, err, "boom")
+	require.False(t, ranAfter, "the batch stops at the failing migration")
+	require.False(t, database.DB.Migrator().HasTable(&Widget{}), "the table of the failed migration is rolled back")
+	for _, status := range migrator.Status() {
+		require.False(t, status.Ran, status.Name)
+	}
+}
+
+func TestMigrateLock(t *testing.T) {
+	tests.ResetState(t)
+
+	config.Set("database.migration_lock_timeout", 0)
+	t.Cleanup(func() { config.Set("database.migration_lock_timeout", 60) })
+
+	// A migrator that was killed left its lock behind
+	lockFile := config.GetString("database.sqlite.database") + ".migrate.lock"
+	require.NoError(t, os.WriteFile(lockFile, []byte("pid 1"), 0o644))
+	t.Cleanup(func() { _ = os.Remove(lockFile) })
+
+	migrator := migrate.NewMigrator()
+	require.ErrorIs(t, migrator.Up(), migrate.ErrLockTimeout)
+	require.ErrorIs(t, migrator.RollbackStep(1), migrate.ErrLockTimeout)
+	require.True(t, migrator.Status()[len(migrator.Status())-1].Ran, "nothing was rolled back")
+
+	require.NoError(t, migrator.Unlock())
+	require.NoFileExists(t, lockFile)
+	require.NoError(t, migrator.Up())
+	require.NoFileExists(t, lockFile, "the lock is released after the migration")
+
+	// Another migrator finishes while this one waits
+	config.Set("database.migration_lock_timeout", 5)
+	require.NoError(t, os.WriteFile(lockFile, []byte("pid 1"), 0o644))
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = os.Remove(lockFile)
+	}()
+	require.NoError(t, migrator.Up())
+}
+
+func TestMigrateDumpAndLoad(t *testing.T) {
+	tests.ResetState(t)
+
+	migrator := migrate.NewMigrator()
+	migrator.SchemaPath = filepath.Join(t.TempDir(), "sqlite-schema.sql")
+	require.NoError(t, migrator.RollbackStep(1))
+	require.NoError(t, migrator.Dump())
+
+	content, err := os.ReadFile(migrator.SchemaPath)
+	require.NoError(t, err)
+	dump := string(content)
+	require.Contains(t, dump, "CREATE TABLE `users`")
+	require.Contains(t, dump, "CREATE VIRTUAL TABLE")
+	require.NotContains(t, dump, "topics_fts_content", "shadow tables come with the virtual table")
+	require.NotContains(t, dump, "CREATE TABLE `migrations`")
+	require.Contains(t, dump, "VALUES ('2022_12_24_210856_add_users_table', 1);")
+	require.NotContains(t, dump, "add_sessions_table")
+
+	// A new database starts from the dump and only runs the newer migration
+	require.NoError(t, migrator.Fresh())
+	statuses := migrator.Status()
+	last := len(statuses) - 1
+	for _, status := range statuses[:last] {
+		require.True(t, status.Ran, status.Name)
+		require.Equal(t, 1, status.Batch, status.Name)
+	}
+	require.True(t, statuses[last].Ran)
+	require.Equal(t, 2, statuses[last].Batch)
+	require.True(t, database.DB.Migrator().HasTable("topics_fts"))
+	require.True(t, database.DB.Migrator().HasColumn("users", "email_verified_at"))
+	require.True(t, database.DB.Migrator().HasTable(&session.Session{}))
+
+	// The dump is only loaded into an empty database
+	require.NoError(t, migrator.Up())
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	require.NoError(t, writer.Close())
+	out, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(out)
+}
@@ -0,0 +1,183 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gohub/app/models/notification"
+	"gohub/app/models/topic"
+	"gohub/pkg/config"
+	"gohub/pkg/database"
+	"gohub/pkg/mail"
+	"gohub/tests"
+)
+
+type notificationsPayload struct {
+	Data struct {
+		Items []struct {
+			ID     uint64  `json:"id"`
+			Type   string  `json:"type"`
+			Title  string  `json:"title"`
+			ReadAt *string `json:"read_at"`
+			Actor  struct {
+				Name string `json:"name"`
+			} `json:"actor"`
+		} `json:"items"`
+		Total int64 `json:"total"`
+	} `json:"data"`
+}
+
+func TestNotifications(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	author := tests.SeedUser(t, tests.UserParams{Name: "author"})
+	replier := tests.SeedUser(t, tests.UserParams{Name: "replier"})
+	bystander := tests.SeedUser(t, tests.UserParams{Name: "bystander"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "notecat"})
+	topicModel := tests.SeedTopic(t, author, category, tests.TopicParams{Title: "hello world", Body: "cc @bystander"})
+	authorAuth := map[string]string{"Authorization": "Bearer " + tests.IssueToken(author)}
+	replierAuth := map[string]string{"Authorization": "Bearer " + tests.IssueToken(replier)}
+	bystanderAuth := map[string]string{"Authorization": "Bearer " + tests.IssueToken(bystander)}
+
+	// The author mentioning themselves or replying to their own topic is not notified
+	first := tests.SeedReply(t, replier, topicModel, tests.ReplyParams{Body: "nice, @author and @replier"})
+	tests.SeedReply(t, author, topicModel, tests.ReplyParams{Body: "thanks @author", ParentID: first.ID})
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+topicModel.GetStringID()+"/vote", nil, replierAuth)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	list := func(query string, headers map[string]string) notificationsPayload {
+		t.Helper()
+		rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/user/notifications"+query, nil, headers)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var payload notificationsPayload
+		tests.DecodeJSON(t, rec, &payload)
+		return payload
+	}
+
+	// A reply that also mentions the author notifies once, as a reply
+	var types []string
+	for _, item := range list("", authorAuth).Data.Items {
+		types = append(types, item.Type)
+		if item.Actor.Name != "replier" || item.Title != "hello world" {
+			t.Fatalf("unexpected notification %+v", item)
+		}
+	}
+	if fmt.Sprint(types) != "[reply vote]" {
+		t.Fatalf("expected reply and vote notifications for the author, got %v", types)
+	}
+	if items := list("", replierAuth).Data.Items; len(items) != 1 || items[0].Type != "answer" {
+		t.Fatalf("expected an answer notification for the replier, got %+v", items)
+	}
+	if items := list("", bystanderAuth).Data.Items; len(items) != 1 || items[0].Type != "mention" {
+		t.Fatalf("expected a mention notification for the bystander, got %+v", items)
+	}
+
+	unreadCount := func() int64 {
+		t.Helper()
+		rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, authorAuth)
+		var payload struct {
+			Data struct {
+				Name                string `json:"name"`
+				UnreadNotifications int64  `json:"unread_notifications"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		if payload.Data.Name != "author" {
+			t.Fatalf("expected the current user, got %+v", payload.Data)
+		}
+		return payload.Data.UnreadNotifications
+	}
+	if got := unreadCount(); got != 2 {
+		t.Fatalf("expected 2 unread notifications, got %d", got)
+	}
+
+	firstID := fmt.Sprint(list("", authorAuth).Data.Items[0].ID)
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/notifications/"+firstID+"/read", nil, replierAuth)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for the notification of another user, got %d", rec.Code)
+	}
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/notifications/"+firstID+"/read", nil, authorAuth)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := unreadCount(); got != 1 {
+		t.Fatalf("expected 1 unread notification, got %d", got)
+	}
+	if items := list("?unread=1", authorAuth).Data.Items; len(items) != 1 || items[0].Type != "vote" {
+		t.Fatalf("expected only the vote to be unread, got %+v", items)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/notifications/read-all", nil, authorAuth)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := unreadCount(); got != 0 {
+		t.Fatalf("expected no unread notifications, got %d", got)
+	}
+
+	// Voting again after taking the vote back does not notify twice
+	tests.DoJSON(t, router, http.MethodDelete, "/api/v1/topics/"+topicModel.GetStringID()+"/vote", nil, replierAuth)
+	tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+topicModel.GetStringID()+"/vote", nil, replierAuth)
+	if got := unreadCount(); got != 0 {
+		t.Fatalf("expected no new notification, got %d", got)
+	}
+
+	if rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/user/notifications?unread=maybe", nil, authorAuth); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+}
+
+func TestNotificationsMail(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+	queued := mailQueue(t)
+
+	config.Set("notification.mail", true)
+	t.Cleanup(func() { config.Set("notification.mail", false) })
+
+	author := tests.SeedUser(t, tests.UserParams{Name: "author"})
+	replier := tests.SeedUser(t, tests.UserParams{Name: "replier"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "mailcat"})
+	topicModel := tests.SeedTopic(t, author, category, tests.TopicParams{Title: "hello mail"})
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+topicModel.GetStringID()+"/replies", map[string]any{
+		"body": "a reply worth a mail",
+	}, map[string]string{"Authorization": "Bearer " + tests.IssueToken(replier)})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	job, err := queued.Pop(t.Context(), config.GetString("queue.default"), 10*time.Millisecond)
+	if err != nil || job == nil {
+		t.Fatalf("expected a queued email, got %v", err)
+	}
+	var email mail.Email
+	if err := json.Unmarshal(job.Payload, &email); err != nil {
+		t.Fatalf("decode email: %v", err)
+	}
+	if len(email.To) != 1 || email.To[0] != author.Email || email.Subject != `replier replied to your topic "hello mail"` {
+		t.Fatalf("unexpected email %+v", email)
+	}
+
+	// A notification rolled back with its topic is not mailed, the failing search index aborts the create
+	database.DB.Exec("DROP TABLE topics_fts")
+	failed := topic.Topic{Title: "rolled back", Body: "hi @author", UserID: replier.GetStringID(), CategoryID: category.GetStringID()}
+	failed.Create(t.Context())
+
+	var stored int64
+	database.DB.Model(&notification.Notification{}).Where("topic_id = ?", failed.GetStringID()).Count(&stored)
+	if stored != 0 {
+		t.Fatalf("expected the notification to be rolled back, got %d", stored)
+	}
+	if job, _ := queued.Pop(t.Context(), config.GetString("queue.default"), 10*time.Millisecond); job != nil {
+		t.Fatalf("expected no email for the rolled back notification, got %s", job.Payload)
+	}
+}
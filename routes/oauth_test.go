@@ -0,0 +1,355 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"gohub/app/models/socialaccount"
+	"gohub/app/models/user"
+	"gohub/pkg/config"
+	"gohub/pkg/database"
+	"gohub/tests"
+)
+
+// fakeGitHub A GitHub-like provider, the code "user-<id>" signs in as the account <id>,
+// whose primary email octocat<id>@example.com is verified unless the id is odd
+func fakeGitHub(t *testing.T) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := strings.CutPrefix(r.FormValue("code"), "user-")
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": id})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": json.Number(id), "login": "octocat"})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		verified := !strings.HasSuffix(id, "1") && !strings.HasSuffix(id, "3") && !strings.HasSuffix(id, "5")
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"email": "octocat" + id + "@example.com", "primary": true, "verified": verified},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config.Set("oauth.github.client_id", "client")
+	config.Set("oauth.github.client_secret", "secret")
+	config.Set("oauth.github.auth_url", server.URL+"/authorize")
+	config.Set("oauth.github.token_url", server.URL+"/token")
+	config.Set("oauth.github.user_url", server.URL+"/user")
+	config.Set("oauth.github.emails_url", server.URL+"/user/emails")
+}
+
+// oauthState The state of the provider URL
+func oauthState(t *testing.T, location string) string {
+	t.Helper()
+	authURL, err := url.Parse(location)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	return authURL.Query().Get("state")
+}
+
+// oauthCookie The nonce cookie set with the state, the browser sends it back to the callback
+func oauthCookie(t *testing.T, rec *httptest.ResponseRecorder) map[string]string {
+	t.Helper()
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == "oauth_nonce" && cookie.Value != "" {
+			return map[string]string{"Cookie": cookie.Name + "=" + cookie.Value}
+		}
+	}
+	t.Fatalf("expected the oauth_nonce cookie")
+	return nil
+}
+
+func TestOAuthAPIDomain(t *testing.T) {
+	tests.ResetState(t)
+	fakeGitHub(t)
+
+	// The routes are mounted at /v1 on the API domain
+	config.Set("app.api_domain", "api.testing.com")
+	t.Cleanup(func() { config.Set("app.api_domain", "") })
+	router := tests.NewRouter()
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/v1/auth/oauth/github/redirect", nil, nil)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("expected 302, got %d", rec.Code)
+	}
+	authURL, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	if callback := authURL.Query().Get("redirect_uri"); callback != "http://api.testing.com/v1/auth/oauth/github/callback" {
+		t.Fatalf("expected the callback on the API domain, got %s", callback)
+	}
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == "oauth_nonce" && cookie.Path != "/v1/auth/oauth" {
+			t.Fatalf("expected the nonce cookie for the callbacks, got path %s", cookie.Path)
+		}
+	}
+
+	state := oauthState(t, rec.Header().Get("Location"))
+	rec = tests.DoJSON(t, router, http.MethodGet, "/v1/auth/oauth/github/callback?code=user-42&state="+state, nil, oauthCookie(t, rec))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestOAuthSignIn(t *testing.T) {
+	tests.ResetState(t)
+	fakeGitHub(t)
+	router := tests.NewRouter()
+
+	signIn := func(code string) *httptest.ResponseRecorder {
+		rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/redirect", nil, nil)
+		if rec.Code != http.StatusFound {
+			t.Fatalf("expected 302, got %d", rec.Code)
+		}
+		state := oauthState(t, rec.Header().Get("Location"))
+		return tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code="+code+"&state="+state, nil, oauthCookie(t, rec))
+	}
+
+	rec := signIn("user-42")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body.String())
+	}
+	var payload struct {
+		Data struct {
+			Token string `json:"token"`
+			User  struct {
+				ID   json.Number `json:"id"`
+				Name string      `json:"name"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &payload)
+	if payload.Data.Token == "" || payload.Data.User.Name != "octocat" {
+		t.Fatalf("expected a token for octocat, got %s", rec.Body.String())
+	}
+	userID := payload.Data.User.ID
+
+	// Signing in again finds the linked user
+	rec = signIn("user-42")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	tests.DecodeJSON(t, rec, &payload)
+	if payload.Data.User.ID != userID {
+		t.Fatalf("expected user %s, got %s", userID, payload.Data.User.ID)
+	}
+
+	// Another account with the same login gets a name of its own
+	rec = signIn("user-43")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	tests.DecodeJSON(t, rec, &payload)
+	if payload.Data.User.Name == "octocat" || !strings.HasPrefix(payload.Data.User.Name, "octocat") {
+		t.Fatalf("expected a new name, got %s", payload.Data.User.Name)
+	}
+
+	// A verified email signs in as the user who has it
+	existing := tests.SeedUser(t, tests.UserParams{Name: "emailowner", Email: "octocat44@example.com"})
+	rec = signIn("user-44")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	tests.DecodeJSON(t, rec, &payload)
+	if payload.Data.User.ID.String() != existing.GetStringID() {
+		t.Fatalf("expected user %s, got %s", existing.GetStringID(), payload.Data.User.ID)
+	}
+
+	// Whoever signed up with the email without verifying it does not get the account
+	squatter := tests.SeedUser(t, tests.UserParams{Name: "squatter", Email: "octocat46@example.com", UnverifiedEmail: true})
+	rec = signIn("user-46")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body.String())
+	}
+	tests.DecodeJSON(t, rec, &payload)
+	if payload.Data.User.ID.String() == squatter.GetStringID() {
+		t.Fatalf("expected a new user, got the squatter %s", squatter.GetStringID())
+	}
+	var linked int64
+	database.DB.Model(&socialaccount.SocialAccount{}).Where("user_id = ?", squatter.GetStringID()).Count(&linked)
+	if linked != 0 {
+		t.Fatalf("expected no account linked to the squatter, got %d", linked)
+	}
+	var withEmail int64
+	database.DB.Model(&user.User{}).Where("email = ?", "octocat46@example.com").Count(&withEmail)
+	if withEmail != 1 {
+		t.Fatalf("expected the new user without the taken email, got %d users with it", withEmail)
+	}
+
+	// An unverified email is not trusted
+	tests.SeedUser(t, tests.UserParams{Name: "unverified", Email: "octocat45@example.com"})
+	rec = signIn("user-45")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	// States are used once
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/redirect", nil, nil)
+	state := oauthState(t, rec.Header().Get("Location"))
+	cookie := oauthCookie(t, rec)
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code=user-42&state="+state, nil, cookie)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code=user-42&state="+state, nil, cookie)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+
+	// Parallel callbacks with the same state, only one of them signs in
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/redirect", nil, nil)
+	state = oauthState(t, rec.Header().Get("Location"))
+	cookie = oauthCookie(t, rec)
+	codes := make(chan int, 4)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code=user-42&state="+state, nil, cookie).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+	succeeded := 0
+	for code := range codes {
+		if code == http.StatusOK {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected one callback to sign in, got %d", succeeded)
+	}
+
+	// A state is only accepted from the browser that started the redirect
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/redirect", nil, nil)
+	state = oauthState(t, rec.Header().Get("Location"))
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code=user-42&state="+state, nil, map[string]string{
+		"Cookie": "oauth_nonce=other",
+	})
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+
+	// A wrong code or a refused sign in
+	if rec = signIn("wrong"); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?error=access_denied", nil, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+
+	// Providers without a client_id are not enabled
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/google/redirect", nil, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestOAuthLinkAndUnlink(t *testing.T) {
+	tests.ResetState(t)
+	fakeGitHub(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "linker", Email: "linker@testing.com"})
+	headers := map[string]string{"Authorization": "Bearer " + tests.IssueToken(user)}
+
+	link := func(code string) *httptest.ResponseRecorder {
+		rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/social-accounts/github", nil, headers)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		var payload struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		state := oauthState(t, payload.Data.URL)
+		return tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code="+code+"&state="+state, nil, oauthCookie(t, rec))
+	}
+
+	// A link URL opened in another browser, such as one sent to a victim, links nothing
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/social-accounts/github", nil, headers)
+	var linkURL struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &linkURL)
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code=user-53&state="+oauthState(t, linkURL.Data.URL), nil, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := link("user-50"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body.String())
+	}
+	// Linking the same account again is fine, another one of the provider is not
+	if rec := link("user-50"); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec := link("user-52"); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user/social-accounts", nil, headers)
+	var list struct {
+		Data []map[string]any `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &list)
+	if len(list.Data) != 1 || list.Data[0]["provider_user_id"] != "50" {
+		t.Fatalf("expected the linked account, got %s", rec.Body.String())
+	}
+
+	// Signing in with the linked account
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/redirect", nil, nil)
+	state := oauthState(t, rec.Header().Get("Location"))
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code=user-50&state="+state, nil, oauthCookie(t, rec))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"name":"linker"`) {
+		t.Fatalf("expected to sign in as linker, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/user/social-accounts/github", nil, headers)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/user/social-accounts/github", nil, headers)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+
+	// A user who signed up with the provider and has no email keeps the only way in
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/redirect", nil, nil)
+	state = oauthState(t, rec.Header().Get("Location"))
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code=user-51&state="+state, nil, oauthCookie(t, rec))
+	var signIn struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &signIn)
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/user/social-accounts/github", nil, map[string]string{
+		"Authorization": "Bearer " + signIn.Data.Token,
+	})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,148 @@
+package routes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gohub/pkg/queue"
+	"gohub/tests"
+)
+
+func newWorker() *queue.Worker {
+	return &queue.Worker{
+		Queue:      "testing",
+		Tries:      3,
+		Backoff:    20 * time.Millisecond,
+		MaxBackoff: 40 * time.Millisecond,
+	}
+}
+
+func TestWorkerRunsJobs(t *testing.T) {
+	tests.ResetState(t)
+
+	var got []string
+	queue.Register("test.echo", func(_ context.Context, payload []byte) error {
+		got = append(got, string(payload))
+		return nil
+	})
+
+	require.NoError(t, queue.DispatchOn(t.Context(), "testing", "test.echo", "first"))
+	require.NoError(t, queue.DispatchOn(t.Context(), "testing", "test.echo", map[string]int{"n": 2}))
+
+	worker := newWorker()
+	for range 2 {
+		ran, err := worker.RunNext(t.Context())
+		require.NoError(t, err)
+		require.True(t, ran)
+	}
+	require.Equal(t, []string{`"first"`, `{"n":2}`}, got)
+
+	ran, err := worker.RunNext(t.Context())
+	require.NoError(t, err)
+	require.False(t, ran, "the queue is empty")
+}
+
+func TestWorkerBacksOffAndFails(t *testing.T) {
+	tests.ResetState(t)
+
+	attempts := 0
+	queue.Register("test.flaky", func(_ context.Context, _ []byte) error {
+		attempts++
+		if attempts == 2 {
+			panic("boom")
+		}
+		return errors.New("smtp unavailable")
+	})
+	require.NoError(t, queue.DispatchOn(t.Context(), "testing", "test.flaky", "payload"))
+
+	worker := newWorker()
+	ran, err := worker.RunNext(t.Context())
+	require.NoError(t, err)
+	require.True(t, ran)
+
+	// The retry waits for the backoff
+	ran, _ = worker.RunNext(t.Context())
+	require.False(t, ran)
+	worker.Timeout = 100 * time.Millisecond
+	for range 2 {
+		ran, err = worker.RunNext(t.Context())
+		require.NoError(t, err)
+		require.True(t, ran)
+	}
+	require.Equal(t, 3, attempts)
+
+	failed, err := queue.Failed(t.Context())
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	require.Equal(t, "test.flaky", failed[0].Type)
+	require.Equal(t, 3, failed[0].Attempts)
+	require.Equal(t, "smtp unavailable", failed[0].Error)
+
+	// Retried jobs start over with fresh attempts
+	queue.Register("test.flaky", func(_ context.Context, payload []byte) error {
+		require.Equal(t, `"payload"`, string(payload))
+		return nil
+	})
+	retried, err := queue.Retry(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 1, retried)
+
+	ran, err = worker.RunNext(t.Context())
+	require.NoError(t, err)
+	require.True(t, ran)
+	failed, _ = queue.Failed(t.Context())
+	require.Empty(t, failed)
+}
+
+func TestWorkerFailsJobsWithoutHandler(t *testing.T) {
+	tests.ResetState(t)
+
+	require.NoError(t, queue.DispatchOn(t.Context(), "testing", "test.unknown", nil))
+	ran, err := newWorker().RunNext(t.Context())
+	require.NoError(t, err)
+	require.True(t, ran)
+
+	failed, err := queue.Failed(t.Context())
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	require.Equal(t, 1, failed[0].Attempts, "a job nobody handles is not retried")
+}
+
+func TestWorkerKeepsJobsOnShutdown(t *testing.T) {
+	tests.ResetState(t)
+
+	// The signal of a deploy arrives while the job runs, its handler fails with the cancelled context
+	var stop context.CancelFunc
+	queue.Register("test.shutdown", func(ctx context.Context, _ []byte) error {
+		stop()
+		return ctx.Err()
+	})
+	driver := queue.Queue.Driver.(*queue.MemoryDriver)
+
+	worker := newWorker()
+	worker.Queue = "shutdown"
+	require.NoError(t, queue.DispatchOn(t.Context(), worker.Queue, "test.shutdown", nil))
+	ctx, cancel := context.WithCancel(t.Context())
+	stop = cancel
+	ran, err := worker.RunNext(ctx)
+	require.NoError(t, err)
+	require.True(t, ran)
+	require.Equal(t, 1, driver.Size(worker.Queue), "the job is pushed back for another attempt")
+
+	// Out of attempts it is stored in failed_jobs all the same
+	worker.Tries = 1
+	worker.Timeout = 100 * time.Millisecond
+	ctx, cancel = context.WithCancel(t.Context())
+	stop = cancel
+	ran, err = worker.RunNext(ctx)
+	require.NoError(t, err)
+	require.True(t, ran)
+
+	failed, err := queue.Failed(t.Context())
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	require.Equal(t, "test.shutdown", failed[0].Type)
+}
@@ -0,0 +1,114 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gohub/app/models/topic"
+	"gohub/tests"
+)
+
+func TestRepliesStoreAndIndex(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "replier"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "replycat"})
+	topicModel := tests.SeedTopic(t, user, category, tests.TopicParams{})
+	token := tests.IssueToken(user)
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+topicModel.GetStringID()+"/replies", map[string]any{
+		"body": "first reply",
+	}, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	var payload map[string]any
+	tests.DecodeJSON(t, rec, &payload)
+	data, _ := payload["data"].(map[string]any)
+	parentID, _ := data["id"].(float64)
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+topicModel.GetStringID()+"/replies", map[string]any{
+		"body":      "nested reply",
+		"parent_id": fmt.Sprintf("%d", int64(parentID)),
+	}, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	updated := topic.Get(t.Context(), topicModel.GetStringID())
+	if updated.ReplyCount != 2 || updated.LastReplyAt == nil {
+		t.Fatalf("expected reply_count=2 with last_reply_at, got %d %v", updated.ReplyCount, updated.LastReplyAt)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics/"+topicModel.GetStringID()+"/replies", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?sort=last_reply_at&order=desc", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestRepliesStoreParentFromOtherTopic(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "replier"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "replycat"})
+	first := tests.SeedTopic(t, user, category, tests.TopicParams{})
+	second := tests.SeedTopic(t, user, category, tests.TopicParams{})
+	parent := tests.SeedReply(t, user, first, tests.ReplyParams{})
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+second.GetStringID()+"/replies", map[string]any{
+		"body":      "misplaced reply",
+		"parent_id": parent.GetStringID(),
+	}, map[string]string{
+		"Authorization": "Bearer " + tests.IssueToken(user),
+	})
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+}
+
+func TestRepliesDelete(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	owner := tests.SeedUser(t, tests.UserParams{Name: "owner"})
+	author := tests.SeedUser(t, tests.UserParams{Name: "author"})
+	other := tests.SeedUser(t, tests.UserParams{Name: "other"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "replycat"})
+	topicModel := tests.SeedTopic(t, owner, category, tests.TopicParams{})
+	parent := tests.SeedReply(t, author, topicModel, tests.ReplyParams{})
+	_ = tests.SeedReply(t, owner, topicModel, tests.ReplyParams{ParentID: parent.ID})
+
+	path := "/api/v1/topics/" + topicModel.GetStringID() + "/replies/" + parent.GetStringID()
+
+	rec := tests.DoJSON(t, router, http.MethodDelete, path, nil, map[string]string{
+		"Authorization": "Bearer " + tests.IssueToken(other),
+	})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodDelete, path, nil, map[string]string{
+		"Authorization": "Bearer " + tests.IssueToken(owner),
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	updated := topic.Get(t.Context(), topicModel.GetStringID())
+	if updated.ReplyCount != 0 || updated.LastReplyAt != nil {
+		t.Fatalf("expected counters to be reset, got %d %v", updated.ReplyCount, updated.LastReplyAt)
+	}
+}
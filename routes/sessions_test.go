@@ -0,0 +1,184 @@
+package routes_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gohub/app/models/session"
+	"gohub/pkg/database"
+	"gohub/tests"
+)
+
+func TestSessions(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "sessionuser", Password: "password123"})
+
+	login := func(userAgent, device string) (token, refreshToken string) {
+		headers := map[string]string{"User-Agent": userAgent}
+		if device != "" {
+			headers["X-Device-Name"] = device
+		}
+		rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+			"login_id":       user.Name,
+			"password":       "password123",
+			"captcha_id":     "captcha_skip_test",
+			"captcha_answer": "123456",
+		}, headers)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+		}
+		var payload struct {
+			Data struct {
+				Token        string `json:"token"`
+				RefreshToken string `json:"refresh_token"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		return payload.Data.Token, payload.Data.RefreshToken
+	}
+
+	laptop, _ := login("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36", "")
+	phone, phoneRefresh := login("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Safari/604.1", "My phone")
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/user/sessions", nil, map[string]string{"Authorization": "Bearer " + laptop})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		Data []struct {
+			ID      uint64 `json:"id"`
+			Device  string `json:"device"`
+			IP      string `json:"ip"`
+			Current bool   `json:"current"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &list)
+	if len(list.Data) != 2 {
+		t.Fatalf("expected 2 sessions, got %s", rec.Body.String())
+	}
+	devices := map[string]bool{}
+	var phoneID uint64
+	for _, item := range list.Data {
+		devices[item.Device] = item.Current
+		if item.Device == "My phone" {
+			phoneID = item.ID
+		}
+	}
+	if current, ok := devices["Chrome on Windows"]; !ok || !current {
+		t.Fatalf("expected the current Chrome on Windows session, got %v", devices)
+	}
+	if current, ok := devices["My phone"]; !ok || current {
+		t.Fatalf("expected the other session named My phone, got %v", devices)
+	}
+
+	// Revoke the phone from the laptop
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/user/sessions/"+strconv.FormatUint(phoneID, 10), nil, map[string]string{"Authorization": "Bearer " + laptop})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, map[string]string{"Authorization": "Bearer " + phone})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for the revoked session, got %d", rec.Code)
+	}
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/refresh-token", map[string]any{"refresh_token": phoneRefresh}, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 refreshing the revoked session, got %d", rec.Code)
+	}
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, map[string]string{"Authorization": "Bearer " + laptop})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the laptop to stay logged in, got %d", rec.Code)
+	}
+
+	// Revoked sessions are gone from the list, and can not be revoked twice
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user/sessions", nil, map[string]string{"Authorization": "Bearer " + laptop})
+	tests.DecodeJSON(t, rec, &list)
+	if len(list.Data) != 1 {
+		t.Fatalf("expected 1 session, got %s", rec.Body.String())
+	}
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/user/sessions/"+strconv.FormatUint(phoneID, 10), nil, map[string]string{"Authorization": "Bearer " + laptop})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+
+	// Sessions of other users can not be revoked
+	other := tests.SeedUser(t, tests.UserParams{Name: "othersession"})
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/user/sessions/"+strconv.FormatUint(list.Data[0].ID, 10), nil,
+		map[string]string{"Authorization": "Bearer " + tests.IssueToken(other)})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestSessionsLogout(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "logoutuser"})
+	first, second, third := tests.IssueToken(user), tests.IssueToken(user), tests.IssueToken(user)
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/logout", nil, map[string]string{"Authorization": "Bearer " + first})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if active := session.ActiveOf(context.Background(), user.GetStringID()); len(active) != 2 {
+		t.Fatalf("expected 2 active sessions, got %d", len(active))
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/logout-all", nil, map[string]string{"Authorization": "Bearer " + second})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if active := session.ActiveOf(context.Background(), user.GetStringID()); len(active) != 0 {
+		t.Fatalf("expected no active sessions, got %d", len(active))
+	}
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, map[string]string{"Authorization": "Bearer " + third})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestSessionsCreateFailure(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "nosession", Phone: "00012345670", Password: "password123"})
+
+	// Without a session the tokens would be refused anyway, the login fails instead
+	if err := database.DB.Migrator().DropTable(&session.Session{}); err != nil {
+		t.Fatalf("drop sessions: %v", err)
+	}
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+		"login_id":       user.Name,
+		"password":       "password123",
+		"captcha_id":     "captcha_skip_test",
+		"captcha_answer": "123456",
+	}, nil)
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "token") {
+		t.Fatalf("expected 500 without tokens, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-phone", map[string]any{
+		"phone":       user.Phone,
+		"verify_code": "123456",
+	}, nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/signup/using-phone", map[string]any{
+		"phone":            "00012345671",
+		"verify_code":      "123456",
+		"name":             "nosession2",
+		"password":         "password123",
+		"password_confirm": "password123",
+	}, nil)
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "refresh_token") {
+		t.Fatalf("expected 500 without tokens, got %d %s", rec.Code, rec.Body.String())
+	}
+}
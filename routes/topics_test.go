@@ -3,8 +3,16 @@ package routes_test
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
+	"gohub/app/models"
+	"gohub/app/models/permission"
+	"gohub/app/models/reply"
+	"gohub/app/models/role"
+	"gohub/app/models/topic"
+	"gohub/pkg/database"
 	"gohub/tests"
 )
 
@@ -90,6 +98,37 @@ func TestTopicsUpdateForbidden(t *testing.T) {
 	}
 }
 
+func TestTopicsModeratorCanManage(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	owner := tests.SeedUser(t, tests.UserParams{Name: "owner"})
+	moderator := tests.SeedUser(t, tests.UserParams{Name: "moderator"})
+	tests.GrantRole(t, moderator, role.Moderator, permission.TopicsModerate)
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "cat3"})
+	topic := tests.SeedTopic(t, owner, category, tests.TopicParams{Title: "topic", Body: "topic body content"})
+
+	moderatorToken := tests.IssueToken(moderator)
+
+	rec := tests.DoJSON(t, router, http.MethodPut, "/api/v1/topics/"+topic.GetStringID(), map[string]any{
+		"title":       "moderated",
+		"body":        "moderated body content",
+		"category_id": category.GetStringID(),
+	}, map[string]string{
+		"Authorization": "Bearer " + moderatorToken,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/topics/"+topic.GetStringID(), nil, map[string]string{
+		"Authorization": "Bearer " + moderatorToken,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
 func TestTopicsDeleteNotFound(t *testing.T) {
 	tests.ResetState(t)
 	router := tests.NewRouter()
@@ -123,3 +162,470 @@ func TestTopicsStoreMissingCategory(t *testing.T) {
 		t.Fatalf("expected 422, got %d", rec.Code)
 	}
 }
+
+func TestTopicsSearch(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "searcher"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "searchcat"})
+	tests.SeedTopic(t, user, category, tests.TopicParams{Title: "gopher meetup", Body: "bring your gopher friends along"})
+	tests.SeedTopic(t, user, category, tests.TopicParams{Title: "unrelated", Body: "nothing to see in this topic"})
+	edited := tests.SeedTopic(t, user, category, tests.TopicParams{Title: "draft", Body: "placeholder body content"})
+	tests.SeedTopic(t, user, category, tests.TopicParams{Title: "markup", Body: `<img src=x onerror="alert(1)"> gopher`})
+
+	// Edits are picked up by the index hooks
+	edited.Body = "a late gopher sighting"
+	edited.Save(t.Context())
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics/search?q=gopher", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var payload struct {
+		Data struct {
+			Items []struct {
+				Title   string `json:"title"`
+				Snippet string `json:"snippet"`
+			} `json:"items"`
+			Total int64 `json:"total"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &payload)
+	if payload.Data.Total != 3 || len(payload.Data.Items) != 3 {
+		t.Fatalf("expected 3 matches, got %d", payload.Data.Total)
+	}
+	for _, item := range payload.Data.Items {
+		if !strings.Contains(item.Snippet, "<mark>gopher</mark>") {
+			t.Fatalf("expected highlighted snippet, got %q", item.Snippet)
+		}
+		// The body is escaped, only the highlight is markup
+		if item.Title == "markup" && !strings.Contains(item.Snippet, "&lt;img src=x onerror=&#34;alert(1)&#34;&gt;") {
+			t.Fatalf("expected escaped snippet, got %q", item.Snippet)
+		}
+	}
+
+	edited.Delete(t.Context())
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics/search?q=gopher", nil, nil)
+	tests.DecodeJSON(t, rec, &payload)
+	if payload.Data.Total != 2 {
+		t.Fatalf("expected deleted topic to leave the index, got %d matches", payload.Data.Total)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics/search", nil, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+}
+
+func TestTopicsFilters(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	alice := tests.SeedUser(t, tests.UserParams{Name: "alice"})
+	bob := tests.SeedUser(t, tests.UserParams{Name: "bob"})
+	golang := tests.SeedCategory(t, tests.CategoryParams{Name: "golang"})
+	rust := tests.SeedCategory(t, tests.CategoryParams{Name: "rust"})
+
+	day := func(date string) time.Time {
+		parsed, _ := time.ParseInLocation(time.DateOnly, date, time.UTC)
+		return parsed
+	}
+	tests.SeedTopic(t, alice, golang, tests.TopicParams{Title: "alice go", CreatedAt: day("2026-01-10")})
+	tests.SeedTopic(t, alice, rust, tests.TopicParams{Title: "alice rust", CreatedAt: day("2026-02-10")})
+	tests.SeedTopic(t, bob, golang, tests.TopicParams{Title: "bob go", CreatedAt: day("2026-03-10")})
+
+	total := func(path string) int64 {
+		t.Helper()
+		separator := "?"
+		if strings.Contains(path, "?") {
+			separator = "&"
+		}
+		rec := tests.DoJSON(t, router, http.MethodGet, path+separator+"with_total=1", nil, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+		var payload struct {
+			Data struct {
+				Total int64 `json:"total"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		return payload.Data.Total
+	}
+
+	cases := map[string]int64{
+		"/api/v1/topics": 3,
+		"/api/v1/topics?category_id=" + golang.GetStringID():                                  2,
+		"/api/v1/topics?user_id=" + alice.GetStringID():                                       2,
+		"/api/v1/topics?created_after=2026-02-10":                                             2,
+		"/api/v1/topics?created_before=2026-02-10":                                            1,
+		"/api/v1/topics?created_after=2026-02-01&created_before=2026-03-01":                   1,
+		"/api/v1/categories/" + golang.GetStringID() + "/topics":                              2,
+		"/api/v1/categories/" + golang.GetStringID() + "/topics?user_id=" + bob.GetStringID(): 1,
+		"/api/v1/users/" + alice.GetStringID() + "/topics":                                    2,
+		"/api/v1/users/" + alice.GetStringID() + "/topics?category_id=" + rust.GetStringID():  1,
+	}
+	for path, want := range cases {
+		if got := total(path); got != want {
+			t.Fatalf("%s: expected %d topics, got %d", path, want, got)
+		}
+	}
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?created_after=yesterday", nil, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?created_after=2026-03-01&created_before=2026-02-01", nil, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/categories/999/topics", nil, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestTopicsKeysetPagination(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "pager"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "pagecat"})
+	for i := 1; i <= 5; i++ {
+		tests.SeedTopic(t, user, category, tests.TopicParams{
+			Title:     fmt.Sprintf("topic %d", i),
+			CreatedAt: time.Date(2026, 1, 6-i, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	type page struct {
+		Titles     []string
+		NextCursor *string
+		PrevCursor *string
+		HasTotal   bool
+	}
+	get := func(query string) page {
+		t.Helper()
+		rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?sort=created_at&order=desc&limit=2"+query, nil, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		var payload struct {
+			Data struct {
+				Items []struct {
+					Title string `json:"title"`
+				} `json:"items"`
+				NextCursor *string `json:"next_cursor"`
+				PrevCursor *string `json:"prev_cursor"`
+				Total      *int64  `json:"total"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		p := page{NextCursor: payload.Data.NextCursor, PrevCursor: payload.Data.PrevCursor, HasTotal: payload.Data.Total != nil}
+		for _, item := range payload.Data.Items {
+			p.Titles = append(p.Titles, item.Title)
+		}
+		return p
+	}
+
+	// An empty cursor starts keyset pagination at the first page
+	first := get("&cursor=")
+	if fmt.Sprint(first.Titles) != "[topic 1 topic 2]" || first.NextCursor == nil || first.PrevCursor != nil || first.HasTotal {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+
+	// A topic written meanwhile must not shift the following pages
+	tests.SeedTopic(t, user, category, tests.TopicParams{Title: "newest", CreatedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)})
+
+	second := get("&cursor=" + *first.NextCursor)
+	if fmt.Sprint(second.Titles) != "[topic 3 topic 4]" || second.NextCursor == nil || second.PrevCursor == nil {
+		t.Fatalf("unexpected second page %+v", second)
+	}
+
+	last := get("&cursor=" + *second.NextCursor)
+	if fmt.Sprint(last.Titles) != "[topic 5]" || last.NextCursor != nil {
+		t.Fatalf("unexpected last page %+v", last)
+	}
+
+	back := get("&cursor=" + *second.PrevCursor)
+	if fmt.Sprint(back.Titles) != "[topic 1 topic 2]" || back.PrevCursor == nil {
+		t.Fatalf("unexpected previous page %+v", back)
+	}
+
+	top := get("&cursor=" + *back.PrevCursor)
+	if fmt.Sprint(top.Titles) != "[newest]" || top.PrevCursor != nil {
+		t.Fatalf("unexpected top page %+v", top)
+	}
+
+	if counted := get("&cursor=&with_total=1"); !counted.HasTotal {
+		t.Fatal("expected total when with_total is set")
+	}
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?cursor=not-a-cursor", nil, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?cursor=&offset=2", nil, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for offset with cursor, got %d", rec.Code)
+	}
+}
+
+func TestTopicsOffsetPagination(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "pager"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "pagecat"})
+	for i := 1; i <= 5; i++ {
+		tests.SeedTopic(t, user, category, tests.TopicParams{
+			Title:     fmt.Sprintf("topic %d", i),
+			CreatedAt: time.Date(2026, 1, 6-i, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	// Without a cursor every topic listing pages by offset
+	for _, path := range []string{
+		"/api/v1/topics",
+		"/api/v1/categories/" + category.GetStringID() + "/topics",
+		"/api/v1/users/" + user.GetStringID() + "/topics",
+	} {
+		rec := tests.DoJSON(t, router, http.MethodGet, path+"?sort=created_at&order=desc&limit=2&offset=2", nil, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+		var payload struct {
+			Data struct {
+				Items []struct {
+					Title string `json:"title"`
+				} `json:"items"`
+				Offset     *int    `json:"offset"`
+				Total      int64   `json:"total"`
+				NextCursor *string `json:"next_cursor"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+
+		var titles []string
+		for _, item := range payload.Data.Items {
+			titles = append(titles, item.Title)
+		}
+		if fmt.Sprint(titles) != "[topic 3 topic 4]" {
+			t.Fatalf("%s: expected the page at offset 2, got %v", path, titles)
+		}
+		if payload.Data.Offset == nil || *payload.Data.Offset != 2 || payload.Data.Total != 5 || payload.Data.NextCursor != nil {
+			t.Fatalf("%s: expected offset paging, got %s", path, rec.Body.String())
+		}
+	}
+}
+
+func TestTopicsKeysetPaginationNullableSort(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "pager"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "pagecat"})
+	quiet := tests.SeedTopic(t, user, category, tests.TopicParams{Title: "quiet"})
+	older := tests.SeedTopic(t, user, category, tests.TopicParams{Title: "older"})
+	busy := tests.SeedTopic(t, user, category, tests.TopicParams{Title: "busy"})
+	tests.SeedReply(t, user, older, tests.ReplyParams{})
+	time.Sleep(10 * time.Millisecond)
+	tests.SeedReply(t, user, busy, tests.ReplyParams{})
+
+	// Walk one topic at a time to the end, then all the way back
+	var titles []string
+	cursor, prevCursor := "", ""
+	for range 3 {
+		rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?sort=last_reply_at&order=desc&limit=1&cursor="+cursor, nil, nil)
+		var payload struct {
+			Data struct {
+				Items []struct {
+					Title string `json:"title"`
+				} `json:"items"`
+				NextCursor *string `json:"next_cursor"`
+				PrevCursor *string `json:"prev_cursor"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		if len(payload.Data.Items) != 1 {
+			t.Fatalf("expected 1 topic, got %d", len(payload.Data.Items))
+		}
+		titles = append(titles, payload.Data.Items[0].Title)
+		if payload.Data.PrevCursor != nil {
+			prevCursor = *payload.Data.PrevCursor
+		}
+		if payload.Data.NextCursor != nil {
+			cursor = *payload.Data.NextCursor
+		}
+	}
+	if fmt.Sprint(titles) != fmt.Sprint([]string{busy.Title, older.Title, quiet.Title}) {
+		t.Fatalf("unexpected order %v", titles)
+	}
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?sort=last_reply_at&order=desc&limit=1&cursor="+prevCursor, nil, nil)
+	var payload struct {
+		Data struct {
+			Items []struct {
+				Title string `json:"title"`
+			} `json:"items"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &payload)
+	if len(payload.Data.Items) != 1 || payload.Data.Items[0].Title != older.Title {
+		t.Fatalf("expected to step back to %q, got %+v", older.Title, payload.Data.Items)
+	}
+}
+
+func TestTopicsSort(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "sorter"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "sortcat"})
+	sameDay := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests.SeedTopic(t, user, category, tests.TopicParams{Title: "a", CreatedAt: sameDay})
+	tests.SeedTopic(t, user, category, tests.TopicParams{Title: "b", CreatedAt: sameDay.AddDate(0, 0, 1)})
+	tests.SeedTopic(t, user, category, tests.TopicParams{Title: "c", CreatedAt: sameDay})
+
+	titles := func(path string) string {
+		t.Helper()
+		rec := tests.DoJSON(t, router, http.MethodGet, path, nil, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+		var payload struct {
+			Data struct {
+				Items []struct {
+					Title string `json:"title"`
+				} `json:"items"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		var titles []string
+		for _, item := range payload.Data.Items {
+			titles = append(titles, item.Title)
+		}
+		return strings.Join(titles, "")
+	}
+
+	if got := titles("/api/v1/topics?sort=-created_at,id"); got != "bac" {
+		t.Fatalf("expected bac, got %s", got)
+	}
+	if got := titles("/api/v1/topics?sort=created_at,-id"); got != "cab" {
+		t.Fatalf("expected cab, got %s", got)
+	}
+	if got := titles("/api/v1/topics/search?q=topic&sort=-id"); got != "" {
+		t.Fatalf("expected no matches, got %s", got)
+	}
+
+	for _, sort := range []string{"body", "id%20desc", "-created_at,-created_at"} {
+		rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?sort="+sort, nil, nil)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("sort=%s: expected 422, got %d", sort, rec.Code)
+		}
+	}
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/users?sort=password", nil, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+}
+
+func TestTopicsTrashAndRestore(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	owner := tests.SeedUser(t, tests.UserParams{Name: "owner"})
+	other := tests.SeedUser(t, tests.UserParams{Name: "other"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "trashcat"})
+	topicModel := tests.SeedTopic(t, owner, category, tests.TopicParams{Title: "oops"})
+	tests.SeedReply(t, other, topicModel, tests.ReplyParams{})
+	ownerAuth := map[string]string{"Authorization": "Bearer " + tests.IssueToken(owner)}
+	otherAuth := map[string]string{"Authorization": "Bearer " + tests.IssueToken(other)}
+
+	rec := tests.DoJSON(t, router, http.MethodDelete, "/api/v1/topics/"+topicModel.GetStringID(), nil, ownerAuth)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics/"+topicModel.GetStringID(), nil, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected trashed topic to be hidden, got %d", rec.Code)
+	}
+
+	trashedTotal := func(headers map[string]string) int64 {
+		t.Helper()
+		rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics/trashed", nil, headers)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		var payload struct {
+			Data struct {
+				Total int64 `json:"total"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		return payload.Data.Total
+	}
+	if got := trashedTotal(ownerAuth); got != 1 {
+		t.Fatalf("expected 1 trashed topic for the owner, got %d", got)
+	}
+	if got := trashedTotal(otherAuth); got != 0 {
+		t.Fatalf("expected no trashed topics for another user, got %d", got)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+topicModel.GetStringID()+"/restore", nil, otherAuth)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+topicModel.GetStringID()+"/restore", nil, ownerAuth)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics/"+topicModel.GetStringID()+"/replies", nil, nil)
+	var replies struct {
+		Data struct {
+			Total int64 `json:"total"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &replies)
+	if replies.Data.Total != 1 {
+		t.Fatalf("expected the reply to survive the trash, got %d", replies.Data.Total)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/"+topicModel.GetStringID()+"/restore", nil, ownerAuth)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a topic not in the trash, got %d", rec.Code)
+	}
+}
+
+func TestTopicsPruneTrashed(t *testing.T) {
+	tests.ResetState(t)
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "owner"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "prunecat"})
+	old := tests.SeedTopic(t, user, category, tests.TopicParams{Title: "old"})
+	recent := tests.SeedTopic(t, user, category, tests.TopicParams{Title: "recent"})
+	tests.SeedReply(t, user, old, tests.ReplyParams{})
+	old.Delete(t.Context())
+	recent.Delete(t.Context())
+	database.DB.Unscoped().Model(&old).Update("deleted_at", time.Now().AddDate(0, 0, -40))
+
+	pruned, err := models.PruneTrashed[topic.Topic](t.Context(), time.Now().AddDate(0, 0, -30))
+	if err != nil || pruned != 1 {
+		t.Fatalf("expected 1 pruned topic, got %d (%v)", pruned, err)
+	}
+
+	var remaining, replies int64
+	database.DB.Unscoped().Model(&topic.Topic{}).Count(&remaining)
+	database.DB.Model(&reply.Reply{}).Where("topic_id = ?", old.ID).Count(&replies)
+	if remaining != 1 || replies != 0 {
+		t.Fatalf("expected the recent topic to stay and the old replies to go, got %d topics and %d replies", remaining, replies)
+	}
+}
@@ -0,0 +1,247 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gohub/pkg/database"
+	"gohub/pkg/totp"
+	"gohub/tests"
+)
+
+func TestTwoFactorLogin(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "twofactoruser", Password: "password123"})
+	headers := map[string]string{"Authorization": "Bearer " + tests.IssueToken(user)}
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/2fa/setup", nil, headers)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var setup struct {
+		Data struct {
+			Secret     string `json:"secret"`
+			OtpauthURI string `json:"otpauth_uri"`
+			QRCode     string `json:"qr_code"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &setup)
+	secret := setup.Data.Secret
+	if !strings.HasPrefix(setup.Data.OtpauthURI, "otpauth://totp/") || !strings.Contains(setup.Data.OtpauthURI, secret) {
+		t.Fatalf("unexpected otpauth uri %q", setup.Data.OtpauthURI)
+	}
+	if !strings.HasPrefix(setup.Data.QRCode, "data:image/png;base64,") {
+		t.Fatalf("unexpected qr code %.40q", setup.Data.QRCode)
+	}
+
+	// Steps relative to the one of the confirmation, the test may cross a 30 second boundary
+	confirmStep := totp.Step(time.Now())
+	code := func(offset int64) string {
+		value, err := totp.Code(secret, confirmStep+offset)
+		if err != nil {
+			t.Fatalf("code: %v", err)
+		}
+		return value
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/2fa/confirm", map[string]any{"code": code(0)}, headers)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var confirm struct {
+		Data struct {
+			RecoveryCodes []string `json:"recovery_codes"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &confirm)
+	if len(confirm.Data.RecoveryCodes) != 8 {
+		t.Fatalf("expected 8 recovery codes, got %v", confirm.Data.RecoveryCodes)
+	}
+
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, headers)
+	var current map[string]any
+	tests.DecodeJSON(t, rec, &current)
+	if current["data"].(map[string]any)["two_factor_enabled"] != true {
+		t.Fatalf("expected two_factor_enabled, got %v", current["data"])
+	}
+
+	challenge := func() string {
+		rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+			"login_id":       user.Name,
+			"password":       "password123",
+			"captcha_id":     "captcha_skip_test",
+			"captcha_answer": "123456",
+		}, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+		}
+		var payload struct {
+			Data struct {
+				TwoFactor      bool   `json:"two_factor"`
+				ChallengeToken string `json:"challenge_token"`
+				Token          string `json:"token"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		if !payload.Data.TwoFactor || payload.Data.ChallengeToken == "" || payload.Data.Token != "" {
+			t.Fatalf("expected a challenge instead of tokens, got %s", rec.Body.String())
+		}
+		return payload.Data.ChallengeToken
+	}
+	loginWith := func(body map[string]any) *httptest.ResponseRecorder {
+		return tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/2fa", body, nil)
+	}
+
+	token := challenge()
+
+	// The challenge token is no access token
+	rec = tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, map[string]string{"Authorization": "Bearer " + token})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+
+	// The code of the confirmation was used already, the next one is accepted once
+	if rec = loginWith(map[string]any{"challenge_token": token, "code": code(0)}); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a used code, got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = loginWith(map[string]any{"challenge_token": token, "code": code(1)})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var tokens struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &tokens)
+	if tokens.Data.Token == "" {
+		t.Fatalf("expected a token, got %s", rec.Body.String())
+	}
+	if rec = loginWith(map[string]any{"challenge_token": challenge(), "code": code(1)}); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a replayed code, got %d", rec.Code)
+	}
+
+	// Recovery codes work once
+	recoveryCode := confirm.Data.RecoveryCodes[0]
+	if rec = loginWith(map[string]any{"challenge_token": challenge(), "recovery_code": recoveryCode}); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec = loginWith(map[string]any{"challenge_token": challenge(), "recovery_code": recoveryCode}); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a used recovery code, got %d", rec.Code)
+	}
+
+	// Either code is required
+	if rec = loginWith(map[string]any{"challenge_token": token}); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 without a code, got %d", rec.Code)
+	}
+
+	// Disabled with a recovery code, the password is enough again
+	rec = tests.DoJSON(t, router, http.MethodDelete, "/api/v1/user/2fa",
+		map[string]any{"recovery_code": confirm.Data.RecoveryCodes[1]}, headers)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-password", map[string]any{
+		"login_id":       user.Name,
+		"password":       "password123",
+		"captcha_id":     "captcha_skip_test",
+		"captcha_answer": "123456",
+	}, nil)
+	tests.DecodeJSON(t, rec, &tokens)
+	if rec.Code != http.StatusOK || tokens.Data.Token == "" {
+		t.Fatalf("expected tokens, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTwoFactorLockout(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "twofactorlocked"})
+	headers := map[string]string{"Authorization": "Bearer " + tests.IssueToken(user)}
+
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/2fa/setup", nil, headers)
+	var setup struct {
+		Data struct {
+			Secret string `json:"secret"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &setup)
+	valid, _ := totp.Code(setup.Data.Secret, totp.Step(time.Now()))
+	wrong := "000000"
+	if valid == wrong {
+		wrong = "111111"
+	}
+
+	for i := 0; i < 4; i++ {
+		rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/2fa/confirm", map[string]any{"code": wrong}, headers)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected 422, got %d", rec.Code)
+		}
+	}
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/2fa/confirm", map[string]any{"code": wrong}, headers)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+
+	// Locked even with the right code
+	rec = tests.DoJSON(t, router, http.MethodPost, "/api/v1/user/2fa/confirm", map[string]any{"code": valid}, headers)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+}
+
+func TestTwoFactorEveryLoginPath(t *testing.T) {
+	tests.ResetState(t)
+	fakeGitHub(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "everypath", Phone: "00012345600", Email: "octocat60@example.com"})
+	secret := totp.GenerateSecret()
+	now := time.Now()
+	database.DB.Model(&user).Updates(map[string]any{"two_factor_secret": secret, "two_factor_confirmed_at": now})
+
+	expectChallenge := func(rec *httptest.ResponseRecorder) string {
+		t.Helper()
+		var payload struct {
+			Data struct {
+				TwoFactor      bool   `json:"two_factor"`
+				ChallengeToken string `json:"challenge_token"`
+				Token          string `json:"token"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		if rec.Code != http.StatusOK || !payload.Data.TwoFactor || payload.Data.ChallengeToken == "" || payload.Data.Token != "" {
+			t.Fatalf("expected a challenge instead of tokens, got %d %s", rec.Code, rec.Body.String())
+		}
+		return payload.Data.ChallengeToken
+	}
+	oauthSignIn := func(code string) *httptest.ResponseRecorder {
+		rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/redirect", nil, nil)
+		state := oauthState(t, rec.Header().Get("Location"))
+		return tests.DoJSON(t, router, http.MethodGet, "/api/v1/auth/oauth/github/callback?code="+code+"&state="+state, nil, oauthCookie(t, rec))
+	}
+
+	// Phone and verification code
+	challenge := expectChallenge(tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/using-phone", map[string]any{
+		"phone":       user.Phone,
+		"verify_code": "123456",
+	}, nil))
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("code: %v", err)
+	}
+	rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/auth/login/2fa", map[string]any{"challenge_token": challenge, "code": code}, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"refresh_token"`) {
+		t.Fatalf("expected tokens, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// OAuth with the verified email of the user, which links the account, then with the linked account
+	expectChallenge(oauthSignIn("user-60"))
+	expectChallenge(oauthSignIn("user-60"))
+}
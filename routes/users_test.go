@@ -142,3 +142,24 @@ func TestUsersUpdateAvatar(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rec.Code)
 	}
 }
+
+func TestRateLimitHeaders(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	user := tests.SeedUser(t, tests.UserParams{Name: "limiteduser"})
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/user", nil, map[string]string{
+		"Authorization": "Bearer " + tests.IssueToken(user),
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	for _, header := range []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy"} {
+		if rec.Header().Get(header) == "" {
+			t.Fatalf("expected %s header", header)
+		}
+	}
+	if rec.Header().Get("X-RateLimit-Limit") != "" {
+		t.Fatalf("expected no X-RateLimit headers")
+	}
+}
@@ -0,0 +1,175 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gohub/app/models/topic"
+	"gohub/app/models/vote"
+	"gohub/pkg/cache"
+	"gohub/pkg/database"
+	"gohub/tests"
+)
+
+func TestTopicVotes(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	author := tests.SeedUser(t, tests.UserParams{Name: "author"})
+	voter := tests.SeedUser(t, tests.UserParams{Name: "voter"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "votecat"})
+	topicModel := tests.SeedTopic(t, author, category, tests.TopicParams{Title: "vote me"})
+	path := "/api/v1/topics/" + topicModel.GetStringID() + "/vote"
+	authorAuth := map[string]string{"Authorization": "Bearer " + tests.IssueToken(author)}
+	voterAuth := map[string]string{"Authorization": "Bearer " + tests.IssueToken(voter)}
+
+	voteCount := func(method string, headers map[string]string) int64 {
+		t.Helper()
+		rec := tests.DoJSON(t, router, method, path, nil, headers)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var payload struct {
+			Data struct {
+				VoteCount int64 `json:"vote_count"`
+			} `json:"data"`
+		}
+		tests.DecodeJSON(t, rec, &payload)
+		return payload.Data.VoteCount
+	}
+
+	if rec := tests.DoJSON(t, router, http.MethodPost, path, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+	if rec := tests.DoJSON(t, router, http.MethodPost, "/api/v1/topics/999/vote", nil, voterAuth); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+
+	if got := voteCount(http.MethodPost, voterAuth); got != 1 {
+		t.Fatalf("expected 1 vote, got %d", got)
+	}
+	if got := voteCount(http.MethodPost, voterAuth); got != 1 {
+		t.Fatalf("expected voting twice to keep 1 vote, got %d", got)
+	}
+	if got := voteCount(http.MethodPost, authorAuth); got != 2 {
+		t.Fatalf("expected 2 votes, got %d", got)
+	}
+	if got := voteCount(http.MethodDelete, voterAuth); got != 1 {
+		t.Fatalf("expected 1 vote after unvoting, got %d", got)
+	}
+	if got := voteCount(http.MethodDelete, voterAuth); got != 1 {
+		t.Fatalf("expected unvoting twice to keep 1 vote, got %d", got)
+	}
+
+	var stored int64
+	database.DB.Model(&vote.TopicVote{}).Where("topic_id = ?", topicModel.ID).Count(&stored)
+	if stored != 1 {
+		t.Fatalf("expected 1 stored vote, got %d", stored)
+	}
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics/"+topicModel.GetStringID(), nil, nil)
+	var shown struct {
+		Data struct {
+			VoteCount int64 `json:"vote_count"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &shown)
+	if shown.Data.VoteCount != 1 {
+		t.Fatalf("expected the topic to show the live vote count, got %d", shown.Data.VoteCount)
+	}
+}
+
+func TestTopicVotesCountCommitted(t *testing.T) {
+	tests.ResetState(t)
+
+	author := tests.SeedUser(t, tests.UserParams{Name: "author"})
+	voter := tests.SeedUser(t, tests.UserParams{Name: "voter"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "countcat"})
+	topicModel := tests.SeedTopic(t, author, category, tests.TopicParams{Title: "count me"})
+	topicID := topicModel.GetStringID()
+
+	// The vote of a topic that does not exist is rolled back by its hook, it is not counted
+	if got := vote.Count(t.Context(), "999"); got != 0 {
+		t.Fatalf("expected no votes, got %d", got)
+	}
+	rolledBack := vote.TopicVote{UserID: voter.GetStringID(), TopicID: "999"}
+	rolledBack.Create(t.Context())
+	if vote.Get(t.Context(), voter.GetStringID(), "999").ID != 0 {
+		t.Fatal("expected the vote to be rolled back")
+	}
+	if got := vote.Count(t.Context(), "999"); got != 0 {
+		t.Fatalf("expected the rolled back vote not to be counted, got %d", got)
+	}
+
+	if got := vote.Count(t.Context(), topicID); got != 0 {
+		t.Fatalf("expected no votes, got %d", got)
+	}
+	voteModel := vote.TopicVote{UserID: voter.GetStringID(), TopicID: topicID}
+	voteModel.Create(t.Context())
+	if got := vote.Count(t.Context(), topicID); got != 1 {
+		t.Fatalf("expected 1 vote, got %d", got)
+	}
+
+	// Deleting the vote twice, as concurrent unvotes do, takes it out once
+	if rows := voteModel.Delete(t.Context()); rows != 1 {
+		t.Fatalf("expected the vote to be deleted, got %d rows", rows)
+	}
+	if rows := voteModel.Delete(t.Context()); rows != 0 {
+		t.Fatalf("expected nothing to delete, got %d rows", rows)
+	}
+	if got := vote.Count(t.Context(), topicID); got != 0 {
+		t.Fatalf("expected no votes after unvoting, got %d", got)
+	}
+}
+
+func TestTopicVotesReconcileAndHot(t *testing.T) {
+	tests.ResetState(t)
+	router := tests.NewRouter()
+
+	author := tests.SeedUser(t, tests.UserParams{Name: "author"})
+	category := tests.SeedCategory(t, tests.CategoryParams{Name: "hotcat"})
+	old := tests.SeedTopic(t, author, category, tests.TopicParams{Title: "old", CreatedAt: time.Now().Add(-48 * time.Hour)})
+	fresh := tests.SeedTopic(t, author, category, tests.TopicParams{Title: "fresh"})
+	quiet := tests.SeedTopic(t, author, category, tests.TopicParams{Title: "quiet"})
+
+	// The old topic has more votes, but the fresh one is hotter
+	for i, topicModel := range []topic.Topic{old, old, old, fresh, fresh} {
+		voter := tests.SeedUser(t, tests.UserParams{Name: fmt.Sprintf("voter%d", i)})
+		voteModel := vote.TopicVote{UserID: voter.GetStringID(), TopicID: topicModel.GetStringID()}
+		voteModel.Create(t.Context())
+	}
+
+	// A counter that drifted is reset to the stored votes
+	cache.Forever("topic:vote_count:"+old.GetStringID(), "7")
+
+	updated, err := vote.Reconcile(t.Context())
+	if err != nil || updated != 2 {
+		t.Fatalf("expected 2 topics updated, got %d (%v)", updated, err)
+	}
+	if got := vote.Count(t.Context(), old.GetStringID()); got != 3 {
+		t.Fatalf("expected the cached counter to be reset to 3, got %d", got)
+	}
+
+	rec := tests.DoJSON(t, router, http.MethodGet, "/api/v1/topics?sort=hot", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload struct {
+		Data struct {
+			Items []struct {
+				ID        uint64 `json:"id"`
+				VoteCount int64  `json:"vote_count"`
+			} `json:"items"`
+		} `json:"data"`
+	}
+	tests.DecodeJSON(t, rec, &payload)
+	items := payload.Data.Items
+	if len(items) != 3 || items[0].ID != fresh.ID || items[1].ID != old.ID || items[2].ID != quiet.ID {
+		t.Fatalf("expected fresh, old, quiet, got %+v", items)
+	}
+	if items[0].VoteCount != 2 || items[1].VoteCount != 3 {
+		t.Fatalf("expected the reconciled vote counts, got %+v", items)
+	}
+}
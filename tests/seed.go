@@ -1,16 +1,20 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"gohub/app/models/category"
 	"gohub/app/models/link"
+	"gohub/app/models/permission"
+	"gohub/app/models/reply"
+	"gohub/app/models/role"
 	"gohub/app/models/topic"
 	"gohub/app/models/user"
+	"gohub/pkg/auth"
 	"gohub/pkg/database"
-	"gohub/pkg/jwt"
 )
 
 type UserParams struct {
@@ -18,6 +22,8 @@ type UserParams struct {
 	Email    string
 	Phone    string
 	Password string
+	// UnverifiedEmail Seed the email without email_verified_at
+	UnverifiedEmail bool
 }
 
 func SeedUser(t *testing.T, params UserParams) user.User {
@@ -42,12 +48,20 @@ func SeedUser(t *testing.T, params UserParams) user.User {
 		Phone:    params.Phone,
 		Password: params.Password,
 	}
+	if !params.UnverifiedEmail {
+		now := time.Now()
+		model.EmailVerifiedAt = &now
+	}
 	database.DB.Create(&model)
 	return model
 }
 
 func IssueToken(userModel user.User) string {
-	return jwt.NewJWT().IssueToken(userModel.GetStringID(), userModel.Name)
+	pair, err := auth.NewSession(context.Background(), userModel, "127.0.0.1", "Go-http-client/1.1", "")
+	if err != nil {
+		panic(err)
+	}
+	return pair.AccessToken
 }
 
 type CategoryParams struct {
@@ -75,6 +89,8 @@ func SeedCategory(t *testing.T, params CategoryParams) category.Category {
 type TopicParams struct {
 	Title string
 	Body  string
+	// Left to GORM when zero
+	CreatedAt time.Time
 }
 
 func SeedTopic(t *testing.T, userModel user.User, categoryModel category.Category, params TopicParams) topic.Topic {
@@ -92,6 +108,28 @@ func SeedTopic(t *testing.T, userModel user.User, categoryModel category.Categor
 		UserID:     userModel.GetStringID(),
 		CategoryID: categoryModel.GetStringID(),
 	}
+	model.CreatedAt = params.CreatedAt
+	database.DB.Create(&model)
+	return model
+}
+
+type ReplyParams struct {
+	Body     string
+	ParentID uint64
+}
+
+func SeedReply(t *testing.T, userModel user.User, topicModel topic.Topic, params ReplyParams) reply.Reply {
+	t.Helper()
+	if params.Body == "" {
+		params.Body = "this is a reply"
+	}
+
+	model := reply.Reply{
+		Body:     params.Body,
+		TopicID:  topicModel.GetStringID(),
+		UserID:   userModel.GetStringID(),
+		ParentID: params.ParentID,
+	}
 	database.DB.Create(&model)
 	return model
 }
@@ -117,3 +155,24 @@ func SeedLink(t *testing.T, params LinkParams) link.Link {
 	database.DB.Create(&model)
 	return model
 }
+
+// GrantRole Give the user a role holding the permissions, the role and permissions are created when missing
+func GrantRole(t *testing.T, userModel user.User, roleName string, permissionNames ...string) role.Role {
+	t.Helper()
+
+	roleModel := role.Role{Name: roleName}
+	database.DB.Where("name = ?", roleName).FirstOrCreate(&roleModel)
+
+	for _, name := range permissionNames {
+		permissionModel := permission.Permission{Name: name}
+		database.DB.Where("name = ?", name).FirstOrCreate(&permissionModel)
+		if err := database.DB.Model(&roleModel).Association("Permissions").Append(&permissionModel); err != nil {
+			t.Fatalf("grant permission failed: %v", err)
+		}
+	}
+
+	if err := roleModel.AssignTo(t.Context(), userModel.ID); err != nil {
+		t.Fatalf("assign role failed: %v", err)
+	}
+	return roleModel
+}
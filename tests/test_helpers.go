@@ -16,15 +16,24 @@ import (
 	"github.com/gin-gonic/gin"
 	"gohub/app/models/category"
 	"gohub/app/models/link"
+	"gohub/app/models/notification"
+	"gohub/app/models/permission"
+	"gohub/app/models/reply"
+	"gohub/app/models/role"
+	"gohub/app/models/session"
+	"gohub/app/models/socialaccount"
 	"gohub/app/models/topic"
 	"gohub/app/models/user"
+	"gohub/app/models/vote"
 	"gohub/bootstrap"
 	appconfig "gohub/config"
 	_ "gohub/database/migrations"
+	"gohub/pkg/cache"
 	"gohub/pkg/config"
 	"gohub/pkg/database"
 	"gohub/pkg/logger"
 	"gohub/pkg/migrate"
+	"gohub/pkg/queue"
 	"gohub/pkg/redis"
 )
 
@@ -80,8 +89,10 @@ func SetupTestEnv(t *testing.T) {
 		)
 		bootstrap.SetupDB()
 		bootstrap.SetupCache()
+		bootstrap.SetupQueue()
+		bootstrap.SetupLimiter()
 
-		migrate.NewMigrator().Up()
+		setupErr = migrate.NewMigrator().Up()
 	})
 
 	if setupErr != nil {
@@ -97,8 +108,19 @@ func ResetState(t *testing.T) {
 		if err := database.DB.Migrator().DropTable(
 			&user.User{},
 			&category.Category{},
+			&reply.Reply{},
+			&vote.TopicVote{},
+			&notification.Notification{},
+			&socialaccount.SocialAccount{},
+			&session.Session{},
 			&topic.Topic{},
+			"topics_fts",
 			&link.Link{},
+			&role.UserRole{},
+			"role_permissions",
+			&role.Role{},
+			&permission.Permission{},
+			&queue.FailedJob{},
 			&migrate.Migration{},
 		); err != nil {
 			t.Fatalf("reset db failed: %v", err)
@@ -108,11 +130,16 @@ func ResetState(t *testing.T) {
 			t.Fatalf("reset db failed: %v", err)
 		}
 	}
-	migrate.NewMigrator().Up()
+	if err := migrate.NewMigrator().Up(); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
 
 	if redis.Redis != nil {
 		redis.Redis.FlushDB()
 	}
+	cache.Flush()
+	// Fresh counters, each test starts with the full rate limits
+	bootstrap.SetupLimiter()
 
 	_ = os.RemoveAll("public/uploads")
 }